@@ -0,0 +1,364 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coderero/erochat-server/api/service"
+	"github.com/coderero/erochat-server/api/utils"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// APITokenHandler represents an HTTP handler for personal api tokens and bot accounts.
+type APITokenHandler struct {
+	// validate is the validator.
+	validate *validator.Validate
+
+	// userStore is a data store for user.
+	userStore interfaces.UserStore
+
+	// apiTokenStore is a data store for personal api tokens.
+	apiTokenStore interfaces.APITokenStore
+}
+
+// CreateAPIToken represents a request to create a personal api token.
+type CreateAPIToken struct {
+	// Name is a label to recognise the token by.
+	Name string `json:"name" validate:"required,max=64"`
+
+	// Scopes is the list of scopes granted to the token.
+	Scopes []string `json:"scopes" validate:"required,min=1"`
+
+	// ExpiresInDays is the lifetime of the token, zero means it never expires.
+	ExpiresInDays int `json:"expires_in_days" validate:"min=0,max=365"`
+}
+
+// CreateBot represents a request to create a bot account.
+type CreateBot struct {
+	// Username is the username of the bot.
+	Username string `json:"username" validate:"required,alphanum,max=32"`
+}
+
+// NewAPITokenHandler creates a new APITokenHandler.
+func NewAPITokenHandler(validator *validator.Validate, userStore interfaces.UserStore, apiTokenStore interfaces.APITokenStore) *APITokenHandler {
+	return &APITokenHandler{
+		validate:      validator,
+		userStore:     userStore,
+		apiTokenStore: apiTokenStore,
+	}
+}
+
+var (
+	tnf = &echo.HTTPError{
+		Code:    echo.ErrNotFound.Code,
+		Message: "api token not found",
+	}
+	bnf = &echo.HTTPError{
+		Code:    echo.ErrNotFound.Code,
+		Message: "bot not found",
+	}
+)
+
+// CreateToken creates a personal api token for the authenticated user.
+func (h *APITokenHandler) CreateToken(c echo.Context) error {
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return h.createToken(c, userID)
+}
+
+// GetTokens lists the personal api tokens of the authenticated user.
+func (h *APITokenHandler) GetTokens(c echo.Context) error {
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return h.getTokens(c, userID)
+}
+
+// RevokeToken revokes a personal api token of the authenticated user.
+func (h *APITokenHandler) RevokeToken(c echo.Context) error {
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return h.revokeToken(c, userID, c.Param("uid"))
+}
+
+// CreateBot creates a bot account owned by the authenticated user.
+func (h *APITokenHandler) CreateBot(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	params := new(CreateBot)
+	if err := utils.JSONDecode(c, params); err != nil {
+		if strings.Contains(err.Error(), "json:") {
+			return c.JSON(http.StatusBadRequest, utils.JsonBindingErrorBuilder(err))
+		}
+		return err
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return c.JSON(http.StatusBadRequest, types.ApiResponse{
+			Status:  types.Failure.String(),
+			Code:    http.StatusBadRequest,
+			Type:    types.ErrorTypeValidation.String(),
+			Message: "validation error",
+			Errors:  utils.ConvertValidationErrors(err),
+		})
+	}
+
+	// Bots never log in with a password, "!" can't match any scrypt hash.
+	bot := &types.User{
+		Username:    params.Username,
+		Email:       fmt.Sprintf("%s@bot.erochat.invalid", strings.ToLower(params.Username)),
+		Password:    "!",
+		AccountType: types.AccountTypeBot,
+		OwnerUID:    uuid.NullUUID{UUID: userID, Valid: true},
+	}
+
+	bot, err = h.userStore.Create(ctx, bot)
+	if err != nil {
+		if isContextError(err) {
+			return err
+		}
+		res := userStoreErrResBuilder(c, err)
+		return c.JSON(res.Code, res)
+	}
+
+	return c.JSON(http.StatusCreated, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusCreated,
+		Message: "bot created successfully",
+		Data:    botResponse(bot),
+	})
+}
+
+// GetBots lists the bot accounts owned by the authenticated user.
+func (h *APITokenHandler) GetBots(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	bots, err := h.userStore.GetBotsByOwner(ctx, userID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	data := make([]echo.Map, 0, len(bots))
+	for _, bot := range bots {
+		data = append(data, botResponse(bot))
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "bots fetched successfully",
+		Data:    data,
+	})
+}
+
+// CreateBotToken creates a personal api token for a bot owned by the authenticated user.
+func (h *APITokenHandler) CreateBotToken(c echo.Context) error {
+	bot, err := h.getOwnedBot(c)
+	if err != nil {
+		return err
+	}
+
+	return h.createToken(c, bot.UID)
+}
+
+// GetBotTokens lists the personal api tokens of a bot owned by the authenticated user.
+func (h *APITokenHandler) GetBotTokens(c echo.Context) error {
+	bot, err := h.getOwnedBot(c)
+	if err != nil {
+		return err
+	}
+
+	return h.getTokens(c, bot.UID)
+}
+
+// RevokeBotToken revokes a personal api token of a bot owned by the authenticated user.
+func (h *APITokenHandler) RevokeBotToken(c echo.Context) error {
+	bot, err := h.getOwnedBot(c)
+	if err != nil {
+		return err
+	}
+
+	return h.revokeToken(c, bot.UID, c.Param("tid"))
+}
+
+// createToken creates a personal api token for the account.
+func (h *APITokenHandler) createToken(c echo.Context, accountID uuid.UUID) error {
+	ctx := c.Request().Context()
+
+	params := new(CreateAPIToken)
+	if err := utils.JSONDecode(c, params); err != nil {
+		if strings.Contains(err.Error(), "json:") {
+			return c.JSON(http.StatusBadRequest, utils.JsonBindingErrorBuilder(err))
+		}
+		return err
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return c.JSON(http.StatusBadRequest, types.ApiResponse{
+			Status:  types.Failure.String(),
+			Code:    http.StatusBadRequest,
+			Type:    types.ErrorTypeValidation.String(),
+			Message: "validation error",
+			Errors:  utils.ConvertValidationErrors(err),
+		})
+	}
+
+	for _, scope := range params.Scopes {
+		if !types.IsValidScope(scope) {
+			return c.JSON(http.StatusBadRequest, types.ApiResponse{
+				Status:  types.Failure.String(),
+				Code:    http.StatusBadRequest,
+				Type:    types.ErrorTypeValidation.String(),
+				Message: "validation error",
+				Errors: []types.Error{
+					{
+						Field:  "scopes",
+						Reason: fmt.Sprintf("unknown scope %q", scope),
+					},
+				},
+			})
+		}
+	}
+
+	token, prefix, hash, err := service.GenerateAPIToken()
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	apiToken := &types.APIToken{
+		UserUID: accountID,
+		Name:    params.Name,
+		Prefix:  prefix,
+		Hash:    hash,
+		Scopes:  params.Scopes,
+	}
+	if params.ExpiresInDays > 0 {
+		expiresAt := time.Now().Add(time.Duration(params.ExpiresInDays) * 24 * time.Hour)
+		apiToken.ExpiresAt = &expiresAt
+	}
+
+	apiToken, err = h.apiTokenStore.Create(ctx, apiToken)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	// The token is only ever shown once, only its hash is stored.
+	return c.JSON(http.StatusCreated, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusCreated,
+		Message: "api token created successfully",
+		Data: echo.Map{
+			"token":   token,
+			"details": apiToken,
+		},
+	})
+}
+
+// getTokens lists the personal api tokens of the account.
+func (h *APITokenHandler) getTokens(c echo.Context, accountID uuid.UUID) error {
+	ctx := c.Request().Context()
+
+	tokens, err := h.apiTokenStore.GetByUser(ctx, accountID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "api tokens fetched successfully",
+		Data:    tokens,
+	})
+}
+
+// revokeToken revokes a personal api token of the account.
+func (h *APITokenHandler) revokeToken(c echo.Context, accountID uuid.UUID, tid string) error {
+	ctx := c.Request().Context()
+
+	tokenID, err := uuid.Parse(tid)
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid token id",
+		}
+	}
+
+	err = h.apiTokenStore.Revoke(ctx, accountID, tokenID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrAPITokenNotFound) {
+			return tnf
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "api token revoked successfully",
+	})
+}
+
+// getOwnedBot returns the bot in the url parameter if it's owned by the authenticated user.
+func (h *APITokenHandler) getOwnedBot(c echo.Context) (*types.User, error) {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return nil, sww
+	}
+
+	botID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return nil, &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid bot id",
+		}
+	}
+
+	bot, err := h.userStore.GetByID(ctx, botID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrUserNotFound) {
+			return nil, bnf
+		}
+		return nil, sww
+	}
+
+	if !bot.IsBot() || !bot.OwnerUID.Valid || bot.OwnerUID.UUID != userID || bot.DeletedAt.Valid {
+		return nil, bnf
+	}
+	return bot, nil
+}
+
+// botResponse builds the public view of a bot account.
+func botResponse(bot *types.User) echo.Map {
+	return echo.Map{
+		"uid":        bot.UID,
+		"username":   bot.Username,
+		"created_at": bot.CreatedAt,
+	}
+}
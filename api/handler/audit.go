@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// defaultAuditLimit is the number of events returned when no limit is given.
+	defaultAuditLimit = 50
+
+	// maxAuditLimit is the maximum number of events returned at once.
+	maxAuditLimit = 200
+)
+
+// AuditHandler represents an HTTP handler for the security audit log.
+type AuditHandler struct {
+	// auditStore is the security audit log.
+	auditStore interfaces.AuditStore
+}
+
+// NewAuditHandler creates a new AuditHandler.
+func NewAuditHandler(auditStore interfaces.AuditStore) *AuditHandler {
+	return &AuditHandler{
+		auditStore: auditStore,
+	}
+}
+
+// GetSecurityEvents returns the recent security events of the authenticated user.
+func (h *AuditHandler) GetSecurityEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	limit, err := parseAuditLimit(c.QueryParam("limit"))
+	if err != nil {
+		return err
+	}
+
+	before := time.Now().UTC()
+	if b := c.QueryParam("before"); b != "" {
+		if before, err = time.Parse(time.RFC3339Nano, b); err != nil {
+			return &echo.HTTPError{
+				Code:    echo.ErrBadRequest.Code,
+				Message: "before must be an rfc3339 timestamp",
+			}
+		}
+	}
+
+	events, err := h.auditStore.GetByUser(ctx, userID, before, limit)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "security events fetched successfully",
+		Data:    events,
+	})
+}
+
+// QueryAuditLog returns the audit events matching the filters, for admins.
+func (h *AuditHandler) QueryAuditLog(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var (
+		filter types.AuditFilter
+		err    error
+	)
+
+	if filter.Limit, err = parseAuditLimit(c.QueryParam("limit")); err != nil {
+		return err
+	}
+
+	if u := c.QueryParam("user_uid"); u != "" {
+		if filter.UserUID, err = uuid.Parse(u); err != nil {
+			return &echo.HTTPError{
+				Code:    echo.ErrBadRequest.Code,
+				Message: "invalid user id",
+			}
+		}
+	}
+
+	if t := c.QueryParam("type"); t != "" {
+		for _, eventType := range strings.Split(t, ",") {
+			filter.Types = append(filter.Types, types.AuditEventType(strings.TrimSpace(eventType)))
+		}
+	}
+
+	filter.IP = c.QueryParam("ip")
+
+	for param, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := c.QueryParam(param); v != "" {
+			if *dst, err = time.Parse(time.RFC3339Nano, v); err != nil {
+				return &echo.HTTPError{
+					Code:    echo.ErrBadRequest.Code,
+					Message: param + " must be an rfc3339 timestamp",
+				}
+			}
+		}
+	}
+
+	events, err := h.auditStore.Query(ctx, filter)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "audit events fetched successfully",
+		Data:    events,
+	})
+}
+
+// parseAuditLimit parses the limit query parameter of the audit routes.
+func parseAuditLimit(v string) (int, error) {
+	if v == "" {
+		return defaultAuditLimit, nil
+	}
+
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit <= 0 {
+		return 0, &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "limit must be a positive number",
+		}
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+	return limit, nil
+}
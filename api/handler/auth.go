@@ -1,15 +1,26 @@
 package handler
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/coderero/erochat-server/api/middleware"
 	"github.com/coderero/erochat-server/api/utils"
 	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/metrics"
 	"github.com/coderero/erochat-server/types"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
 )
 
 // AuthHandler represents an HTTP handler for authentication.
@@ -25,6 +36,24 @@ type AuthHandler struct {
 
 	// TokenService represents a token service.
 	tokenService interfaces.TokenService
+
+	// APITokenStore represents the personal api token store.
+	apiTokenStore interfaces.APITokenStore
+
+	// AuditStore represents the security audit log.
+	auditStore interfaces.AuditStore
+
+	// unknownUserAudits limits the audit events of the logins of unknown
+	// users by client ip, anyone can send them.
+	unknownUserAudits *echoMiddleware.RateLimiterMemoryStore
+}
+
+// unknownUserAuditRate is the rate of the audit events of the logins of
+// unknown users recorded for a client ip, the others are only counted.
+var unknownUserAuditRate = echoMiddleware.RateLimiterMemoryStoreConfig{
+	Rate:      rate.Every(time.Minute / 10),
+	Burst:     10,
+	ExpiresIn: time.Minute * 10,
 }
 
 // AuthCreate represents a request to create a new user.
@@ -41,18 +70,28 @@ type Auth struct {
 	Password string `json:"password"`
 }
 
+// ChangePassword represents a request to change the password of a user.
+type ChangePassword struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
 // RefreshToken represents a request to refresh a token.
 type RefreshToken struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
 // NewAuthHandler creates a new AuthHandler.
-func NewAuthHandler(validator *validator.Validate, userStore interfaces.UserStore, passwordHasher interfaces.PassService, tokenService interfaces.TokenService) *AuthHandler {
+func NewAuthHandler(validator *validator.Validate, userStore interfaces.UserStore, passwordHasher interfaces.PassService, tokenService interfaces.TokenService, apiTokenStore interfaces.APITokenStore, auditStore interfaces.AuditStore) *AuthHandler {
 	return &AuthHandler{
 		validator:      validator,
 		userStore:      userStore,
 		passwordHasher: passwordHasher,
 		tokenService:   tokenService,
+		apiTokenStore:  apiTokenStore,
+		auditStore:     auditStore,
+
+		unknownUserAudits: echoMiddleware.NewRateLimiterMemoryStoreWithConfig(unknownUserAuditRate),
 	}
 }
 
@@ -63,16 +102,23 @@ var (
 		Type:    types.ErrorTypeInvalidCredentials.String(),
 		Message: "invalid credentials provided",
 	}
-	validationErr = types.ApiResponse{
+)
+
+// validationErrRes returns the response of a request failing the validation.
+func validationErrRes(errs []types.Error) types.ApiResponse {
+	return types.ApiResponse{
 		Status:  types.Failure.String(),
 		Code:    http.StatusBadRequest,
 		Type:    types.ErrorTypeValidation.String(),
 		Message: "validation error",
+		Errors:  errs,
 	}
-)
+}
 
 // Login logs in a user.
 func (h *AuthHandler) Login(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	var (
 		params Auth
 		user   *types.User
@@ -85,20 +131,28 @@ func (h *AuthHandler) Login(c echo.Context) error {
 	}
 
 	if err := checkForLoginParams(params); len(err) > 0 {
-		validationErr.Errors = err
-		return c.JSON(http.StatusBadRequest, validationErr)
+		return c.JSON(http.StatusBadRequest, validationErrRes(err))
 	}
 
-	// Check if the user exists.
+	// Check if the user exists. The audit log records the field used and a
+	// truncated hash of its value, it links the repeated attempts without
+	// keeping what was typed.
+	identifier := map[string]string{"reason": "user_not_found"}
 	if params.Username != "" {
-		user, err = h.userStore.GetByUsername(params.Username)
+		user, err = h.userStore.GetByUsername(ctx, params.Username)
+		identifier["username_hash"] = auditHash(params.Username)
 	} else {
-		user, err = h.userStore.GetByEmail(params.Email)
+		user, err = h.userStore.GetByEmail(ctx, params.Email)
+		identifier["email_hash"] = auditHash(params.Email)
 	}
 
 	// If the user is not found, return an error.
 	if err != nil {
 		if errors.Is(err, interfaces.ErrUserNotFound) {
+			metrics.ObserveLoginFailure("user_not_found")
+			if allowed, _ := h.unknownUserAudits.Allow(c.RealIP()); allowed {
+				utils.RecordAudit(c, h.auditStore, types.AuditLoginFailure, uuid.Nil, identifier)
+			}
 			return c.JSON(http.StatusNotFound, types.ApiResponse{
 				Status:  types.Failure.String(),
 				Code:    http.StatusNotFound,
@@ -106,10 +160,13 @@ func (h *AuthHandler) Login(c echo.Context) error {
 				Message: "user not found",
 			})
 		}
-		return c.JSON(http.StatusBadRequest, userStoreErrResBuilder(err))
+		return userStoreErrRes(c, err)
 	}
 
 	if user.DeletedAt.Valid {
+		metrics.ObserveLoginFailure("account_deleted")
+		utils.RecordAudit(c, h.auditStore, types.AuditLoginFailure, user.UID, map[string]string{"reason": "account_deleted"})
+
 		// Send a response that your account has been deleted and you can't login
 		// You can also send a link to recover the account
 		return c.JSON(http.StatusBadRequest, types.ApiResponse{
@@ -120,22 +177,36 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		})
 	}
 
+	// Bots can only authenticate with personal api tokens.
+	if user.IsBot() {
+		metrics.ObserveLoginFailure("bot_account")
+		utils.RecordAudit(c, h.auditStore, types.AuditLoginFailure, user.UID, map[string]string{"reason": "bot_account"})
+		return c.JSON(http.StatusBadRequest, invalidCred)
+	}
+
 	// Check if the password is valid.
 	if !h.passwordHasher.Compare(params.Password, user.Password) {
+		metrics.ObserveLoginFailure("invalid_password")
+		utils.RecordAudit(c, h.auditStore, types.AuditLoginFailure, user.UID, map[string]string{"reason": "invalid_password"})
 		return c.JSON(http.StatusBadRequest, invalidCred)
 	}
 
 	// Generate a token and a refresh token.
-	token, refreshToken, err := h.tokenService.GenerateTokens(user.Email, user.UID)
+	token, refreshToken, err := h.tokenService.GenerateTokens(ctx, user.Email, user.UID)
 
 	// If an error occurred, return it.
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, userStoreErrResBuilder(err))
+		return userStoreErrRes(c, err)
 	}
 
 	// Save the tokens in the cookies.
-	utils.SaveCookie(c, "__a", token)
-	utils.SaveCookie(c, "__r", refreshToken)
+	// Only crypto/rand can fail here.
+	if err := saveAuthCookies(c, token, refreshToken); err != nil {
+		return echo.ErrInternalServerError.WithInternal(err)
+	}
+
+	metrics.ObserveLogin()
+	utils.RecordAudit(c, h.auditStore, types.AuditLoginSuccess, user.UID, nil)
 
 	return c.JSON(http.StatusOK, types.ApiResponse{
 		Status:  types.Success.String(),
@@ -150,6 +221,8 @@ func (h *AuthHandler) Login(c echo.Context) error {
 
 // Register registers a new user.
 func (h *AuthHandler) Register(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	var (
 		params AuthCreate
 		user   *types.User
@@ -164,8 +237,7 @@ func (h *AuthHandler) Register(c echo.Context) error {
 
 	// Validate the request.
 	if err := h.validator.Struct(params); err != nil {
-		validationErr.Errors = utils.ConvertValidationErrors(err)
-		return c.JSON(http.StatusBadRequest, validationErr)
+		return c.JSON(http.StatusBadRequest, validationErrRes(utils.ConvertValidationErrors(err)))
 	}
 
 	// create a channel to check if the username or email exists both concurrently
@@ -173,7 +245,7 @@ func (h *AuthHandler) Register(c echo.Context) error {
 
 	// Check if the username exists.
 	go func() {
-		user, _ = h.userStore.GetByUsername(params.Username)
+		user, _ = h.userStore.GetByUsername(ctx, params.Username)
 		if user != nil {
 			if user.DeletedAt.Valid {
 				exits <- map[string]interface{}{"username": false, "deleted": true}
@@ -186,7 +258,7 @@ func (h *AuthHandler) Register(c echo.Context) error {
 
 	// Check if the email exists.
 	go func() {
-		user, _ = h.userStore.GetByEmail(params.Email)
+		user, _ = h.userStore.GetByEmail(ctx, params.Email)
 		if user != nil {
 			if user.DeletedAt.Valid {
 				exits <- map[string]interface{}{"email": false, "deleted": true}
@@ -215,14 +287,13 @@ func (h *AuthHandler) Register(c echo.Context) error {
 
 	// If there are errors, return them.
 	if len(errors) > 0 {
-		validationErr.Errors = errors
-		return c.JSON(http.StatusBadRequest, validationErr)
+		return c.JSON(http.StatusBadRequest, validationErrRes(errors))
 	}
 
 	// Hash the password.
 	hashedPass, err := h.passwordHasher.Hash(params.Password)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, userStoreErrResBuilder(err))
+		return userStoreErrRes(c, err)
 	}
 
 	// Create the user.
@@ -233,22 +304,26 @@ func (h *AuthHandler) Register(c echo.Context) error {
 	}
 
 	// Store the user.
-	user, err = h.userStore.Create(user)
+	user, err = h.userStore.Create(ctx, user)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, userStoreErrResBuilder(err))
+		return userStoreErrRes(c, err)
 	}
 
 	// Generate a token and a refresh token.
-	token, refreshToken, err := h.tokenService.GenerateTokens(user.Email, user.UID)
+	token, refreshToken, err := h.tokenService.GenerateTokens(ctx, user.Email, user.UID)
 
 	// If an error occurred, return it.
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, userStoreErrResBuilder(err))
+		return userStoreErrRes(c, err)
 	}
 
 	// Save the tokens in the cookies.
-	utils.SaveCookie(c, "__a", token)
-	utils.SaveCookie(c, "__r", refreshToken)
+	// Only crypto/rand can fail here.
+	if err := saveAuthCookies(c, token, refreshToken); err != nil {
+		return echo.ErrInternalServerError.WithInternal(err)
+	}
+
+	utils.RecordAudit(c, h.auditStore, types.AuditRegister, user.UID, nil)
 
 	return c.JSON(http.StatusOK, types.ApiResponse{
 		Status:  types.Success.String(),
@@ -265,6 +340,8 @@ func (h *AuthHandler) Register(c echo.Context) error {
 // ! Non Web Handler
 // RefreshToken refreshes a token.
 func (h *AuthHandler) RefreshToken(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	var (
 		refreshToken RefreshToken
 		err          error
@@ -290,16 +367,20 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 
 	// Validate the request.
 	if err := h.validator.Struct(refreshToken); err != nil {
-		validationErr.Errors = utils.ConvertValidationErrors(err)
-		return c.JSON(http.StatusBadRequest, validationErr)
+		return c.JSON(http.StatusBadRequest, validationErrRes(utils.ConvertValidationErrors(err)))
 	}
 
 	// TODO: Revoking the existing access token.
 
 	// Check if the refresh token is valid.
-	token, err := h.tokenService.RefreshToken(refreshToken.RefreshToken)
+	token, err := h.tokenService.RefreshToken(ctx, refreshToken.RefreshToken)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, userStoreErrResBuilder(err))
+		return userStoreErrRes(c, err)
+	}
+
+	if claims, err := h.tokenService.GetClaims(ctx, token); err == nil {
+		uid, _ := uuid.Parse(fmt.Sprint(claims["uid"]))
+		utils.RecordAudit(c, h.auditStore, types.AuditTokenRefresh, uid, nil)
 	}
 
 	// Return the new token.
@@ -313,9 +394,11 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 
 // Logout logs out a user.
 func (h *AuthHandler) Logout(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	// Get the access token and the refresh token from the cookies.
-	accessToken := utils.GetCookie(c, "__a")
-	refreshToken := utils.GetCookie(c, "__r")
+	accessToken := utils.GetCookie(c, utils.AccessTokenCookie)
+	refreshToken := utils.GetCookie(c, utils.RefreshTokenCookie)
 
 	if accessToken == "" || refreshToken == "" {
 		return c.JSON(http.StatusBadRequest, types.ApiResponse{
@@ -326,9 +409,22 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 		})
 	}
 
+	// Logging out changes state with cookie credentials, so it needs the CSRF token.
+	if !utils.CompareCSRFToken(utils.GetCookie(c, utils.CSRFCookie), c.Request().Header.Get(utils.CSRFHeader)) {
+		return middleware.ErrCSRFTokenInvalid
+	}
+
+	// The access token may have expired, the event is then recorded without a user.
+	var uid uuid.UUID
+	if claims, err := h.tokenService.GetClaims(ctx, accessToken); err == nil {
+		uid, _ = uuid.Parse(fmt.Sprint(claims["uid"]))
+	}
+	utils.RecordAudit(c, h.auditStore, types.AuditLogout, uid, nil)
+
 	// Delete the cookies.
-	utils.DeleteCookie(c, "__a")
-	utils.DeleteCookie(c, "__r")
+	utils.DeleteCookie(c, utils.AccessTokenCookie)
+	utils.DeleteCookie(c, utils.RefreshTokenCookie)
+	utils.DeleteCookie(c, utils.CSRFCookie)
 	return c.JSON(http.StatusOK, types.ApiResponse{
 		Status:  types.Success.String(),
 		Code:    http.StatusOK,
@@ -336,6 +432,83 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 	})
 }
 
+// ChangePassword changes the password of the authenticated user and revokes
+// their personal api tokens and those of their bots. The JWT sessions are
+// stateless and can't be revoked, they end with their token lifetime.
+func (h *AuthHandler) ChangePassword(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var params ChangePassword
+	if err := utils.JSONDecode(c, &params); err != nil {
+		if strings.Contains(err.Error(), "json:") {
+			return c.JSON(http.StatusBadRequest, utils.JsonBindingErrorBuilder(err))
+		}
+		return err
+	}
+
+	// Validate the request.
+	if err := h.validator.Struct(params); err != nil {
+		return c.JSON(http.StatusBadRequest, validationErrRes(utils.ConvertValidationErrors(err)))
+	}
+
+	email, ok := c.Get("user").(string)
+	if !ok {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "failed to get user email",
+		}
+	}
+
+	user, err := h.userStore.GetByEmail(ctx, email)
+	if err != nil {
+		return userStoreErrRes(c, err)
+	}
+
+	if !h.passwordHasher.Compare(params.CurrentPassword, user.Password) {
+		utils.RecordAudit(c, h.auditStore, types.AuditPasswordChange, user.UID, map[string]string{"result": "invalid_password"})
+		return c.JSON(http.StatusBadRequest, invalidCred)
+	}
+
+	hashedPass, err := h.passwordHasher.Hash(params.NewPassword)
+	if err != nil {
+		return userStoreErrRes(c, err)
+	}
+
+	if err := h.userStore.UpdatePassword(ctx, user.UID, hashedPass); err != nil {
+		return userStoreErrRes(c, err)
+	}
+
+	// A leaked token must not outlive the password.
+	revoked, err := h.apiTokenStore.RevokeAll(ctx, user.UID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	utils.RecordAudit(c, h.auditStore, types.AuditPasswordChange, user.UID, map[string]string{
+		"result":             "success",
+		"api_tokens_revoked": strconv.FormatInt(revoked, 10),
+	})
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "password changed successfully",
+	})
+}
+
+// saveAuthCookies saves the tokens and a fresh CSRF token in the cookies.
+func saveAuthCookies(c echo.Context, token, refreshToken string) error {
+	csrfToken, err := utils.GenerateCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	utils.SaveCookie(c, utils.AccessTokenCookie, token)
+	utils.SaveCookie(c, utils.RefreshTokenCookie, refreshToken)
+	utils.SaveReadableCookie(c, utils.CSRFCookie, csrfToken)
+	return nil
+}
+
 // checkForLoginParams checks if the login parameters are valid.
 func checkForLoginParams(a Auth) []types.Error {
 	var (
@@ -386,57 +559,85 @@ func checkForLoginParams(a Auth) []types.Error {
 	return errors
 }
 
-func userStoreErrResBuilder(err error) types.ApiResponse {
+// auditHash returns the first 16 hex digits of the SHA-256 of a login
+// identifier, case insensitively.
+func auditHash(identifier string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(identifier)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// userStoreErrRes answers a user store error. The context errors are
+// returned instead, the requests that ran out of time or whose client went
+// away are answered with a 504 or a 499 by the error handler.
+func userStoreErrRes(c echo.Context, err error) error {
+	if isContextError(err) {
+		return err
+	}
+	return c.JSON(http.StatusBadRequest, userStoreErrResBuilder(c, err))
+}
+
+// isContextError reports whether err is caused by the end of the request
+// context.
+func isContextError(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+func userStoreErrResBuilder(c echo.Context, err error) types.ApiResponse {
 	var (
 		apiRes types.ApiResponse = types.ApiResponse{
 			Status: types.Failure.String(),
 			Code:   http.StatusBadRequest,
 		}
-		errors []types.Error
+		fieldErrs []types.Error
 	)
 
-	switch err {
-	case interfaces.ErrUserNotFound:
+	switch {
+	case errors.Is(err, interfaces.ErrUserNotFound):
 		apiRes.Code = http.StatusNotFound
 		apiRes.Type = types.ErrorTypeNotFound.String()
-	case interfaces.ErrFailedToGetUser:
+	case errors.Is(err, interfaces.ErrFailedToGetUser):
 		apiRes.Code = http.StatusBadRequest
 		apiRes.Type = types.ErrorTypeInternal.String()
 		apiRes.Message = "failed to get user"
-	case interfaces.ErrFailedToCreateUser:
+	case errors.Is(err, interfaces.ErrFailedToCreateUser):
 		apiRes.Code = http.StatusBadRequest
 		apiRes.Type = types.ErrorTypeInternal.String()
 		apiRes.Message = "failed to create user"
-	case interfaces.ErrFailedToUpdateUser:
+	case errors.Is(err, interfaces.ErrFailedToUpdateUser):
 		apiRes.Code = http.StatusBadRequest
 		apiRes.Type = types.ErrorTypeInternal.String()
 		apiRes.Message = "failed to update user"
-	case interfaces.ErrFailedToDeleteUser:
+	case errors.Is(err, interfaces.ErrFailedToDeleteUser):
 		apiRes.Code = http.StatusBadRequest
 		apiRes.Type = types.ErrorTypeInternal.String()
 		apiRes.Message = "failed to delete user"
-	case interfaces.ErrEmailExists:
+	case errors.Is(err, interfaces.ErrEmailExists):
 		apiRes.Code = http.StatusConflict
 		apiRes.Type = types.ErrorTypeConflict.String()
 		apiRes.Message = "conflict occurred"
-		errors = append(errors, types.Error{
+		fieldErrs = append(fieldErrs, types.Error{
 			Field:  "email",
 			Reason: "email already exists",
 		})
-		apiRes.Errors = append(apiRes.Errors, errors...)
-	case interfaces.ErrUsernameExists:
+		apiRes.Errors = append(apiRes.Errors, fieldErrs...)
+	case errors.Is(err, interfaces.ErrUsernameExists):
 		apiRes.Code = http.StatusConflict
 		apiRes.Type = types.ErrorTypeConflict.String()
 		apiRes.Message = "conflict occurred"
-		errors = append(errors, types.Error{
+		fieldErrs = append(fieldErrs, types.Error{
 			Field:  "username",
 			Reason: "username already exists",
 		})
-		apiRes.Errors = append(apiRes.Errors, errors...)
+		apiRes.Errors = append(apiRes.Errors, fieldErrs...)
 	default:
 		apiRes.Code = http.StatusBadRequest
 		apiRes.Type = types.ErrorTypeUnknown.String()
 		apiRes.Message = "unknown error occurred"
 	}
+
+	// Only the generic message is sent, the cause is logged.
+	if apiRes.Type == types.ErrorTypeInternal.String() || apiRes.Type == types.ErrorTypeUnknown.String() {
+		utils.LogError(c, "user store failed", err)
+	}
 	return apiRes
 }
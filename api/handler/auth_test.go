@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/labstack/echo/v4"
+)
+
+// unknownUserStore knows no user, the other methods panic.
+type unknownUserStore struct {
+	interfaces.UserStore
+}
+
+func (s *unknownUserStore) GetByUsername(ctx context.Context, username string) (*types.User, error) {
+	return nil, interfaces.ErrUserNotFound
+}
+
+func (s *unknownUserStore) GetByEmail(ctx context.Context, email string) (*types.User, error) {
+	return nil, interfaces.ErrUserNotFound
+}
+
+// fakeAuditStore keeps the recorded events, the other methods panic.
+type fakeAuditStore struct {
+	interfaces.AuditStore
+	events []*types.AuditEvent
+}
+
+func (s *fakeAuditStore) Record(ctx context.Context, event *types.AuditEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+// login sends a login request from a client ip.
+func login(t *testing.T, h *AuthHandler, ip, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderXRealIP, ip)
+	rec := httptest.NewRecorder()
+
+	if err := h.Login(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return rec
+}
+
+func TestLoginUnknownUserAudit(t *testing.T) {
+	audit := &fakeAuditStore{}
+	h := NewAuthHandler(nil, &unknownUserStore{}, nil, nil, nil, audit)
+
+	for i := 0; i < unknownUserAuditRate.Burst+5; i++ {
+		if rec := login(t, h, "203.0.113.7", `{"username": "Mallory", "password": "hunter22"}`); rec.Code != http.StatusNotFound {
+			t.Fatalf("got status %d, want 404", rec.Code)
+		}
+	}
+	if len(audit.events) != unknownUserAuditRate.Burst {
+		t.Fatalf("got %d audit events, want the burst of %d", len(audit.events), unknownUserAuditRate.Burst)
+	}
+
+	metadata := audit.events[0].Metadata
+	if metadata["username_hash"] != auditHash("mallory") || len(metadata["username_hash"]) != 16 {
+		t.Errorf("got metadata %v, want the truncated hash of the username", metadata)
+	}
+	for key, value := range metadata {
+		if strings.Contains(strings.ToLower(value), "mallory") {
+			t.Errorf("%s keeps the typed username: %q", key, value)
+		}
+	}
+
+	// The other clients have their own limit.
+	login(t, h, "198.51.100.4", `{"email": "someone@example.com", "password": "hunter22"}`)
+	if n := len(audit.events); n != unknownUserAuditRate.Burst+1 {
+		t.Fatalf("got %d audit events, want the event of another client", n)
+	}
+	if metadata := audit.events[len(audit.events)-1].Metadata; metadata["email_hash"] != auditHash("someone@example.com") {
+		t.Errorf("got metadata %v, want the truncated hash of the email", metadata)
+	}
+}
+
+func TestValidationErrRes(t *testing.T) {
+	a := validationErrRes([]types.Error{{Field: "username", Reason: "required"}})
+	b := validationErrRes(nil)
+
+	if len(a.Errors) != 1 || b.Errors != nil {
+		t.Errorf("the responses share their errors: %v and %v", a.Errors, b.Errors)
+	}
+	if b.Code != http.StatusBadRequest || b.Type != types.ErrorTypeValidation.String() {
+		t.Errorf("got %+v", b)
+	}
+}
@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// getUserUID returns the uuid of the authenticated user.
+func getUserUID(c echo.Context) (uuid.UUID, error) {
+	uid, ok := c.Get("uid").(string)
+	if !ok {
+		return uuid.Nil, errors.New("user id missing from context")
+	}
+	return uuid.Parse(uid)
+}
@@ -0,0 +1,407 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/coderero/erochat-server/api/utils"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// FriendListHandler represents an HTTP handler for friend lists and the
+// users statuses are hidden from.
+type FriendListHandler struct {
+	// validate is the validator.
+	validate *validator.Validate
+
+	// friendStore is a data store for friend.
+	friendStore interfaces.FriendStore
+
+	// friendListStore is a data store for friend lists.
+	friendListStore interfaces.FriendListStore
+}
+
+// SaveFriendList represents a request to create or rename a friend list.
+type SaveFriendList struct {
+	// Name is the name of the list.
+	Name string `json:"name" validate:"required,max=64"`
+}
+
+// NewFriendListHandler creates a new FriendListHandler.
+func NewFriendListHandler(validator *validator.Validate, friendStore interfaces.FriendStore, friendListStore interfaces.FriendListStore) *FriendListHandler {
+	return &FriendListHandler{
+		validate:        validator,
+		friendStore:     friendStore,
+		friendListStore: friendListStore,
+	}
+}
+
+var (
+	lnf = &echo.HTTPError{
+		Code:    echo.ErrNotFound.Code,
+		Message: "friend list not found",
+	}
+	errFriendListExists = &echo.HTTPError{
+		Code:    echo.ErrConflict.Code,
+		Message: "a friend list with this name already exists",
+	}
+	errNotAFriend = &echo.HTTPError{
+		Code:    echo.ErrBadRequest.Code,
+		Message: "user is not a friend",
+	}
+)
+
+// GetFriendLists gets the friend lists of the authenticated user.
+func (h *FriendListHandler) GetFriendLists(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	lists, err := h.friendListStore.GetByOwner(ctx, userID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "friend lists fetched successfully",
+		Data:    lists,
+	})
+}
+
+// CreateFriendList creates a friend list for the authenticated user.
+func (h *FriendListHandler) CreateFriendList(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	params := new(SaveFriendList)
+	if err := utils.JSONDecode(c, params); err != nil {
+		if strings.Contains(err.Error(), "json:") {
+			return c.JSON(http.StatusBadRequest, utils.JsonBindingErrorBuilder(err))
+		}
+		return err
+	}
+
+	params.Name = strings.TrimSpace(params.Name)
+	if err := h.validate.Struct(params); err != nil {
+		return c.JSON(http.StatusBadRequest, types.ApiResponse{
+			Status:  types.Failure.String(),
+			Code:    http.StatusBadRequest,
+			Type:    types.ErrorTypeValidation.String(),
+			Message: "validation error",
+			Errors:  utils.ConvertValidationErrors(err),
+		})
+	}
+
+	list, err := h.friendListStore.Create(ctx, &types.FriendList{
+		OwnerUID: userID,
+		Name:     params.Name,
+	})
+	if err != nil {
+		if errors.Is(err, interfaces.ErrFriendListExists) {
+			return errFriendListExists
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusCreated, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusCreated,
+		Message: "friend list created successfully",
+		Data:    list,
+	})
+}
+
+// RenameFriendList renames a friend list of the authenticated user.
+func (h *FriendListHandler) RenameFriendList(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, listID, err := h.listParams(c)
+	if err != nil {
+		return err
+	}
+
+	params := new(SaveFriendList)
+	if err := utils.JSONDecode(c, params); err != nil {
+		if strings.Contains(err.Error(), "json:") {
+			return c.JSON(http.StatusBadRequest, utils.JsonBindingErrorBuilder(err))
+		}
+		return err
+	}
+
+	params.Name = strings.TrimSpace(params.Name)
+	if err := h.validate.Struct(params); err != nil {
+		return c.JSON(http.StatusBadRequest, types.ApiResponse{
+			Status:  types.Failure.String(),
+			Code:    http.StatusBadRequest,
+			Type:    types.ErrorTypeValidation.String(),
+			Message: "validation error",
+			Errors:  utils.ConvertValidationErrors(err),
+		})
+	}
+
+	err = h.friendListStore.Rename(ctx, userID, listID, params.Name)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrFriendListNotFound) {
+			return lnf
+		}
+		if errors.Is(err, interfaces.ErrFriendListExists) {
+			return errFriendListExists
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "friend list renamed successfully",
+	})
+}
+
+// DeleteFriendList deletes a friend list of the authenticated user.
+func (h *FriendListHandler) DeleteFriendList(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, listID, err := h.listParams(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.friendListStore.Delete(ctx, userID, listID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrFriendListNotFound) {
+			return lnf
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "friend list deleted successfully",
+	})
+}
+
+// GetFriendListMembers gets the members of a friend list of the authenticated user.
+func (h *FriendListHandler) GetFriendListMembers(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, listID, err := h.listParams(c)
+	if err != nil {
+		return err
+	}
+
+	members, err := h.friendListStore.GetMembers(ctx, userID, listID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrFriendListNotFound) {
+			return lnf
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "friend list members fetched successfully",
+		Data:    members,
+	})
+}
+
+// AddFriendListMember adds a friend to a friend list of the authenticated user.
+func (h *FriendListHandler) AddFriendListMember(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, listID, err := h.listParams(c)
+	if err != nil {
+		return err
+	}
+
+	memberID, err := h.friendParam(c, userID, "member")
+	if err != nil {
+		return err
+	}
+
+	err = h.friendListStore.AddMember(ctx, userID, listID, memberID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrFriendListNotFound) {
+			return lnf
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "friend added to the list successfully",
+	})
+}
+
+// RemoveFriendListMember removes a user from a friend list of the authenticated user.
+func (h *FriendListHandler) RemoveFriendListMember(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, listID, err := h.listParams(c)
+	if err != nil {
+		return err
+	}
+
+	memberID, err := uuid.Parse(c.Param("member"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid user id",
+		}
+	}
+
+	err = h.friendListStore.RemoveMember(ctx, userID, listID, memberID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrFriendListMemberNotFound) {
+			return &echo.HTTPError{
+				Code:    echo.ErrNotFound.Code,
+				Message: "user is not in the friend list",
+			}
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "user removed from the list successfully",
+	})
+}
+
+// GetStatusHiddenFrom gets the users the authenticated user hides their statuses from.
+func (h *FriendListHandler) GetStatusHiddenFrom(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	hidden, err := h.friendListStore.GetHiddenFrom(ctx, userID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "hidden users fetched successfully",
+		Data:    hidden,
+	})
+}
+
+// HideStatusFrom hides the statuses of the authenticated user from a friend.
+func (h *FriendListHandler) HideStatusFrom(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	friendID, err := h.friendParam(c, userID, "uid")
+	if err != nil {
+		return err
+	}
+
+	if err = h.friendListStore.HideFrom(ctx, userID, friendID); err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "statuses hidden successfully",
+	})
+}
+
+// UnhideStatusFrom shows the statuses of the authenticated user to a user again.
+func (h *FriendListHandler) UnhideStatusFrom(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	hiddenID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid user id",
+		}
+	}
+
+	err = h.friendListStore.UnhideFrom(ctx, userID, hiddenID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrFriendListMemberNotFound) {
+			return &echo.HTTPError{
+				Code:    echo.ErrNotFound.Code,
+				Message: "statuses are not hidden from this user",
+			}
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "statuses shown successfully",
+	})
+}
+
+// listParams returns the authenticated user and the friend list in the url.
+func (h *FriendListHandler) listParams(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	userID, err := getUserUID(c)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, sww
+	}
+
+	listID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid friend list id",
+		}
+	}
+	return userID, listID, nil
+}
+
+// friendParam returns the user in the url parameter if they're a friend of
+// the authenticated user.
+func (h *FriendListHandler) friendParam(c echo.Context, userID uuid.UUID, param string) (uuid.UUID, error) {
+	ctx := c.Request().Context()
+
+	friendID, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		return uuid.Nil, &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid user id",
+		}
+	}
+
+	friends, err := h.friendStore.AreFriends(ctx, userID, friendID)
+	if err != nil {
+		return uuid.Nil, sww
+	}
+	if !friends {
+		return uuid.Nil, errNotAFriend
+	}
+	return friendID, nil
+}
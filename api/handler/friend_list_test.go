@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// fakeFriendListStore keeps the lists and the hidden users in memory, the
+// other methods panic.
+type fakeFriendListStore struct {
+	interfaces.FriendListStore
+	lists  map[uuid.UUID]*types.FriendList
+	hidden map[[2]uuid.UUID]bool
+}
+
+func (s *fakeFriendListStore) Get(ctx context.Context, ownerID, listID uuid.UUID) (*types.FriendList, error) {
+	list, ok := s.lists[listID]
+	if !ok || list.OwnerUID != ownerID {
+		return nil, interfaces.ErrFriendListNotFound
+	}
+	return list, nil
+}
+
+func (s *fakeFriendListStore) HideFrom(ctx context.Context, ownerID, userID uuid.UUID) error {
+	s.hidden[[2]uuid.UUID{ownerID, userID}] = true
+	return nil
+}
+
+func (s *fakeFriendListStore) UnhideFrom(ctx context.Context, ownerID, userID uuid.UUID) error {
+	if !s.hidden[[2]uuid.UUID{ownerID, userID}] {
+		return interfaces.ErrFriendListMemberNotFound
+	}
+	delete(s.hidden, [2]uuid.UUID{ownerID, userID})
+	return nil
+}
+
+// createdStatusStore keeps the created status, the other methods panic.
+type createdStatusStore struct {
+	interfaces.StatusStore
+	created *types.UserStatus
+}
+
+func (s *createdStatusStore) CreateStatus(ctx context.Context, status *types.UserStatus) (*types.UserStatus, error) {
+	s.created = status
+	return status, nil
+}
+
+func TestCreateStatusAudience(t *testing.T) {
+	var (
+		user      = uuid.New()
+		ownList   = &types.FriendList{UID: uuid.New(), OwnerUID: user, Name: "close friends"}
+		otherList = &types.FriendList{UID: uuid.New(), OwnerUID: uuid.New(), Name: "close friends"}
+	)
+	lists := &fakeFriendListStore{lists: map[uuid.UUID]*types.FriendList{
+		ownList.UID:   ownList,
+		otherList.UID: otherList,
+	}}
+
+	tests := []struct {
+		name     string
+		audience string
+		listUID  string
+		code     int
+		list     uuid.NullUUID
+	}{
+		{name: "default", code: http.StatusOK},
+		{name: "everyone", audience: "everyone", code: http.StatusOK},
+		{name: "everyone ignores the list", audience: "everyone", listUID: ownList.UID.String(), code: http.StatusOK},
+		{name: "list", audience: "list", listUID: ownList.UID.String(), code: http.StatusOK, list: uuid.NullUUID{UUID: ownList.UID, Valid: true}},
+		{name: "except list", audience: "except_list", listUID: ownList.UID.String(), code: http.StatusOK, list: uuid.NullUUID{UUID: ownList.UID, Valid: true}},
+		{name: "list missing", audience: "list", code: http.StatusBadRequest},
+		{name: "except list missing", audience: "except_list", code: http.StatusBadRequest},
+		{name: "list of another user", audience: "list", listUID: otherList.UID.String(), code: http.StatusNotFound},
+		{name: "unknown list", audience: "except_list", listUID: uuid.NewString(), code: http.StatusNotFound},
+		{name: "invalid list", audience: "list", listUID: "close-friends", code: http.StatusBadRequest},
+		{name: "unknown audience", audience: "friends", code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := &createdStatusStore{}
+			h := NewUserStatusHandler(validator.New(), nil, statuses, lists, nil, nil)
+
+			body := fmt.Sprintf(`{"resource_url": "https://cdn.erochat.example/a.jpg", "resource_thumbnail": "https://cdn.erochat.example/a.thumb.jpg", "title": "a", "audience": %q, "list_uid": %q}`, tt.audience, tt.listUID)
+			c, rec := userContext(http.MethodPost, user, "", body)
+			if code := httpCode(h.CreateStatus(c), rec); code != tt.code {
+				t.Fatalf("got status %d, want %d", code, tt.code)
+			}
+
+			if tt.code != http.StatusOK {
+				if statuses.created != nil {
+					t.Error("the status was created")
+				}
+				return
+			}
+			if statuses.created.Audience != types.StatusAudience(tt.audience) || statuses.created.ListUID != tt.list {
+				t.Errorf("got audience %q of list %v, want %q of list %v", statuses.created.Audience, statuses.created.ListUID, tt.audience, tt.list)
+			}
+		})
+	}
+}
+
+func TestHideStatusFrom(t *testing.T) {
+	var (
+		user     = uuid.New()
+		friend   = uuid.New()
+		stranger = uuid.New()
+	)
+
+	tests := []struct {
+		name  string
+		param string
+		code  int
+	}{
+		{"friend", friend.String(), http.StatusOK},
+		{"not a friend", stranger.String(), http.StatusBadRequest},
+		{"invalid uid", "not-a-uuid", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			friends := &blockFriendStore{friends: map[[2]uuid.UUID]bool{{friend, user}: true}}
+			lists := &fakeFriendListStore{hidden: map[[2]uuid.UUID]bool{}}
+			h := NewFriendListHandler(nil, friends, lists)
+
+			c, rec := userContext(http.MethodPost, user, tt.param, "")
+			if code := httpCode(h.HideStatusFrom(c), rec); code != tt.code {
+				t.Fatalf("got status %d, want %d", code, tt.code)
+			}
+			if hidden := len(lists.hidden) > 0; hidden != (tt.code == http.StatusOK) {
+				t.Errorf("hidden: %v, want %v", hidden, tt.code == http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestUnhideStatusFrom(t *testing.T) {
+	var (
+		user   = uuid.New()
+		hidden = uuid.New()
+		// A former friend stays hidden until they're shown the statuses again.
+		former = uuid.New()
+	)
+
+	tests := []struct {
+		name  string
+		param string
+		code  int
+	}{
+		{"hidden", hidden.String(), http.StatusOK},
+		{"former friend", former.String(), http.StatusOK},
+		{"not hidden", uuid.NewString(), http.StatusNotFound},
+		{"invalid uid", "not-a-uuid", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lists := &fakeFriendListStore{hidden: map[[2]uuid.UUID]bool{
+				{user, hidden}: true,
+				{user, former}: true,
+			}}
+			h := NewFriendListHandler(nil, nil, lists)
+
+			c, rec := userContext(http.MethodDelete, user, tt.param, "")
+			if code := httpCode(h.UnhideStatusFrom(c), rec); code != tt.code {
+				t.Fatalf("got status %d, want %d", code, tt.code)
+			}
+			want := 2
+			if tt.code == http.StatusOK {
+				want = 1
+			}
+			if n := len(lists.hidden); n != want {
+				t.Errorf("got %d hidden users, want %d", n, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/labstack/echo/v4"
+)
+
+// HealthHandler represents an HTTP handler for the health, readiness and
+// diagnostics of the instance.
+type HealthHandler struct {
+	// checks are the dependencies checked by the readiness, by name.
+	checks map[string]interfaces.HealthChecker
+
+	// timeout bounds every dependency check.
+	timeout time.Duration
+
+	// pool reports the MySQL connection pool statistics.
+	pool interfaces.PoolStatsProvider
+
+	// version is the build version.
+	version string
+
+	// startedAt is when the instance started.
+	startedAt time.Time
+
+	// shuttingDown makes the instance unready during the shutdown.
+	shuttingDown atomic.Bool
+}
+
+// NewHealthHandler creates a new HealthHandler.
+func NewHealthHandler(checks map[string]interfaces.HealthChecker, timeout time.Duration, pool interfaces.PoolStatsProvider, version string) *HealthHandler {
+	return &HealthHandler{
+		checks:    checks,
+		timeout:   timeout,
+		pool:      pool,
+		version:   version,
+		startedAt: time.Now(),
+	}
+}
+
+// ShutDown makes the instance unready, so the load balancers stop sending
+// it requests before it stops accepting them.
+func (h *HealthHandler) ShutDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Healthz reports that the process is alive, it never checks dependencies.
+func (h *HealthHandler) Healthz(c echo.Context) error {
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "alive",
+	})
+}
+
+// Readyz reports whether the instance can serve requests, every dependency
+// is checked concurrently with its own timeout.
+func (h *HealthHandler) Readyz(c echo.Context) error {
+	readiness := types.Readiness{
+		Ready:        !h.shuttingDown.Load(),
+		ShuttingDown: h.shuttingDown.Load(),
+		Checks:       make(map[string]types.HealthCheck, len(h.checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, checker := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), h.timeout)
+			defer cancel()
+
+			started := time.Now()
+			err := checker.Check(ctx)
+			check := types.HealthCheck{
+				Healthy:  err == nil,
+				Duration: time.Since(started).String(),
+			}
+			if err != nil {
+				check.Error = err.Error()
+			}
+
+			mu.Lock()
+			readiness.Checks[name] = check
+			readiness.Ready = readiness.Ready && check.Healthy
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if !readiness.Ready {
+		return c.JSON(http.StatusServiceUnavailable, types.ApiResponse{
+			Status:  types.Failure.String(),
+			Code:    http.StatusServiceUnavailable,
+			Message: "not ready",
+			Type:    types.ErrorTypeServiceUnavailable.String(),
+			Data:    readiness,
+		})
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "ready",
+		Data:    readiness,
+	})
+}
+
+// GetDiagnostics returns the build, uptime and connection pool statistics
+// of this instance, for admins.
+func (h *HealthHandler) GetDiagnostics(c echo.Context) error {
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "diagnostics fetched successfully",
+		Data: types.Diagnostics{
+			Version:    h.version,
+			GoVersion:  runtime.Version(),
+			StartedAt:  h.startedAt,
+			Uptime:     time.Since(h.startedAt).Round(time.Second).String(),
+			Goroutines: runtime.NumGoroutine(),
+			MySQL:      h.pool.Stats(),
+		},
+	})
+}
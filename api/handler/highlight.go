@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/coderero/erochat-server/api/utils"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// HighlightHandler represents an HTTP handler for the highlights pinned to
+// the profile of the authenticated user.
+type HighlightHandler struct {
+	// validate is the validator.
+	validate *validator.Validate
+
+	// highlightStore is a data store for highlights.
+	highlightStore interfaces.HighlightStore
+}
+
+// SaveHighlight represents a request to create or rename a highlight.
+type SaveHighlight struct {
+	// Name is the name of the highlight.
+	Name string `json:"name" validate:"required,max=64"`
+}
+
+// NewHighlightHandler creates a new HighlightHandler.
+func NewHighlightHandler(validator *validator.Validate, highlightStore interfaces.HighlightStore) *HighlightHandler {
+	return &HighlightHandler{
+		validate:       validator,
+		highlightStore: highlightStore,
+	}
+}
+
+var (
+	hnf = &echo.HTTPError{
+		Code:    echo.ErrNotFound.Code,
+		Message: "highlight not found",
+	}
+	errHighlightExists = &echo.HTTPError{
+		Code:    echo.ErrConflict.Code,
+		Message: "a highlight with this name already exists",
+	}
+)
+
+// GetHighlights gets the highlights of the authenticated user.
+func (h *HighlightHandler) GetHighlights(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	highlights, err := h.highlightStore.GetByOwner(ctx, userID, userID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "highlights fetched successfully",
+		Data:    highlights,
+	})
+}
+
+// CreateHighlight creates a highlight for the authenticated user.
+func (h *HighlightHandler) CreateHighlight(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	params, err := h.decodeHighlight(c)
+	if params == nil {
+		return err
+	}
+
+	highlight, err := h.highlightStore.Create(ctx, &types.Highlight{
+		OwnerUID: userID,
+		Name:     params.Name,
+	})
+	if err != nil {
+		if errors.Is(err, interfaces.ErrHighlightExists) {
+			return errHighlightExists
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusCreated, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusCreated,
+		Message: "highlight created successfully",
+		Data:    highlight,
+	})
+}
+
+// RenameHighlight renames a highlight of the authenticated user.
+func (h *HighlightHandler) RenameHighlight(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, highlightID, err := h.highlightParams(c)
+	if err != nil {
+		return err
+	}
+
+	params, err := h.decodeHighlight(c)
+	if params == nil {
+		return err
+	}
+
+	err = h.highlightStore.Rename(ctx, userID, highlightID, params.Name)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrHighlightNotFound) {
+			return hnf
+		}
+		if errors.Is(err, interfaces.ErrHighlightExists) {
+			return errHighlightExists
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "highlight renamed successfully",
+	})
+}
+
+// DeleteHighlight deletes a highlight of the authenticated user, its
+// statuses stay in the archive.
+func (h *HighlightHandler) DeleteHighlight(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, highlightID, err := h.highlightParams(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.highlightStore.Delete(ctx, userID, highlightID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrHighlightNotFound) {
+			return hnf
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "highlight deleted successfully",
+	})
+}
+
+// AddHighlightStatus pins a status of the authenticated user to a highlight.
+func (h *HighlightHandler) AddHighlightStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, highlightID, err := h.highlightParams(c)
+	if err != nil {
+		return err
+	}
+
+	statusID, err := uuid.Parse(c.Param("sid"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid status id",
+		}
+	}
+
+	err = h.highlightStore.AddStatus(ctx, userID, highlightID, statusID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrHighlightNotFound) {
+			return hnf
+		}
+		if errors.Is(err, interfaces.ErrStatusNotFound) {
+			return &echo.HTTPError{
+				Code:    echo.ErrNotFound.Code,
+				Message: "status not found",
+			}
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "status added to the highlight successfully",
+	})
+}
+
+// RemoveHighlightStatus removes a status from a highlight of the authenticated user.
+func (h *HighlightHandler) RemoveHighlightStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, highlightID, err := h.highlightParams(c)
+	if err != nil {
+		return err
+	}
+
+	statusID, err := uuid.Parse(c.Param("sid"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid status id",
+		}
+	}
+
+	err = h.highlightStore.RemoveStatus(ctx, userID, highlightID, statusID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrHighlightStatusNotFound) {
+			return &echo.HTTPError{
+				Code:    echo.ErrNotFound.Code,
+				Message: "status is not in the highlight",
+			}
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "status removed from the highlight successfully",
+	})
+}
+
+// decodeHighlight decodes and validates a highlight request, the params are
+// nil when the response has already been written or an error returned.
+func (h *HighlightHandler) decodeHighlight(c echo.Context) (*SaveHighlight, error) {
+	params := new(SaveHighlight)
+	if err := utils.JSONDecode(c, params); err != nil {
+		if strings.Contains(err.Error(), "json:") {
+			return nil, c.JSON(http.StatusBadRequest, utils.JsonBindingErrorBuilder(err))
+		}
+		return nil, err
+	}
+
+	params.Name = strings.TrimSpace(params.Name)
+	if err := h.validate.Struct(params); err != nil {
+		return nil, c.JSON(http.StatusBadRequest, types.ApiResponse{
+			Status:  types.Failure.String(),
+			Code:    http.StatusBadRequest,
+			Type:    types.ErrorTypeValidation.String(),
+			Message: "validation error",
+			Errors:  utils.ConvertValidationErrors(err),
+		})
+	}
+	return params, nil
+}
+
+// highlightParams returns the authenticated user and the highlight in the url.
+func (h *HighlightHandler) highlightParams(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	userID, err := getUserUID(c)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, sww
+	}
+
+	highlightID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid highlight id",
+		}
+	}
+	return userID, highlightID, nil
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/labstack/echo/v4"
+)
+
+// JobHandler represents an HTTP handler for the background jobs.
+type JobHandler struct {
+	// jobRunner runs the background jobs.
+	jobRunner interfaces.JobRunner
+}
+
+// NewJobHandler creates a new JobHandler.
+func NewJobHandler(jobRunner interfaces.JobRunner) *JobHandler {
+	return &JobHandler{
+		jobRunner: jobRunner,
+	}
+}
+
+// GetJobs returns the status of the background jobs on this instance, for admins.
+func (h *JobHandler) GetJobs(c echo.Context) error {
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "jobs fetched successfully",
+		Data:    h.jobRunner.Status(),
+	})
+}
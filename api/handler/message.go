@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coderero/erochat-server/api/utils"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// defaultMessageLimit is the number of messages returned when no limit is given.
+	defaultMessageLimit = 50
+
+	// maxMessageLimit is the maximum number of messages returned at once.
+	maxMessageLimit = 200
+)
+
+// MessageHandler represents an HTTP handler for private messages.
+type MessageHandler struct {
+	// validate is the validator.
+	validate *validator.Validate
+
+	// friendStore is a data store for friend.
+	friendStore interfaces.FriendStore
+
+	// statusStore is a data store for status.
+	statusStore interfaces.StatusStore
+
+	// messageStore is a data store for private messages.
+	messageStore interfaces.MessageStore
+}
+
+// SendMessage represents a request to send a private message.
+type SendMessage struct {
+	// Body is the text of the message.
+	Body string `json:"body" validate:"required,max=4000"`
+}
+
+// NewMessageHandler creates a new MessageHandler.
+func NewMessageHandler(validator *validator.Validate, friendStore interfaces.FriendStore, statusStore interfaces.StatusStore, messageStore interfaces.MessageStore) *MessageHandler {
+	return &MessageHandler{
+		validate:     validator,
+		friendStore:  friendStore,
+		statusStore:  statusStore,
+		messageStore: messageStore,
+	}
+}
+
+var errMessageNotAllowed = &echo.HTTPError{
+	Code:    http.StatusForbidden,
+	Message: "you can't message this user",
+}
+
+// SendMessage sends a private message to a friend.
+func (h *MessageHandler) SendMessage(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	recipientID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid user id",
+		}
+	}
+
+	if userID == recipientID {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "cannot message yourself",
+		}
+	}
+
+	// Only friends can message each other, a block ends the friendship but
+	// is checked too in case the friendship is recreated around it.
+	blocked, err := h.friendStore.IsBlocked(ctx, userID, recipientID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+	friends, err := h.friendStore.AreFriends(ctx, userID, recipientID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+	if blocked || !friends {
+		return errMessageNotAllowed
+	}
+
+	return h.send(c, &types.Message{
+		SenderUID:    userID,
+		RecipientUID: recipientID,
+	})
+}
+
+// ReplyToStatus replies to a status of a friend with a private message to its owner.
+func (h *MessageHandler) ReplyToStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	statusID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid status id",
+		}
+	}
+
+	// The friend status procedure only returns live statuses the user is in
+	// the audience of, from unblocked friends.
+	status, err := h.friendStore.GetFriendStatus(ctx, userID, statusID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrFriendStatusNotFound) {
+			return &echo.HTTPError{
+				Code:    echo.ErrNotFound.Code,
+				Message: "friend status not found",
+			}
+		}
+		return sww.WithInternal(err)
+	}
+
+	return h.send(c, &types.Message{
+		SenderUID:    userID,
+		RecipientUID: status.UID,
+		StatusRef: &types.StatusRef{
+			StatusUID:         status.StatusID,
+			OwnerUID:          status.UID,
+			Title:             status.Title,
+			ResourceThumbnail: status.ResourceThumbnail,
+		},
+	})
+}
+
+// GetConversation gets the most recent messages between the user and another user.
+func (h *MessageHandler) GetConversation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	otherID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid user id",
+		}
+	}
+
+	limit := defaultMessageLimit
+	if v := c.QueryParam("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			return &echo.HTTPError{
+				Code:    echo.ErrBadRequest.Code,
+				Message: "limit must be a positive number",
+			}
+		}
+		if limit > maxMessageLimit {
+			limit = maxMessageLimit
+		}
+	}
+
+	before := time.Now().UTC()
+	if b := c.QueryParam("before"); b != "" {
+		if before, err = time.Parse(time.RFC3339Nano, b); err != nil {
+			return &echo.HTTPError{
+				Code:    echo.ErrBadRequest.Code,
+				Message: "before must be an rfc3339 timestamp",
+			}
+		}
+	}
+
+	messages, err := h.messageStore.GetConversation(ctx, types.ConversationID(userID, otherID), before, limit)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	if err := h.resolveStatusRefs(ctx, messages); err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "messages fetched successfully",
+		Data:    messages,
+	})
+}
+
+// send decodes the body of a message and sends it.
+func (h *MessageHandler) send(c echo.Context, message *types.Message) error {
+	ctx := c.Request().Context()
+
+	params := new(SendMessage)
+	if err := utils.JSONDecode(c, params); err != nil {
+		if strings.Contains(err.Error(), "json:") {
+			return c.JSON(http.StatusBadRequest, utils.JsonBindingErrorBuilder(err))
+		}
+		return err
+	}
+
+	params.Body = strings.TrimSpace(params.Body)
+	if err := h.validate.Struct(params); err != nil {
+		return c.JSON(http.StatusBadRequest, types.ApiResponse{
+			Status:  types.Failure.String(),
+			Code:    http.StatusBadRequest,
+			Type:    types.ErrorTypeValidation.String(),
+			Message: "validation error",
+			Errors:  utils.ConvertValidationErrors(err),
+		})
+	}
+	message.Body = params.Body
+
+	message, err := h.messageStore.Send(ctx, message)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusCreated, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusCreated,
+		Message: "message sent successfully",
+		Data:    message,
+	})
+}
+
+// resolveStatusRefs degrades the references to statuses that expired or were
+// deleted since the replies were sent to an expired marker.
+func (h *MessageHandler) resolveStatusRefs(ctx context.Context, messages []*types.Message) error {
+	var ids []uuid.UUID
+	for _, message := range messages {
+		if message.StatusRef != nil {
+			ids = append(ids, message.StatusRef.StatusUID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	active, err := h.statusStore.GetActiveStatusIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		if ref := message.StatusRef; ref != nil && !active[ref.StatusUID] {
+			ref.Expired = true
+			ref.Title = ""
+			ref.ResourceThumbnail = ""
+		}
+	}
+	return nil
+}
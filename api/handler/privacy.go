@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/coderero/erochat-server/api/utils"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// PrivacyHandler represents an HTTP handler for privacy settings.
+type PrivacyHandler struct {
+	// validate is the validator.
+	validate *validator.Validate
+
+	// privacyStore is a data store for privacy settings.
+	privacyStore interfaces.PrivacyStore
+
+	// searchIndex is the search index of users.
+	searchIndex interfaces.SearchIndex
+}
+
+// UpdatePrivacySettings represents a request to update privacy settings,
+// omitted fields are left unchanged.
+type UpdatePrivacySettings struct {
+	// EmailVisibility is who can see the email.
+	EmailVisibility *types.Visibility `json:"email_visibility" validate:"omitempty,oneof=everyone friends nobody"`
+
+	// BioVisibility is who can see the bio.
+	BioVisibility *types.Visibility `json:"bio_visibility" validate:"omitempty,oneof=everyone friends nobody"`
+
+	// LastSeenVisibility is who can see when the user was last seen.
+	LastSeenVisibility *types.Visibility `json:"last_seen_visibility" validate:"omitempty,oneof=everyone friends nobody"`
+
+	// StatusVisibility is who can see the statuses.
+	StatusVisibility *types.Visibility `json:"status_visibility" validate:"omitempty,oneof=everyone friends nobody"`
+
+	// FriendRequests is who can send friend requests.
+	FriendRequests *types.FriendRequestPolicy `json:"friend_requests" validate:"omitempty,oneof=everyone friends_of_friends nobody"`
+
+	// Searchable is whether the profile appears in search.
+	Searchable *bool `json:"searchable"`
+}
+
+// NewPrivacyHandler creates a new PrivacyHandler.
+func NewPrivacyHandler(validator *validator.Validate, privacyStore interfaces.PrivacyStore, searchIndex interfaces.SearchIndex) *PrivacyHandler {
+	return &PrivacyHandler{
+		validate:     validator,
+		privacyStore: privacyStore,
+		searchIndex:  searchIndex,
+	}
+}
+
+// GetPrivacySettings returns the privacy settings of the authenticated user.
+func (h *PrivacyHandler) GetPrivacySettings(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	settings, err := h.privacyStore.Get(ctx, userID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "privacy settings fetched successfully",
+		Data:    settings,
+	})
+}
+
+// UpdatePrivacySettings updates the privacy settings of the authenticated user.
+func (h *PrivacyHandler) UpdatePrivacySettings(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	params := new(UpdatePrivacySettings)
+	if err := utils.JSONDecode(c, params); err != nil {
+		if strings.Contains(err.Error(), "json:") {
+			return c.JSON(http.StatusBadRequest, utils.JsonBindingErrorBuilder(err))
+		}
+		return err
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return c.JSON(http.StatusBadRequest, types.ApiResponse{
+			Status:  types.Failure.String(),
+			Code:    http.StatusBadRequest,
+			Type:    types.ErrorTypeValidation.String(),
+			Message: "validation error",
+			Errors:  utils.ConvertValidationErrors(err),
+		})
+	}
+
+	settings, err := h.privacyStore.Get(ctx, userID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+	searchable := settings.Searchable
+
+	if params.EmailVisibility != nil {
+		settings.EmailVisibility = *params.EmailVisibility
+	}
+	if params.BioVisibility != nil {
+		settings.BioVisibility = *params.BioVisibility
+	}
+	if params.LastSeenVisibility != nil {
+		settings.LastSeenVisibility = *params.LastSeenVisibility
+	}
+	if params.StatusVisibility != nil {
+		settings.StatusVisibility = *params.StatusVisibility
+	}
+	if params.FriendRequests != nil {
+		settings.FriendRequests = *params.FriendRequests
+	}
+	if params.Searchable != nil {
+		settings.Searchable = *params.Searchable
+	}
+
+	settings, err = h.privacyStore.Update(ctx, settings)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	if settings.Searchable != searchable {
+		if err := h.searchIndex.Reindex(ctx, userID); err != nil {
+			utils.LogError(c, "failed to reindex user", err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "privacy settings updated successfully",
+		Data:    settings,
+	})
+}
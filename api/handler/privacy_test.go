@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// fakePrivacyStore keeps the settings in memory.
+type fakePrivacyStore struct {
+	settings map[uuid.UUID]*types.PrivacySettings
+}
+
+func (s *fakePrivacyStore) Get(ctx context.Context, userID uuid.UUID) (*types.PrivacySettings, error) {
+	if settings, ok := s.settings[userID]; ok {
+		copied := *settings
+		return &copied, nil
+	}
+	return types.DefaultPrivacySettings(userID), nil
+}
+
+func (s *fakePrivacyStore) GetMany(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]*types.PrivacySettings, error) {
+	settings := make(map[uuid.UUID]*types.PrivacySettings, len(userIDs))
+	for _, userID := range userIDs {
+		settings[userID], _ = s.Get(ctx, userID)
+	}
+	return settings, nil
+}
+
+func (s *fakePrivacyStore) Update(ctx context.Context, settings *types.PrivacySettings) (*types.PrivacySettings, error) {
+	s.settings[settings.UserUID] = settings
+	return settings, nil
+}
+
+// fakeSearchIndex records the reindexed users, the other methods panic.
+type fakeSearchIndex struct {
+	interfaces.SearchIndex
+	reindexed []uuid.UUID
+}
+
+func (s *fakeSearchIndex) Reindex(ctx context.Context, userID uuid.UUID) error {
+	s.reindexed = append(s.reindexed, userID)
+	return nil
+}
+
+func TestUpdatePrivacySettings(t *testing.T) {
+	user := uuid.New()
+
+	tests := []struct {
+		name      string
+		body      string
+		code      int
+		want      func(s *types.PrivacySettings)
+		reindexed bool
+	}{
+		{
+			name: "one field",
+			body: `{"bio_visibility": "nobody"}`,
+			code: http.StatusOK,
+			want: func(s *types.PrivacySettings) { s.BioVisibility = types.VisibilityNobody },
+		},
+		{
+			name: "every field",
+			body: `{"email_visibility": "everyone", "last_seen_visibility": "nobody", "status_visibility": "everyone", "friend_requests": "friends_of_friends"}`,
+			code: http.StatusOK,
+			want: func(s *types.PrivacySettings) {
+				s.EmailVisibility = types.VisibilityEveryone
+				s.LastSeenVisibility = types.VisibilityNobody
+				s.StatusVisibility = types.VisibilityEveryone
+				s.FriendRequests = types.FriendRequestsFriendsOfFriends
+			},
+		},
+		{
+			name:      "searchable",
+			body:      `{"searchable": false}`,
+			code:      http.StatusOK,
+			want:      func(s *types.PrivacySettings) { s.Searchable = false },
+			reindexed: true,
+		},
+		{
+			name: "searchable unchanged",
+			body: `{"searchable": true}`,
+			code: http.StatusOK,
+			want: func(s *types.PrivacySettings) {},
+		},
+		{
+			name: "unknown visibility",
+			body: `{"bio_visibility": "friends_of_friends"}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "unknown policy",
+			body: `{"friend_requests": "friends"}`,
+			code: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privacy := &fakePrivacyStore{settings: map[uuid.UUID]*types.PrivacySettings{}}
+			search := &fakeSearchIndex{}
+			h := NewPrivacyHandler(validator.New(), privacy, search)
+
+			c, rec := userContext(http.MethodPatch, user, "", tt.body)
+			if code := httpCode(h.UpdatePrivacySettings(c), rec); code != tt.code {
+				t.Fatalf("got status %d, want %d", code, tt.code)
+			}
+
+			got, _ := privacy.Get(context.Background(), user)
+			want := types.DefaultPrivacySettings(user)
+			if tt.want != nil {
+				tt.want(want)
+			}
+			if *got != *want {
+				t.Errorf("got settings %+v, want %+v", got, want)
+			}
+			if reindexed := len(search.reindexed) > 0; reindexed != tt.reindexed {
+				t.Errorf("reindexed: %v, want %v", reindexed, tt.reindexed)
+			}
+		})
+	}
+}
+
+func TestHidePrivateFields(t *testing.T) {
+	var (
+		everyone = uuid.New()
+		friends  = uuid.New()
+		nobody   = uuid.New()
+		unset    = uuid.New()
+	)
+	privacy := &fakePrivacyStore{settings: map[uuid.UUID]*types.PrivacySettings{}}
+	for uid, visibility := range map[uuid.UUID]types.Visibility{
+		everyone: types.VisibilityEveryone,
+		friends:  types.VisibilityFriends,
+		nobody:   types.VisibilityNobody,
+	} {
+		settings := types.DefaultPrivacySettings(uid)
+		settings.BioVisibility = visibility
+		privacy.settings[uid] = settings
+	}
+	h := NewUserFriendShipHandler(nil, nil, nil, privacy, nil, 0)
+
+	for _, isFriend := range []bool{true, false} {
+		list := []*types.Friend{
+			{UID: everyone, Bio: "bio"},
+			{UID: friends, Bio: "bio"},
+			{UID: nobody, Bio: "bio"},
+			{UID: unset, Bio: "bio"},
+		}
+		if err := h.hidePrivateFields(context.Background(), list, isFriend); err != nil {
+			t.Fatal(err)
+		}
+
+		shown := map[uuid.UUID]bool{}
+		for _, friend := range list {
+			shown[friend.UID] = friend.Bio != ""
+		}
+		want := map[uuid.UUID]bool{everyone: true, friends: isFriend, nobody: false, unset: true}
+		for uid, w := range want {
+			if shown[uid] != w {
+				t.Errorf("friend %v: bio of %s shown %v, want %v", isFriend, uid, shown[uid], w)
+			}
+		}
+	}
+}
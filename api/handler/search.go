@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// defaultSearchLimit is the number of results returned when no limit is given.
+	defaultSearchLimit = 20
+
+	// maxSearchLimit is the maximum number of results returned at once.
+	maxSearchLimit = 50
+
+	// maxSearchQueryLength is the maximum length of a search query.
+	maxSearchQueryLength = 100
+)
+
+// SearchHandler represents an HTTP handler for user search.
+type SearchHandler struct {
+	// searchIndex is the search index of users.
+	searchIndex interfaces.SearchIndex
+}
+
+// NewSearchHandler creates a new SearchHandler.
+func NewSearchHandler(searchIndex interfaces.SearchIndex) *SearchHandler {
+	return &SearchHandler{
+		searchIndex: searchIndex,
+	}
+}
+
+// SearchUsers searches users by username, first name and last name.
+func (h *SearchHandler) SearchUsers(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	query := types.SearchQuery{
+		Query:     strings.TrimSpace(c.QueryParam("q")),
+		ViewerUID: userID,
+		Limit:     defaultSearchLimit,
+	}
+
+	if query.Query == "" || len(query.Query) > maxSearchQueryLength {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "q is required and must be at most 100 characters",
+		}
+	}
+
+	if v := c.QueryParam("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil || query.Limit <= 0 {
+			return &echo.HTTPError{
+				Code:    echo.ErrBadRequest.Code,
+				Message: "limit must be a positive number",
+			}
+		}
+		if query.Limit > maxSearchLimit {
+			query.Limit = maxSearchLimit
+		}
+	}
+
+	if v := c.QueryParam("cursor"); v != "" {
+		if query.Cursor, err = types.ParseSearchCursor(v); err != nil {
+			return &echo.HTTPError{
+				Code:    echo.ErrBadRequest.Code,
+				Message: "invalid cursor",
+			}
+		}
+	}
+
+	page, err := h.searchIndex.Search(ctx, query)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "users fetched successfully",
+		Data:    page,
+	})
+}
@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/coderero/erochat-server/api/utils"
 	"github.com/coderero/erochat-server/interfaces"
 	"github.com/coderero/erochat-server/types"
 	"github.com/go-playground/validator/v10"
@@ -19,16 +24,28 @@ type UserFriendShipHandler struct {
 	// friendStore is a data store for friend.
 	friendStore interfaces.FriendStore
 
+	// privacyStore is a data store for privacy settings.
+	privacyStore interfaces.PrivacyStore
+
+	// statusStore is a data store for status.
+	statusStore interfaces.StatusStore
+
 	// validate is a validator that validates the request.
 	validate *validator.Validate
+
+	// declineCooldown is how long a declined sender can't send a new request.
+	declineCooldown time.Duration
 }
 
 // NewUserFriendShipHandler returns a new user friend ship handler.
-func NewUserFriendShipHandler(validator *validator.Validate, userStore interfaces.UserStore, friendStore interfaces.FriendStore) *UserFriendShipHandler {
+func NewUserFriendShipHandler(validator *validator.Validate, userStore interfaces.UserStore, friendStore interfaces.FriendStore, privacyStore interfaces.PrivacyStore, statusStore interfaces.StatusStore, declineCooldown time.Duration) *UserFriendShipHandler {
 	return &UserFriendShipHandler{
-		userStore:   userStore,
-		friendStore: friendStore,
-		validate:    validator,
+		userStore:       userStore,
+		friendStore:     friendStore,
+		privacyStore:    privacyStore,
+		statusStore:     statusStore,
+		validate:        validator,
+		declineCooldown: declineCooldown,
 	}
 }
 
@@ -45,6 +62,8 @@ var (
 
 // GetFriends gets the friends of a user.
 func (u *UserFriendShipHandler) GetFriends(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	uid, ok := c.Get("uid").(string)
 	if !ok {
 		return sww
@@ -53,12 +72,16 @@ func (u *UserFriendShipHandler) GetFriends(c echo.Context) error {
 	// Parse the user id.
 	userID, err := uuid.Parse(uid)
 	if err != nil {
-		return sww
+		return sww.WithInternal(err)
 	}
 
-	friends, err := u.friendStore.GetFriends(userID)
+	friends, err := u.friendStore.GetFriends(ctx, userID)
 	if err != nil {
-		return sww
+		return sww.WithInternal(err)
+	}
+
+	if err := u.hidePrivateFields(ctx, friends, true); err != nil {
+		return sww.WithInternal(err)
 	}
 
 	res := types.ApiResponse{
@@ -73,6 +96,8 @@ func (u *UserFriendShipHandler) GetFriends(c echo.Context) error {
 
 // GetFriend gets a friend by its id.
 func (u *UserFriendShipHandler) GetFriend(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	uid, ok := c.Get("uid").(string)
 	if !ok {
 		return sww
@@ -81,7 +106,7 @@ func (u *UserFriendShipHandler) GetFriend(c echo.Context) error {
 	// Parse the user id.
 	userID, err := uuid.Parse(uid)
 	if err != nil {
-		return sww
+		return sww.WithInternal(err)
 	}
 
 	fid := c.Param("uid")
@@ -94,19 +119,22 @@ func (u *UserFriendShipHandler) GetFriend(c echo.Context) error {
 
 	friendID, err := uuid.Parse(fid)
 	if err != nil {
-		fmt.Println(err)
 		return &echo.HTTPError{
 			Code:    echo.ErrBadRequest.Code,
 			Message: "invalid user id",
 		}
 	}
 
-	friend, err := u.friendStore.GetFriend(userID, friendID)
+	friend, err := u.friendStore.GetFriend(ctx, userID, friendID)
 	if err != nil {
 		if errors.Is(err, interfaces.ErrFriendNotFound) {
 			return fnf
 		}
-		return sww
+		return sww.WithInternal(err)
+	}
+
+	if err := u.hidePrivateFields(ctx, []*types.Friend{friend}, true); err != nil {
+		return sww.WithInternal(err)
 	}
 
 	res := types.ApiResponse{
@@ -121,6 +149,8 @@ func (u *UserFriendShipHandler) GetFriend(c echo.Context) error {
 
 // DeleteFriend deletes a friend by its id.
 func (u *UserFriendShipHandler) DeleteFriend(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	uid, ok := c.Get("uid").(string)
 	if !ok {
 		return sww
@@ -129,7 +159,7 @@ func (u *UserFriendShipHandler) DeleteFriend(c echo.Context) error {
 	// Parse the user id.
 	userID, err := uuid.Parse(uid)
 	if err != nil {
-		return sww
+		return sww.WithInternal(err)
 	}
 
 	fid := c.Param("uid")
@@ -147,12 +177,12 @@ func (u *UserFriendShipHandler) DeleteFriend(c echo.Context) error {
 		}
 	}
 
-	err = u.friendStore.DeleteFriend(userID, friendID)
+	err = u.friendStore.DeleteFriend(ctx, userID, friendID)
 	if err != nil {
 		if errors.Is(err, interfaces.ErrFriendNotFound) {
 			return fnf
 		}
-		return sww
+		return sww.WithInternal(err)
 	}
 
 	res := types.ApiResponse{
@@ -166,6 +196,8 @@ func (u *UserFriendShipHandler) DeleteFriend(c echo.Context) error {
 
 // GetFriendRequests gets the friend requests of a user.
 func (u *UserFriendShipHandler) GetFriendRequests(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	uid, ok := c.Get("uid").(string)
 	if !ok {
 		return sww
@@ -174,12 +206,16 @@ func (u *UserFriendShipHandler) GetFriendRequests(c echo.Context) error {
 	// Parse the user id.
 	userID, err := uuid.Parse(uid)
 	if err != nil {
-		return sww
+		return sww.WithInternal(err)
 	}
 
-	requests, err := u.friendStore.GetFriendRequests(userID)
+	requests, err := u.friendStore.GetFriendRequests(ctx, userID)
 	if err != nil {
-		return sww
+		return sww.WithInternal(err)
+	}
+
+	if err := u.hidePrivateFields(ctx, requests, false); err != nil {
+		return sww.WithInternal(err)
 	}
 
 	res := types.ApiResponse{
@@ -192,8 +228,48 @@ func (u *UserFriendShipHandler) GetFriendRequests(c echo.Context) error {
 	return c.JSON(http.StatusOK, res)
 }
 
+// GetIncomingFriendRequests gets the friend requests received by a user.
+func (u *UserFriendShipHandler) GetIncomingFriendRequests(c echo.Context) error {
+	return u.getFriendRequestsByDirection(c, types.FriendRequestIncoming)
+}
+
+// GetOutgoingFriendRequests gets the friend requests sent by a user.
+func (u *UserFriendShipHandler) GetOutgoingFriendRequests(c echo.Context) error {
+	return u.getFriendRequestsByDirection(c, types.FriendRequestOutgoing)
+}
+
+// getFriendRequestsByDirection gets the incoming or outgoing friend requests of a user.
+func (u *UserFriendShipHandler) getFriendRequestsByDirection(c echo.Context, direction types.FriendRequestDirection) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	requests, err := u.friendStore.GetFriendRequestsByDirection(ctx, userID, direction)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	if err := u.hidePrivateFields(ctx, requests, false); err != nil {
+		return sww.WithInternal(err)
+	}
+
+	res := types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: fmt.Sprintf("%s friend requests fetched successfully", direction),
+		Data:    requests,
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
 // GetFriendRequest gets a friend request by its id.
 func (u *UserFriendShipHandler) GetFriendRequest(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	uUID, ok := c.Get("uid").(string)
 	if !ok {
 		return sww
@@ -202,7 +278,7 @@ func (u *UserFriendShipHandler) GetFriendRequest(c echo.Context) error {
 	// Parse the user id.
 	userID, err := uuid.Parse(uUID)
 	if err != nil {
-		return sww
+		return sww.WithInternal(err)
 	}
 
 	fid := c.Param("uid")
@@ -220,12 +296,16 @@ func (u *UserFriendShipHandler) GetFriendRequest(c echo.Context) error {
 		}
 	}
 
-	request, err := u.friendStore.GetFriendRequest(userID, reqId)
+	request, err := u.friendStore.GetFriendRequest(ctx, userID, reqId)
 	if err != nil {
 		if errors.Is(err, interfaces.ErrFriendNotFound) {
 			return fnf
 		}
-		return sww
+		return sww.WithInternal(err)
+	}
+
+	if err := u.hidePrivateFields(ctx, []*types.Friend{request}, false); err != nil {
+		return sww.WithInternal(err)
 	}
 
 	res := types.ApiResponse{
@@ -240,6 +320,8 @@ func (u *UserFriendShipHandler) GetFriendRequest(c echo.Context) error {
 
 // AcceptFriendRequest accepts a friend request.
 func (u *UserFriendShipHandler) AcceptFriendRequest(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	rUUID, ok := c.Get("uid").(string)
 	if !ok {
 		return sww
@@ -247,7 +329,7 @@ func (u *UserFriendShipHandler) AcceptFriendRequest(c echo.Context) error {
 
 	UUID, err := uuid.Parse(rUUID)
 	if err != nil {
-		return sww
+		return sww.WithInternal(err)
 	}
 
 	fid := c.Param("uid")
@@ -265,12 +347,12 @@ func (u *UserFriendShipHandler) AcceptFriendRequest(c echo.Context) error {
 		}
 	}
 
-	err = u.friendStore.AcceptFriendRequest(UUID, reqId)
+	err = u.friendStore.AcceptFriendRequest(ctx, UUID, reqId)
 	if err != nil {
 		if errors.Is(err, interfaces.ErrFriendNotFound) {
 			return fnf
 		}
-		return sww
+		return sww.WithInternal(err)
 	}
 
 	res := types.ApiResponse{
@@ -282,8 +364,79 @@ func (u *UserFriendShipHandler) AcceptFriendRequest(c echo.Context) error {
 	return c.JSON(http.StatusOK, res)
 }
 
+// CancelFriendRequest cancels a friend request sent by the user.
+func (u *UserFriendShipHandler) CancelFriendRequest(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	reqID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid request id",
+		}
+	}
+
+	err = u.friendStore.CancelFriendRequest(ctx, userID, reqID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrFriendNotFound) {
+			return fnf
+		}
+		return sww.WithInternal(err)
+	}
+
+	res := types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "friend request cancelled successfully",
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// DeclineFriendRequest declines a friend request received by the user, the
+// sender can't send a new one until the cooldown ends.
+func (u *UserFriendShipHandler) DeclineFriendRequest(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	reqID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid request id",
+		}
+	}
+
+	err = u.friendStore.DeclineFriendRequest(ctx, userID, reqID, u.declineCooldown)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrFriendNotFound) {
+			return fnf
+		}
+		return sww.WithInternal(err)
+	}
+
+	res := types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "friend request declined successfully",
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
 // DeleteFriendRequest deletes a friend request by its id.
 func (u *UserFriendShipHandler) DeleteFriendRequest(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	uid, ok := c.Get("uid").(string)
 	if !ok {
 		return sww
@@ -292,7 +445,7 @@ func (u *UserFriendShipHandler) DeleteFriendRequest(c echo.Context) error {
 	// Parse the user id.
 	userID, err := uuid.Parse(uid)
 	if err != nil {
-		return sww
+		return sww.WithInternal(err)
 	}
 
 	fid := c.Param("uid")
@@ -310,13 +463,12 @@ func (u *UserFriendShipHandler) DeleteFriendRequest(c echo.Context) error {
 		}
 	}
 
-	err = u.friendStore.DeleteFriendRequest(userID, relationID)
+	err = u.friendStore.DeleteFriendRequest(ctx, userID, relationID)
 	if err != nil {
-		fmt.Println(err)
 		if errors.Is(err, interfaces.ErrFriendNotFound) {
 			return fnf
 		}
-		return sww
+		return sww.WithInternal(err)
 	}
 
 	res := types.ApiResponse{
@@ -330,6 +482,8 @@ func (u *UserFriendShipHandler) DeleteFriendRequest(c echo.Context) error {
 
 // GetFriendsStatus gets the friends status of a user.
 func (u *UserFriendShipHandler) GetFriendsStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	uid, ok := c.Get("uid").(string)
 	if !ok {
 		return sww
@@ -338,13 +492,25 @@ func (u *UserFriendShipHandler) GetFriendsStatus(c echo.Context) error {
 	// Parse the user id.
 	userID, err := uuid.Parse(uid)
 	if err != nil {
-		return sww
+		return sww.WithInternal(err)
+	}
+
+	// Muted friends are omitted by default, "separate" lists them apart.
+	var opts types.FriendsStatusOptions
+	switch c.QueryParam("muted") {
+	case "", "omit":
+	case "separate":
+		opts.IncludeMuted = true
+	default:
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "muted must be omit or separate",
+		}
 	}
 
-	statu, err := u.friendStore.GetFriendsStatus(userID)
+	statu, err := u.friendStore.GetFriendsStatus(ctx, userID, opts)
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-		return sww
+		return sww.WithInternal(err)
 	}
 
 	res := types.ApiResponse{
@@ -354,11 +520,28 @@ func (u *UserFriendShipHandler) GetFriendsStatus(c echo.Context) error {
 		Data:    statu,
 	}
 
+	if opts.IncludeMuted {
+		unmuted, muted := []*types.FriendStatus{}, []*types.FriendStatus{}
+		for _, status := range statu {
+			if status.Muted {
+				muted = append(muted, status)
+			} else {
+				unmuted = append(unmuted, status)
+			}
+		}
+		res.Data = echo.Map{
+			"statuses": unmuted,
+			"muted":    muted,
+		}
+	}
+
 	return c.JSON(http.StatusOK, res)
 }
 
 // GetFriendStatus gets the friend status of a user.
 func (u *UserFriendShipHandler) GetFriendStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	uid, ok := c.Get("uid").(string)
 	if !ok {
 		return sww
@@ -367,7 +550,7 @@ func (u *UserFriendShipHandler) GetFriendStatus(c echo.Context) error {
 	// Parse the user id.
 	userID, err := uuid.Parse(uid)
 	if err != nil {
-		return sww
+		return sww.WithInternal(err)
 	}
 
 	fid := c.Param("uid")
@@ -385,7 +568,7 @@ func (u *UserFriendShipHandler) GetFriendStatus(c echo.Context) error {
 		}
 	}
 
-	status, err := u.friendStore.GetFriendStatus(userID, friendID)
+	status, err := u.friendStore.GetFriendStatus(ctx, userID, friendID)
 	if err != nil {
 		if errors.Is(err, interfaces.ErrFriendStatusNotFound) {
 			return &echo.HTTPError{
@@ -393,7 +576,14 @@ func (u *UserFriendShipHandler) GetFriendStatus(c echo.Context) error {
 				Message: "friend status not found",
 			}
 		}
-		return sww
+		return sww.WithInternal(err)
+	}
+
+	// Fetching a single status opens it, a failed view must not hide it.
+	if err := u.statusStore.RecordView(ctx, status.StatusID, userID); err != nil {
+		utils.LogError(c, "failed to record status view", err, slog.String("status_uid", status.StatusID.String()))
+	} else {
+		status.Seen = true
 	}
 
 	res := types.ApiResponse{
@@ -405,3 +595,351 @@ func (u *UserFriendShipHandler) GetFriendStatus(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, res)
 }
+
+// MarkStatusViewed marks a status of a friend as viewed by the user.
+func (u *UserFriendShipHandler) MarkStatusViewed(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	statusID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid status id",
+		}
+	}
+
+	// Only statuses the user is allowed to see can be viewed.
+	if _, err := u.friendStore.GetFriendStatus(ctx, userID, statusID); err != nil {
+		if errors.Is(err, interfaces.ErrFriendStatusNotFound) {
+			return &echo.HTTPError{
+				Code:    echo.ErrNotFound.Code,
+				Message: "friend status not found",
+			}
+		}
+		return sww.WithInternal(err)
+	}
+
+	if err := u.statusStore.RecordView(ctx, statusID, userID); err != nil {
+		return sww.WithInternal(err)
+	}
+
+	res := types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "status marked as viewed successfully",
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// BlockUser blocks a user.
+func (u *UserFriendShipHandler) BlockUser(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	bid := c.Param("uid")
+	if len(bid) == 0 {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "uid is required in url parameter",
+		}
+	}
+	blockedID, err := uuid.Parse(bid)
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid user id",
+		}
+	}
+
+	if userID == blockedID {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "cannot block yourself",
+		}
+	}
+
+	if _, err := u.userStore.GetByID(ctx, blockedID); err != nil {
+		if errors.Is(err, interfaces.ErrUserNotFound) {
+			return &echo.HTTPError{
+				Code:    echo.ErrNotFound.Code,
+				Message: "user not found",
+			}
+		}
+		return sww.WithInternal(err)
+	}
+
+	err = u.friendStore.BlockUser(ctx, userID, blockedID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrAlreadyBlocked) {
+			return &echo.HTTPError{
+				Code:    echo.ErrConflict.Code,
+				Message: "user already blocked",
+			}
+		}
+		return sww.WithInternal(err)
+	}
+
+	res := types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "user blocked successfully",
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// UnblockUser unblocks a user.
+func (u *UserFriendShipHandler) UnblockUser(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	bid := c.Param("uid")
+	if len(bid) == 0 {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "uid is required in url parameter",
+		}
+	}
+	blockedID, err := uuid.Parse(bid)
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid user id",
+		}
+	}
+
+	err = u.friendStore.UnblockUser(ctx, userID, blockedID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrBlockNotFound) {
+			return &echo.HTTPError{
+				Code:    echo.ErrNotFound.Code,
+				Message: "user is not blocked",
+			}
+		}
+		return sww.WithInternal(err)
+	}
+
+	res := types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "user unblocked successfully",
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetBlockedUsers gets the users blocked by the user.
+func (u *UserFriendShipHandler) GetBlockedUsers(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	blocked, err := u.friendStore.GetBlockedUsers(ctx, userID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	res := types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "blocked users fetched successfully",
+		Data:    blocked,
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// MuteStatus mutes the statuses of a friend without unfriending them.
+func (u *UserFriendShipHandler) MuteStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	friendID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid user id",
+		}
+	}
+
+	friends, err := u.friendStore.AreFriends(ctx, userID, friendID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+	if !friends {
+		return errNotAFriend
+	}
+
+	if err = u.friendStore.MuteStatus(ctx, userID, friendID); err != nil {
+		return sww.WithInternal(err)
+	}
+
+	res := types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "friend status muted successfully",
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// UnmuteStatus unmutes the statuses of a friend.
+func (u *UserFriendShipHandler) UnmuteStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	friendID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid user id",
+		}
+	}
+
+	err = u.friendStore.UnmuteStatus(ctx, userID, friendID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrMuteNotFound) {
+			return &echo.HTTPError{
+				Code:    echo.ErrNotFound.Code,
+				Message: "friend status is not muted",
+			}
+		}
+		return sww.WithInternal(err)
+	}
+
+	res := types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "friend status unmuted successfully",
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetMutedFriends gets the friends whose statuses are muted by the user.
+func (u *UserFriendShipHandler) GetMutedFriends(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	muted, err := u.friendStore.GetMutedFriends(ctx, userID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	res := types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "muted friends fetched successfully",
+		Data:    muted,
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+const (
+	// defaultSuggestionsPerPage is the page size of friend suggestions when none is given.
+	defaultSuggestionsPerPage = 20
+
+	// maxSuggestionsPerPage is the maximum page size of friend suggestions.
+	maxSuggestionsPerPage = 50
+)
+
+// GetFriendSuggestions gets a page of people the user may know, ranked by
+// mutual friends.
+func (u *UserFriendShipHandler) GetFriendSuggestions(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	page, perPage := 1, defaultSuggestionsPerPage
+	if v := c.QueryParam("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page <= 0 {
+			return &echo.HTTPError{
+				Code:    echo.ErrBadRequest.Code,
+				Message: "page must be a positive number",
+			}
+		}
+	}
+	if v := c.QueryParam("per_page"); v != "" {
+		if perPage, err = strconv.Atoi(v); err != nil || perPage <= 0 {
+			return &echo.HTTPError{
+				Code:    echo.ErrBadRequest.Code,
+				Message: "per_page must be a positive number",
+			}
+		}
+		if perPage > maxSuggestionsPerPage {
+			perPage = maxSuggestionsPerPage
+		}
+	}
+
+	suggestions, err := u.friendStore.GetFriendSuggestions(ctx, userID, perPage, (page-1)*perPage)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	res := types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "friend suggestions fetched successfully",
+		Data: echo.Map{
+			"page":        page,
+			"per_page":    perPage,
+			"suggestions": suggestions,
+		},
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// hidePrivateFields blanks the fields the listed users don't show to the
+// authenticated user, depending on whether they're friends.
+func (u *UserFriendShipHandler) hidePrivateFields(ctx context.Context, friends []*types.Friend, isFriend bool) error {
+	uids := make([]uuid.UUID, 0, len(friends))
+	for _, friend := range friends {
+		uids = append(uids, friend.UID)
+	}
+
+	settings, err := u.privacyStore.GetMany(ctx, uids)
+	if err != nil {
+		return err
+	}
+
+	for _, friend := range friends {
+		if s, ok := settings[friend.UID]; ok && !s.BioVisibility.CanSee(isFriend) {
+			friend.Bio = ""
+		}
+	}
+	return nil
+}
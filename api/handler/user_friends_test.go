@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// userContext returns the context of a request of an authenticated user,
+// with the uid url parameter and a json body when they're not empty.
+func userContext(method string, userID uuid.UUID, param, body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	rec := httptest.NewRecorder()
+
+	c := echo.New().NewContext(req, rec)
+	c.Set("uid", userID.String())
+	if param != "" {
+		c.SetParamNames("uid")
+		c.SetParamValues(param)
+	}
+	return c, rec
+}
+
+// httpCode returns the status code of a handler error, or of the response
+// when the handler answered.
+func httpCode(err error, rec *httptest.ResponseRecorder) int {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	return rec.Code
+}
+
+// knownUserStore serves the users by uuid, the other methods panic.
+type knownUserStore struct {
+	interfaces.UserStore
+	users map[uuid.UUID]*types.User
+}
+
+func (s *knownUserStore) GetByID(ctx context.Context, id uuid.UUID) (*types.User, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return nil, interfaces.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// blockFriendStore keeps the blocks and friendships in memory, the other
+// methods panic.
+type blockFriendStore struct {
+	interfaces.FriendStore
+	blocks  map[[2]uuid.UUID]bool
+	friends map[[2]uuid.UUID]bool
+}
+
+func (s *blockFriendStore) BlockUser(ctx context.Context, userID, blockedID uuid.UUID) error {
+	if s.blocks[[2]uuid.UUID{userID, blockedID}] {
+		return interfaces.ErrAlreadyBlocked
+	}
+	s.blocks[[2]uuid.UUID{userID, blockedID}] = true
+	delete(s.friends, [2]uuid.UUID{userID, blockedID})
+	delete(s.friends, [2]uuid.UUID{blockedID, userID})
+	return nil
+}
+
+func (s *blockFriendStore) UnblockUser(ctx context.Context, userID, blockedID uuid.UUID) error {
+	if !s.blocks[[2]uuid.UUID{userID, blockedID}] {
+		return interfaces.ErrBlockNotFound
+	}
+	delete(s.blocks, [2]uuid.UUID{userID, blockedID})
+	return nil
+}
+
+func (s *blockFriendStore) AreFriends(ctx context.Context, userID, otherID uuid.UUID) (bool, error) {
+	return s.friends[[2]uuid.UUID{userID, otherID}] || s.friends[[2]uuid.UUID{otherID, userID}], nil
+}
+
+func TestBlockUser(t *testing.T) {
+	var (
+		user    = uuid.New()
+		friend  = uuid.New()
+		blocked = uuid.New()
+	)
+	users := &knownUserStore{users: map[uuid.UUID]*types.User{
+		user:    {UID: user},
+		friend:  {UID: friend},
+		blocked: {UID: blocked},
+	}}
+
+	tests := []struct {
+		name  string
+		param string
+		code  int
+	}{
+		{"friend", friend.String(), http.StatusOK},
+		{"already blocked", blocked.String(), http.StatusConflict},
+		{"self", user.String(), http.StatusBadRequest},
+		{"unknown user", uuid.NewString(), http.StatusNotFound},
+		{"invalid uid", "not-a-uuid", http.StatusBadRequest},
+		{"missing uid", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			friends := &blockFriendStore{
+				blocks:  map[[2]uuid.UUID]bool{{user, blocked}: true},
+				friends: map[[2]uuid.UUID]bool{{user, friend}: true},
+			}
+			h := NewUserFriendShipHandler(nil, users, friends, nil, nil, 0)
+
+			c, rec := userContext(http.MethodPost, user, tt.param, "")
+			if code := httpCode(h.BlockUser(c), rec); code != tt.code {
+				t.Fatalf("got status %d, want %d", code, tt.code)
+			}
+
+			if tt.code == http.StatusOK {
+				if !friends.blocks[[2]uuid.UUID{user, friend}] {
+					t.Error("the user isn't blocked")
+				}
+				if ok, _ := friends.AreFriends(context.Background(), user, friend); ok {
+					t.Error("the blocked user is still a friend")
+				}
+			}
+		})
+	}
+}
+
+func TestUnblockUser(t *testing.T) {
+	var (
+		user    = uuid.New()
+		blocked = uuid.New()
+		blocker = uuid.New()
+	)
+
+	tests := []struct {
+		name  string
+		param string
+		code  int
+	}{
+		{"blocked", blocked.String(), http.StatusOK},
+		{"not blocked", uuid.NewString(), http.StatusNotFound},
+		// A block by the other user can't be lifted by the blocked user.
+		{"blocked by the other", blocker.String(), http.StatusNotFound},
+		{"invalid uid", "not-a-uuid", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			friends := &blockFriendStore{blocks: map[[2]uuid.UUID]bool{
+				{user, blocked}: true,
+				{blocker, user}: true,
+			}}
+			h := NewUserFriendShipHandler(nil, nil, friends, nil, nil, 0)
+
+			c, rec := userContext(http.MethodDelete, user, tt.param, "")
+			if code := httpCode(h.UnblockUser(c), rec); code != tt.code {
+				t.Fatalf("got status %d, want %d", code, tt.code)
+			}
+			if !friends.blocks[[2]uuid.UUID{blocker, user}] {
+				t.Error("the block by the other user was lifted")
+			}
+		})
+	}
+}
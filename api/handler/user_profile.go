@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,6 +26,24 @@ type ProfileHandler struct {
 
 	// userStore is a data store for user.
 	userStore interfaces.UserStore
+
+	// friendStore is a data store for friend.
+	friendStore interfaces.FriendStore
+
+	// auditStore is the security audit log.
+	auditStore interfaces.AuditStore
+
+	// privacyStore is a data store for privacy settings.
+	privacyStore interfaces.PrivacyStore
+
+	// searchIndex is the search index of users.
+	searchIndex interfaces.SearchIndex
+
+	// highlightStore is a data store for highlights.
+	highlightStore interfaces.HighlightStore
+
+	// apiTokenStore is a data store for personal api tokens.
+	apiTokenStore interfaces.APITokenStore
 }
 
 // UserProfile is a user profile.
@@ -45,8 +66,15 @@ type UserProfile struct {
 	// Username is the username of the profile.
 	Username string `json:"username"`
 
-	// Email is the email of the profile.
-	Email string `json:"email"`
+	// Email is the email of the profile, empty when hidden.
+	Email string `json:"email,omitempty"`
+
+	// LastSeenAt is the last time the user was seen, nil when hidden.
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+
+	// Highlights are the highlights pinned to the profile, omitted when the
+	// statuses are hidden.
+	Highlights []*types.Highlight `json:"highlights,omitempty"`
 
 	// CreatedAt is the time the profile was created.
 	CreatedAt time.Time `json:"created_at"`
@@ -80,15 +108,35 @@ type UpdateProfile struct {
 	Avatar string `json:"avatar"`
 }
 
-func NewProfileHandler(validator *validator.Validate, profileStore interfaces.ProfileStore, userStore interfaces.UserStore) *ProfileHandler {
+var (
+	errFriendRequestNotAllowed = &echo.HTTPError{
+		Code:    http.StatusForbidden,
+		Message: "cannot send a friend request to this user",
+	}
+	friendRequestSent = types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "friend request sent successfully",
+	}
+)
+
+func NewProfileHandler(validator *validator.Validate, profileStore interfaces.ProfileStore, userStore interfaces.UserStore, friendStore interfaces.FriendStore, auditStore interfaces.AuditStore, privacyStore interfaces.PrivacyStore, searchIndex interfaces.SearchIndex, highlightStore interfaces.HighlightStore, apiTokenStore interfaces.APITokenStore) *ProfileHandler {
 	return &ProfileHandler{
-		validate:     validator,
-		profileStore: profileStore,
-		userStore:    userStore,
+		validate:       validator,
+		profileStore:   profileStore,
+		userStore:      userStore,
+		friendStore:    friendStore,
+		auditStore:     auditStore,
+		privacyStore:   privacyStore,
+		searchIndex:    searchIndex,
+		highlightStore: highlightStore,
+		apiTokenStore:  apiTokenStore,
 	}
 }
 
 func (h *ProfileHandler) AddFriend(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	uid := c.Param("uid")
 	// Parse the uuid.
 	id, err := uuid.Parse(uid)
@@ -127,9 +175,37 @@ func (h *ProfileHandler) AddFriend(c echo.Context) error {
 		}
 	}
 
+	// Neither side of a block can send a request to the other.
+	blocked, err := h.friendStore.IsBlocked(ctx, userUID, id)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+	if blocked {
+		return errFriendRequestNotAllowed
+	}
+
+	// The recipient decides who can send them requests.
+	allowed, err := h.canSendFriendRequest(ctx, userUID, id)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+	if !allowed {
+		return errFriendRequestNotAllowed
+	}
+
+	// A sender declined during the cooldown is told the request was sent, so
+	// they can't tell they were declined.
+	suppressed, err := h.friendStore.IsFriendRequestSuppressed(ctx, userUID, id)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+	if suppressed {
+		return c.JSON(http.StatusOK, friendRequestSent)
+	}
+
 	addFriend := make(chan echo.Map, 2)
 	go func(email string) {
-		user, err := h.userStore.GetByEmail(email)
+		user, err := h.userStore.GetByEmail(ctx, email)
 		if err != nil {
 			addFriend <- echo.Map{
 				"error": err,
@@ -143,7 +219,7 @@ func (h *ProfileHandler) AddFriend(c echo.Context) error {
 	}(email)
 
 	go func(id uuid.UUID) {
-		friend, err := h.profileStore.GetByUID(id)
+		friend, err := h.profileStore.GetByUID(ctx, id)
 		if err != nil {
 			addFriend <- echo.Map{
 				"error": err,
@@ -192,7 +268,7 @@ func (h *ProfileHandler) AddFriend(c echo.Context) error {
 		}
 	}
 
-	err = h.profileStore.CreateFriendship(user.UID.String(), friend.UID.String())
+	err = h.profileStore.CreateFriendship(ctx, user.UID.String(), friend.UID.String())
 	if err != nil {
 		if errors.Is(err, interfaces.ErrDuplicateFriendship) {
 			return &echo.HTTPError{
@@ -201,6 +277,10 @@ func (h *ProfileHandler) AddFriend(c echo.Context) error {
 			}
 		}
 
+		if errors.Is(err, interfaces.ErrBlockedFriendship) {
+			return errFriendRequestNotAllowed
+		}
+
 		if errors.Is(err, interfaces.ErrSelfFriendship) {
 			return &echo.HTTPError{
 				Code:    http.StatusBadRequest,
@@ -214,19 +294,17 @@ func (h *ProfileHandler) AddFriend(c echo.Context) error {
 				Message: "friend not found",
 			}
 		}
-		return sww
+		return sww.WithInternal(err)
 	}
 
-	return c.JSON(http.StatusOK, types.ApiResponse{
-		Status:  types.Success.String(),
-		Code:    http.StatusOK,
-		Message: "friend request sent successfully",
-	})
+	return c.JSON(http.StatusOK, friendRequestSent)
 
 }
 
 // GetProfileByID returns a profile by its uuid.
 func (h *ProfileHandler) GetProfileByID(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	uid := c.Param("uid")
 
 	// Parse the uuid.
@@ -238,7 +316,24 @@ func (h *ProfileHandler) GetProfileByID(c echo.Context) error {
 		}
 	}
 
-	profile, err := h.profileStore.GetByUID(id)
+	userUID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	// A blocked profile looks like a missing one.
+	blocked, err := h.friendStore.IsBlocked(ctx, userUID, id)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+	if blocked {
+		return &echo.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "profile not found",
+		}
+	}
+
+	profile, err := h.profileStore.GetByUID(ctx, id)
 	if err != nil {
 		if errors.Is(err, interfaces.ErrProfileNotFound) {
 			return &echo.HTTPError{
@@ -246,20 +341,45 @@ func (h *ProfileHandler) GetProfileByID(c echo.Context) error {
 				Message: "profile not found",
 			}
 		}
-		return sww
+		return sww.WithInternal(err)
+	}
+
+	isFriend, err := h.friendStore.AreFriends(ctx, userUID, id)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	settings, err := h.privacyStore.Get(ctx, id)
+	if err != nil {
+		return sww.WithInternal(err)
 	}
 
 	profileResponse := UserProfile{
 		UID:       profile.UID.String(),
 		FirstName: profile.FirstName,
 		LastName:  profile.LastName,
-		Bio:       profile.Bio,
 		Avatar:    profile.Avatar,
 		Username:  profile.Username,
-		Email:     profile.Email,
 		CreatedAt: profile.CreatedAt,
 	}
 
+	// Users always see their own profile in full.
+	owner := userUID == id
+	if owner || settings.EmailVisibility.CanSee(isFriend) {
+		profileResponse.Email = profile.Email
+	}
+	if owner || settings.BioVisibility.CanSee(isFriend) {
+		profileResponse.Bio = profile.Bio
+	}
+	if (owner || settings.LastSeenVisibility.CanSee(isFriend)) && profile.LastSeenAt.Valid {
+		profileResponse.LastSeenAt = &profile.LastSeenAt.Time
+	}
+	if owner || settings.StatusVisibility.CanSee(isFriend) {
+		if profileResponse.Highlights, err = h.highlightStore.GetByOwner(ctx, id, userUID); err != nil {
+			return sww.WithInternal(err)
+		}
+	}
+
 	return c.JSON(http.StatusOK, types.ApiResponse{
 		Status:  types.Success.String(),
 		Code:    http.StatusOK,
@@ -270,6 +390,8 @@ func (h *ProfileHandler) GetProfileByID(c echo.Context) error {
 
 // GetProfile gets the profile of the authenticated user.x
 func (h *ProfileHandler) GetProfile(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	email, ok := c.Get("user").(string)
 	if !ok {
 		return &echo.HTTPError{
@@ -278,7 +400,7 @@ func (h *ProfileHandler) GetProfile(c echo.Context) error {
 		}
 	}
 
-	profile, err := h.profileStore.GetByEmail(email)
+	profile, err := h.profileStore.GetByEmail(ctx, email)
 	if err != nil {
 		return &echo.HTTPError{
 			Code:    http.StatusNotFound,
@@ -307,6 +429,8 @@ func (h *ProfileHandler) GetProfile(c echo.Context) error {
 
 // CreateProfile creates a new profile.
 func (h *ProfileHandler) CreateProfile(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	profile := new(CreateProfile)
 	if err := utils.JSONDecode(c, profile); err != nil {
 		if strings.Contains(err.Error(), "json:") {
@@ -334,7 +458,7 @@ func (h *ProfileHandler) CreateProfile(c echo.Context) error {
 	}
 
 	// Get the user by its email.
-	user, err := h.userStore.GetByEmail(email)
+	user, err := h.userStore.GetByEmail(ctx, email)
 	if err != nil {
 		return &echo.HTTPError{
 			Code:    echo.ErrUnauthorized.Code,
@@ -351,7 +475,7 @@ func (h *ProfileHandler) CreateProfile(c echo.Context) error {
 		UserID:    user.ID,
 	}
 
-	res, err := h.profileStore.Create(profileData)
+	res, err := h.profileStore.Create(ctx, profileData)
 	if err != nil {
 		if err == interfaces.ErrProfileExists {
 			return &echo.HTTPError{
@@ -365,6 +489,8 @@ func (h *ProfileHandler) CreateProfile(c echo.Context) error {
 		}
 	}
 
+	h.reindex(c, user.UID)
+
 	profileResponse := UserProfile{
 		UID:       res.UID.String(),
 		FirstName: res.FirstName,
@@ -386,6 +512,8 @@ func (h *ProfileHandler) CreateProfile(c echo.Context) error {
 
 // UpdateProfile updates a profile.
 func (h *ProfileHandler) UpdateProfile(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	profile := new(UpdateProfile)
 	if err := utils.JSONDecode(c, profile); err != nil {
 		if strings.Contains(err.Error(), "json:") {
@@ -410,7 +538,7 @@ func (h *ProfileHandler) UpdateProfile(c echo.Context) error {
 	}
 
 	// Get the user by its email.
-	user, err := h.userStore.GetByEmail(email)
+	user, err := h.userStore.GetByEmail(ctx, email)
 	if err != nil {
 		return &echo.HTTPError{
 			Code:    echo.ErrUnauthorized.Code,
@@ -426,7 +554,7 @@ func (h *ProfileHandler) UpdateProfile(c echo.Context) error {
 		UserID:    user.ID,
 	}
 
-	res, err := h.profileStore.Update(profileData)
+	res, err := h.profileStore.Update(ctx, profileData)
 	if err != nil {
 		return &echo.HTTPError{
 			Code:    echo.ErrBadRequest.Code,
@@ -434,6 +562,8 @@ func (h *ProfileHandler) UpdateProfile(c echo.Context) error {
 		}
 	}
 
+	h.reindex(c, user.UID)
+
 	profileResponse := UserProfile{
 		UID:       res.UID.String(),
 		FirstName: res.FirstName,
@@ -453,8 +583,12 @@ func (h *ProfileHandler) UpdateProfile(c echo.Context) error {
 	})
 }
 
-// DeleteProfile deletes a profile by its uuid.
+// DeleteProfile deletes a profile by its uuid, and revokes the personal api
+// tokens of the user and of their bots. Reactivating the profile doesn't
+// restore them.
 func (h *ProfileHandler) DeleteProfile(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	email, ok := c.Get("user").(string)
 	if !ok {
 		return &echo.HTTPError{
@@ -464,7 +598,7 @@ func (h *ProfileHandler) DeleteProfile(c echo.Context) error {
 	}
 
 	// Get the user by its email.
-	user, err := h.userStore.GetByEmail(email)
+	user, err := h.userStore.GetByEmail(ctx, email)
 	if err != nil {
 		return &echo.HTTPError{
 			Code:    echo.ErrUnauthorized.Code,
@@ -472,7 +606,7 @@ func (h *ProfileHandler) DeleteProfile(c echo.Context) error {
 		}
 	}
 
-	err = h.profileStore.Delete(user.ID)
+	err = h.profileStore.Delete(ctx, user.ID)
 	if err != nil {
 		return &echo.HTTPError{
 			Code:    echo.ErrBadRequest.Code,
@@ -480,6 +614,16 @@ func (h *ProfileHandler) DeleteProfile(c echo.Context) error {
 		}
 	}
 
+	revoked, err := h.apiTokenStore.RevokeAll(ctx, user.UID)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	utils.RecordAudit(c, h.auditStore, types.AuditProfileDelete, user.UID, map[string]string{
+		"api_tokens_revoked": strconv.FormatInt(revoked, 10),
+	})
+	h.reindex(c, user.UID)
+
 	return c.JSON(http.StatusOK, types.ApiResponse{
 		Status:  types.Success.String(),
 		Code:    http.StatusOK,
@@ -489,6 +633,8 @@ func (h *ProfileHandler) DeleteProfile(c echo.Context) error {
 
 // ReactivateProfile reactivates a profile by its uuid.
 func (h *ProfileHandler) ReactivateProfile(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	email, ok := c.Get("user").(string)
 	if !ok {
 		return &echo.HTTPError{
@@ -498,7 +644,7 @@ func (h *ProfileHandler) ReactivateProfile(c echo.Context) error {
 	}
 
 	// Get the user by its email.
-	user, err := h.userStore.GetByEmail(email)
+	user, err := h.userStore.GetByEmail(ctx, email)
 	if err != nil {
 		return &echo.HTTPError{
 			Code:    echo.ErrUnauthorized.Code,
@@ -506,7 +652,7 @@ func (h *ProfileHandler) ReactivateProfile(c echo.Context) error {
 		}
 	}
 
-	err = h.profileStore.Reactivate(user.ID)
+	err = h.profileStore.Reactivate(ctx, user.ID)
 	if err != nil {
 		return &echo.HTTPError{
 			Code:    echo.ErrBadRequest.Code,
@@ -514,9 +660,39 @@ func (h *ProfileHandler) ReactivateProfile(c echo.Context) error {
 		}
 	}
 
+	utils.RecordAudit(c, h.auditStore, types.AuditProfileReactivate, user.UID, nil)
+	h.reindex(c, user.UID)
+
 	return c.JSON(http.StatusOK, types.ApiResponse{
 		Status:  types.Success.String(),
 		Code:    http.StatusOK,
 		Message: "profile reactivated successfully",
 	})
 }
+
+// canSendFriendRequest reports whether the recipient's privacy settings let
+// the sender send them a friend request.
+func (h *ProfileHandler) canSendFriendRequest(ctx context.Context, senderID, recipientID uuid.UUID) (bool, error) {
+	settings, err := h.privacyStore.Get(ctx, recipientID)
+	if err != nil {
+		return false, err
+	}
+
+	switch settings.FriendRequests {
+	case types.FriendRequestsNobody:
+		return false, nil
+	case types.FriendRequestsFriendsOfFriends:
+		return h.friendStore.HaveMutualFriend(ctx, senderID, recipientID)
+	}
+	return true, nil
+}
+
+// reindex refreshes the search document of a user. A stale document only
+// affects search results until the next rebuild, so failures are logged.
+func (h *ProfileHandler) reindex(c echo.Context, userID uuid.UUID) {
+	ctx := c.Request().Context()
+
+	if err := h.searchIndex.Reindex(ctx, userID); err != nil {
+		utils.LogError(c, "failed to reindex user", err, slog.String("reindexed_uid", userID.String()))
+	}
+}
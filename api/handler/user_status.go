@@ -1,7 +1,14 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/coderero/erochat-server/api/utils"
 	"github.com/coderero/erochat-server/interfaces"
@@ -11,6 +18,15 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// defaultArchiveLimit is the number of archived statuses returned when
+	// no limit is given.
+	defaultArchiveLimit = 20
+
+	// maxArchiveLimit is the maximum number of archived statuses returned at once.
+	maxArchiveLimit = 50
+)
+
 type UserStatusHandler struct {
 	// userStore is a data store for user.
 	userStore interfaces.UserStore
@@ -18,31 +34,88 @@ type UserStatusHandler struct {
 	// statusStore is a data store for status.
 	statusStore interfaces.StatusStore
 
+	// friendListStore is a data store for friend lists.
+	friendListStore interfaces.FriendListStore
+
+	// friendStore is a data store for friend.
+	friendStore interfaces.FriendStore
+
+	// linkPreviews fetches the previews of link items.
+	linkPreviews interfaces.LinkPreviewService
+
 	// validate is a validator that validates the request.
 	validate *validator.Validate
 }
 
 type CreateStatus struct {
-	// Resource URL.
-	ResourceURL string `json:"resource_url" validate:"required"`
+	// Resource URL of a single image status, used when there are no items.
+	ResourceURL string `json:"resource_url" validate:"max=255"`
+
+	// Resource Thumbnail of a single image status, used when there are no items.
+	ResourceThumbnail string `json:"resource_thumbnail" validate:"max=255"`
+
+	// Resource Title of a single image status, used when there are no items.
+	Title string `json:"title" validate:"max=255"`
+
+	// Items are the items of the status post, shown in order.
+	Items []CreateStatusItem `json:"items" validate:"max=10,dive"`
+
+	// Audience is who the status is shown to, everyone by default.
+	Audience types.StatusAudience `json:"audience" validate:"omitempty,oneof=everyone list except_list"`
+
+	// ListUID is the friend list of the list and except_list audiences.
+	ListUID string `json:"list_uid" validate:"omitempty,uuid"`
+}
+
+// CreateStatusItem is an item of a status post, the fields required depend
+// on the kind.
+type CreateStatusItem struct {
+	// Kind is the kind of the item.
+	Kind types.StatusItemKind `json:"kind" validate:"required,oneof=text image video link"`
+
+	// Text is the text of a text item, "@username" mentions a friend.
+	Text string `json:"text" validate:"max=700"`
+
+	// BackgroundColor is the background color of a text item, as #rrggbb.
+	BackgroundColor string `json:"background_color" validate:"omitempty,len=7,hexcolor"`
+
+	// Font is the font of a text item.
+	Font string `json:"font" validate:"omitempty,oneof=sans serif mono script"`
 
-	// Resource Thumbnail.
-	ResourceThumbnail string `json:"resource_thumbnail" validate:"required"`
+	// ResourceURL is the image or video of a media item.
+	ResourceURL string `json:"resource_url" validate:"omitempty,url,max=255"`
 
-	// Resource Title.
-	Title string `json:"title" validate:"required"`
+	// ResourceThumbnail is the thumbnail of a media item.
+	ResourceThumbnail string `json:"resource_thumbnail" validate:"omitempty,url,max=255"`
+
+	// DurationMS is the duration of a video item in milliseconds.
+	DurationMS int `json:"duration_ms" validate:"min=0,max=60000"`
+
+	// Caption is the caption of a media or link item, "@username" mentions a friend.
+	Caption string `json:"caption" validate:"max=500"`
+
+	// URL is the link of a link item.
+	URL string `json:"url" validate:"omitempty,url,max=2048"`
 }
 
+// mentionPattern matches the "@username" mentions of a text or caption.
+var mentionPattern = regexp.MustCompile(`@([A-Za-z0-9_.]+)`)
+
 // NewUserStatusHandler returns a new user status handler.
-func NewUserStatusHandler(validator *validator.Validate, userStore interfaces.UserStore, statusStore interfaces.StatusStore) *UserStatusHandler {
+func NewUserStatusHandler(validator *validator.Validate, userStore interfaces.UserStore, statusStore interfaces.StatusStore, friendListStore interfaces.FriendListStore, friendStore interfaces.FriendStore, linkPreviews interfaces.LinkPreviewService) *UserStatusHandler {
 	return &UserStatusHandler{
-		userStore:   userStore,
-		statusStore: statusStore,
-		validate:    validator,
+		userStore:       userStore,
+		statusStore:     statusStore,
+		friendListStore: friendListStore,
+		friendStore:     friendStore,
+		linkPreviews:    linkPreviews,
+		validate:        validator,
 	}
 }
 
 func (u *UserStatusHandler) GetStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	r_uid, ok := c.Get("uid").(string)
 	if !ok {
 		return &echo.HTTPError{
@@ -58,7 +131,7 @@ func (u *UserStatusHandler) GetStatus(c echo.Context) error {
 			Message: "something went wrong",
 		}
 	}
-	status, err := u.statusStore.GetStatus(uid)
+	status, err := u.statusStore.GetStatus(ctx, uid)
 	if err != nil {
 		return &echo.HTTPError{
 			Code:    echo.ErrBadRequest.Code,
@@ -75,6 +148,8 @@ func (u *UserStatusHandler) GetStatus(c echo.Context) error {
 }
 
 func (u *UserStatusHandler) CreateStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	r_uid, ok := c.Get("uid").(string)
 	if !ok {
 		return &echo.HTTPError{
@@ -106,14 +181,66 @@ func (u *UserStatusHandler) CreateStatus(c echo.Context) error {
 		})
 	}
 
+	items, itemErrs := statusItems(&status)
+	if len(itemErrs) > 0 {
+		return c.JSON(http.StatusBadRequest, types.ApiResponse{
+			Status:  types.Failure.String(),
+			Code:    http.StatusBadRequest,
+			Type:    types.ErrorTypeValidation.String(),
+			Message: "validation error",
+			Errors:  itemErrs,
+		})
+	}
+
 	newStatus := &types.UserStatus{
-		UserID:            uid,
-		ResourceURI:       status.ResourceURL,
-		ResourceThumbnail: status.ResourceThumbnail,
-		Title:             status.Title,
+		UserID:   uid,
+		Audience: status.Audience,
+	}
+
+	// List audiences must target one of the user's own lists.
+	if status.Audience == types.StatusAudienceList || status.Audience == types.StatusAudienceExceptList {
+		if status.ListUID == "" {
+			return c.JSON(http.StatusBadRequest, types.ApiResponse{
+				Status:  types.Failure.String(),
+				Code:    http.StatusBadRequest,
+				Type:    types.ErrorTypeValidation.String(),
+				Message: "validation error",
+				Errors: []types.Error{
+					{
+						Field:  "list_uid",
+						Reason: "list_uid is required for the list audiences",
+					},
+				},
+			})
+		}
+
+		listID := uuid.MustParse(status.ListUID)
+		if _, err := u.friendListStore.Get(ctx, uid, listID); err != nil {
+			if errors.Is(err, interfaces.ErrFriendListNotFound) {
+				return lnf
+			}
+			return sww.WithInternal(err)
+		}
+		newStatus.ListUID = uuid.NullUUID{UUID: listID, Valid: true}
+	}
+
+	for _, item := range items {
+		if item.Kind == types.StatusItemLink {
+			item.Link = u.fetchLinkPreview(ctx, item.Link.URL)
+		}
+
+		text := item.Caption
+		if item.Kind == types.StatusItemText {
+			text = item.Text
+		}
+		if item.Mentions, err = u.resolveMentions(ctx, uid, text); err != nil {
+			return sww.WithInternal(err)
+		}
 	}
+	newStatus.Items = items
+	summarizeStatus(newStatus)
 
-	s, err := u.statusStore.CreateStatus(newStatus)
+	s, err := u.statusStore.CreateStatus(ctx, newStatus)
 	if err != nil {
 		return &echo.HTTPError{
 			Code:    echo.ErrBadRequest.Code,
@@ -130,6 +257,8 @@ func (u *UserStatusHandler) CreateStatus(c echo.Context) error {
 }
 
 func (u *UserStatusHandler) DeleteStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	statusID := c.Param("uid")
 	if statusID == "" {
 		return &echo.HTTPError{
@@ -162,9 +291,9 @@ func (u *UserStatusHandler) DeleteStatus(c echo.Context) error {
 		}
 	}
 
-	err = u.statusStore.DeleteStatus(user_uid, uid)
+	err = u.statusStore.DeleteStatus(ctx, user_uid, uid)
 	if err != nil {
-		if err.Error() == "status not found" {
+		if errors.Is(err, interfaces.ErrStatusNotFound) {
 			return &echo.HTTPError{
 				Code:    echo.ErrNotFound.Code,
 				Message: "status not found",
@@ -183,3 +312,236 @@ func (u *UserStatusHandler) DeleteStatus(c echo.Context) error {
 	})
 
 }
+
+// GetStatusViewers gets the friends who viewed a status of the authenticated user.
+func (u *UserStatusHandler) GetStatusViewers(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	statusID, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    echo.ErrBadRequest.Code,
+			Message: "invalid status id",
+		}
+	}
+
+	viewers, err := u.statusStore.GetViewers(ctx, userID, statusID)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrStatusNotFound) {
+			return &echo.HTTPError{
+				Code:    echo.ErrNotFound.Code,
+				Message: "status not found",
+			}
+		}
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "status viewers fetched successfully",
+		Data: echo.Map{
+			"view_count": len(viewers),
+			"viewers":    viewers,
+		},
+	})
+}
+
+// GetStatusArchive gets the expired statuses of the authenticated user, most
+// recent first. The archive is private to its owner.
+func (u *UserStatusHandler) GetStatusArchive(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserUID(c)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	limit := defaultArchiveLimit
+	if l := c.QueryParam("limit"); l != "" {
+		if limit, err = strconv.Atoi(l); err != nil || limit <= 0 {
+			return &echo.HTTPError{
+				Code:    echo.ErrBadRequest.Code,
+				Message: "limit must be a positive number",
+			}
+		}
+		limit = min(limit, maxArchiveLimit)
+	}
+
+	before := time.Now().UTC()
+	if b := c.QueryParam("before"); b != "" {
+		if before, err = time.Parse(time.RFC3339Nano, b); err != nil {
+			return &echo.HTTPError{
+				Code:    echo.ErrBadRequest.Code,
+				Message: "before must be an rfc3339 timestamp",
+			}
+		}
+	}
+
+	statuses, err := u.statusStore.GetArchive(ctx, userID, before, limit)
+	if err != nil {
+		return sww.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, types.ApiResponse{
+		Status:  types.Success.String(),
+		Code:    http.StatusOK,
+		Message: "status archive fetched successfully",
+		Data:    statuses,
+	})
+}
+
+// statusItems builds the items of a status post. A post without items is a
+// single image item built from the resource fields.
+func statusItems(params *CreateStatus) ([]*types.StatusItem, []types.Error) {
+	var errs []types.Error
+	required := func(field, value string) {
+		if strings.TrimSpace(value) == "" {
+			errs = append(errs, types.Error{
+				Field:  field,
+				Reason: "field is required",
+			})
+		}
+	}
+
+	if len(params.Items) == 0 {
+		required("resource_url", params.ResourceURL)
+		required("resource_thumbnail", params.ResourceThumbnail)
+		required("title", params.Title)
+		return []*types.StatusItem{
+			{
+				Kind:              types.StatusItemImage,
+				ResourceURI:       params.ResourceURL,
+				ResourceThumbnail: params.ResourceThumbnail,
+				Caption:           params.Title,
+			},
+		}, errs
+	}
+
+	items := make([]*types.StatusItem, 0, len(params.Items))
+	for i, p := range params.Items {
+		field := fmt.Sprintf("items[%d].", i)
+		item := &types.StatusItem{
+			Position: i,
+			Kind:     p.Kind,
+			Caption:  p.Caption,
+		}
+
+		switch p.Kind {
+		case types.StatusItemText:
+			required(field+"text", p.Text)
+			item.Caption = ""
+			item.Text = p.Text
+			item.BackgroundColor = strings.ToLower(p.BackgroundColor)
+			item.Font = p.Font
+		case types.StatusItemImage, types.StatusItemVideo:
+			required(field+"resource_url", p.ResourceURL)
+			required(field+"resource_thumbnail", p.ResourceThumbnail)
+			item.ResourceURI = p.ResourceURL
+			item.ResourceThumbnail = p.ResourceThumbnail
+			if p.Kind == types.StatusItemVideo {
+				if p.DurationMS <= 0 {
+					errs = append(errs, types.Error{
+						Field:  field + "duration_ms",
+						Reason: "field is required",
+					})
+				}
+				item.DurationMS = p.DurationMS
+			}
+		case types.StatusItemLink:
+			required(field+"url", p.URL)
+			item.Link = &types.LinkPreview{URL: p.URL}
+		}
+		items = append(items, item)
+	}
+	return items, errs
+}
+
+// summarizeStatus fills the title and resource of a status from its first
+// item, for the clients that don't render the items.
+func summarizeStatus(status *types.UserStatus) {
+	if len(status.Items) == 0 {
+		return
+	}
+
+	first := status.Items[0]
+	switch first.Kind {
+	case types.StatusItemText:
+		status.Title = first.Text
+	case types.StatusItemLink:
+		status.Title = first.Caption
+		if status.Title == "" {
+			status.Title = first.Link.Title
+		}
+		status.ResourceURI = first.Link.URL
+		status.ResourceThumbnail = first.Link.Image
+	default:
+		status.Title = first.Caption
+		status.ResourceURI = first.ResourceURI
+		status.ResourceThumbnail = first.ResourceThumbnail
+	}
+
+	if title := []rune(status.Title); len(title) > 255 {
+		status.Title = string(title[:255])
+	}
+	if len(status.ResourceURI) > 255 {
+		status.ResourceURI = ""
+	}
+	if len(status.ResourceThumbnail) > 255 {
+		status.ResourceThumbnail = ""
+	}
+}
+
+// fetchLinkPreview fetches the preview of a link, a link that can't be
+// previewed is posted with its url only.
+func (u *UserStatusHandler) fetchLinkPreview(ctx context.Context, rawURL string) *types.LinkPreview {
+	preview, err := u.linkPreviews.Fetch(ctx, rawURL)
+	if err != nil {
+		return &types.LinkPreview{URL: rawURL}
+	}
+	return preview
+}
+
+// resolveMentions returns the friends of the user mentioned in a text,
+// mentions of other users are left as plain text.
+func (u *UserStatusHandler) resolveMentions(ctx context.Context, userID uuid.UUID, text string) ([]*types.StatusMention, error) {
+	var (
+		mentions []*types.StatusMention
+		seen     = map[string]bool{}
+	)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := strings.TrimRight(match[1], ".")
+		if username == "" || seen[strings.ToLower(username)] {
+			continue
+		}
+		seen[strings.ToLower(username)] = true
+
+		user, err := u.userStore.GetByUsername(ctx, username)
+		if err != nil {
+			if errors.Is(err, interfaces.ErrUserNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if user.DeletedAt.Valid || user.UID == userID {
+			continue
+		}
+
+		friends, err := u.friendStore.AreFriends(ctx, userID, user.UID)
+		if err != nil {
+			return nil, err
+		}
+		if friends {
+			mentions = append(mentions, &types.StatusMention{
+				UID:      user.UID,
+				Username: user.Username,
+			})
+		}
+	}
+	return mentions, nil
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/coderero/erochat-server/api/utils"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ErrAdminRequired is returned when a non admin user requests an admin route.
+var ErrAdminRequired = &echo.HTTPError{
+	Code:    http.StatusForbidden,
+	Message: "admin privileges required",
+}
+
+type AdminMiddlewareConfig struct {
+	// UserStore is the user store.
+	UserStore interfaces.UserStore
+
+	// AuditStore is the security audit log.
+	AuditStore interfaces.AuditStore
+}
+
+// AdminMiddleware is a middleware that only lets administrators through.
+//
+// It must run after JWTMiddleware. Every admin request is recorded in the
+// audit log, and the admin is set as the actor of the events recorded by the
+// handler.
+func AdminMiddleware(config AdminMiddlewareConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+
+			uid, ok := c.Get("uid").(string)
+			if !ok {
+				return echo.ErrUnauthorized
+			}
+
+			adminUID, err := uuid.Parse(uid)
+			if err != nil {
+				return echo.ErrUnauthorized
+			}
+
+			user, err := config.UserStore.GetByID(ctx, adminUID)
+			if err != nil || !user.IsAdmin() || user.DeletedAt.Valid {
+				return ErrAdminRequired
+			}
+
+			c.Set("audit_actor", adminUID)
+			utils.RecordAudit(c, config.AuditStore, types.AuditAdminAction, adminUID, map[string]string{
+				"method": c.Request().Method,
+				"route":  c.Path(),
+				"query":  c.QueryString(),
+			})
+
+			return next(c)
+		}
+	}
+}
@@ -1,17 +1,55 @@
 package middleware
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/coderero/erochat-server/api/service"
 	"github.com/coderero/erochat-server/api/utils"
 	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// AuthMethodBearer is set in the context when the request was authenticated
+	// with the Authorization header.
+	AuthMethodBearer = "bearer"
+
+	// AuthMethodCookie is set in the context when the request was authenticated
+	// with the token cookies.
+	AuthMethodCookie = "cookie"
+
+	// AuthMethodAPIToken is set in the context when the request was authenticated
+	// with a personal api token.
+	AuthMethodAPIToken = "api_token"
+)
+
+type JWTMiddlewareConfig struct {
+	// TokenService is the token service.
+	TokenService interfaces.TokenService
+
+	// APITokenStore is the personal api token store.
+	APITokenStore interfaces.APITokenStore
+
+	// UserStore is the user store.
+	UserStore interfaces.UserStore
+
+	// AuditStore is the security audit log.
+	AuditStore interfaces.AuditStore
+}
+
 // JWTMiddleware is a middleware that checks if the user is authenticated.
-func JWTMiddleware(jwt interfaces.TokenService) echo.MiddlewareFunc {
+//
+// The Authorization header accepts either a JWT or a personal api token, the
+// cookies only accept JWTs. Bot accounts can only use personal api tokens.
+func JWTMiddleware(config JWTMiddlewareConfig) echo.MiddlewareFunc {
+	jwt := config.TokenService
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+
 			// Get the token from the request.
 			rToken := c.Request().Header.Get("Authorization")
 
@@ -20,8 +58,17 @@ func JWTMiddleware(jwt interfaces.TokenService) echo.MiddlewareFunc {
 				if len(bearer) != 2 || bearer[0] != "Bearer" || len(bearer[1]) == 0 {
 					return echo.ErrUnauthorized
 				}
+
+				// Personal api tokens are looked up instead of verified.
+				if service.IsAPIToken(bearer[1]) {
+					if err := setAPITokenToContext(c, config, bearer[1]); err != nil {
+						return err
+					}
+					return next(c)
+				}
+
 				// Validate the token.
-				valid, err := jwt.ValidateToken(bearer[1])
+				valid, err := jwt.ValidateToken(ctx, bearer[1])
 				if err != nil || !valid {
 					return echo.ErrUnauthorized
 				}
@@ -34,6 +81,7 @@ func JWTMiddleware(jwt interfaces.TokenService) echo.MiddlewareFunc {
 					if err != nil {
 						return err
 					}
+					c.Set("auth", AuthMethodBearer)
 					return next(c)
 				}
 			} else {
@@ -44,34 +92,38 @@ func JWTMiddleware(jwt interfaces.TokenService) echo.MiddlewareFunc {
 				)
 
 				// Get the access token from the cookie.
-				accessToken = utils.GetCookie(c, "__a")
-				refreshToken = utils.GetCookie(c, "__r")
+				accessToken = utils.GetCookie(c, utils.AccessTokenCookie)
+				refreshToken = utils.GetCookie(c, utils.RefreshTokenCookie)
 
 				if accessToken == "" && refreshToken == "" {
 					return echo.ErrUnauthorized
 				}
 
-				accessValid, aErr := jwt.ValidateToken(accessToken)
+				accessValid, aErr := jwt.ValidateToken(ctx, accessToken)
 
 				if aErr != nil || !accessValid {
-					refreshValid, rErr := jwt.ValidateToken(refreshToken)
+					refreshValid, rErr := jwt.ValidateToken(ctx, refreshToken)
 					if rErr != nil || !refreshValid {
 						return echo.ErrUnauthorized
 					}
 
 					if refreshValid {
-						token, rErr := jwt.RefreshToken(refreshToken)
+						token, rErr := jwt.RefreshToken(ctx, refreshToken)
 						if rErr != nil {
 							return echo.ErrUnauthorized
 						}
 						// Set the token in the cookie.
-						utils.SaveCookie(c, "__a", token)
+						utils.SaveCookie(c, utils.AccessTokenCookie, token)
 
 						// Set the user email in the context.
 						err := GetAndSetToContext(c, jwt, token)
 						if err != nil {
 							return err
 						}
+						c.Set("auth", AuthMethodCookie)
+
+						uid, _ := uuid.Parse(fmt.Sprint(c.Get("uid")))
+						utils.RecordAudit(c, config.AuditStore, types.AuditTokenRefresh, uid, nil)
 						return next(c)
 					}
 					return echo.ErrUnauthorized
@@ -83,6 +135,7 @@ func JWTMiddleware(jwt interfaces.TokenService) echo.MiddlewareFunc {
 					if err != nil {
 						return err
 					}
+					c.Set("auth", AuthMethodCookie)
 					return next(c)
 				}
 			}
@@ -92,8 +145,10 @@ func JWTMiddleware(jwt interfaces.TokenService) echo.MiddlewareFunc {
 }
 
 func GetAndSetToContext(c echo.Context, jwt interfaces.TokenService, token string) error {
+	ctx := c.Request().Context()
+
 	// Get the claims from the token.
-	claims, err := jwt.GetClaims(token)
+	claims, err := jwt.GetClaims(ctx, token)
 	if err != nil {
 		return echo.ErrUnauthorized
 	}
@@ -103,3 +158,40 @@ func GetAndSetToContext(c echo.Context, jwt interfaces.TokenService, token strin
 
 	return nil
 }
+
+// setAPITokenToContext authenticates a personal api token and sets its owner in the context.
+func setAPITokenToContext(c echo.Context, config JWTMiddlewareConfig, token string) error {
+	ctx := c.Request().Context()
+
+	if config.APITokenStore == nil || config.UserStore == nil {
+		return echo.ErrUnauthorized
+	}
+
+	apiToken, err := config.APITokenStore.GetByHash(ctx, service.HashAPIToken(token))
+	if err != nil {
+		return echo.ErrUnauthorized
+	}
+
+	user, err := config.UserStore.GetByID(ctx, apiToken.UserUID)
+	if err != nil || user.DeletedAt.Valid {
+		return echo.ErrUnauthorized
+	}
+
+	// A bot acts for its owner, it can't outlive the owner's account.
+	if user.IsBot() {
+		owner, err := config.UserStore.GetByID(ctx, user.OwnerUID.UUID)
+		if err != nil || owner.DeletedAt.Valid {
+			return echo.ErrUnauthorized
+		}
+	}
+
+	// The last use is informational, a failure must not reject the request.
+	_ = config.APITokenStore.Touch(ctx, apiToken.UID)
+
+	c.Set("user", user.Email)
+	c.Set("uid", user.UID.String())
+	c.Set("auth", AuthMethodAPIToken)
+	c.Set("api_token", apiToken)
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coderero/erochat-server/api/service"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// fakeAPITokenStore serves the tokens by hash, the other methods panic.
+type fakeAPITokenStore struct {
+	interfaces.APITokenStore
+	tokens map[string]*types.APIToken
+}
+
+func (s *fakeAPITokenStore) GetByHash(ctx context.Context, hash string) (*types.APIToken, error) {
+	token, ok := s.tokens[hash]
+	if !ok {
+		return nil, interfaces.ErrAPITokenNotFound
+	}
+	return token, nil
+}
+
+func (s *fakeAPITokenStore) Touch(ctx context.Context, uid uuid.UUID) error {
+	return nil
+}
+
+// fakeUserStore serves the users by uuid, the other methods panic.
+type fakeUserStore struct {
+	interfaces.UserStore
+	users map[uuid.UUID]*types.User
+}
+
+func (s *fakeUserStore) GetByID(ctx context.Context, id uuid.UUID) (*types.User, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return nil, interfaces.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func TestJWTMiddlewareAPIToken(t *testing.T) {
+	deleted := sql.NullTime{Time: time.Now(), Valid: true}
+	var (
+		owner        = &types.User{UID: uuid.New(), Email: "owner@example.com", AccountType: types.AccountTypeUser}
+		deletedOwner = &types.User{UID: uuid.New(), AccountType: types.AccountTypeUser, DeletedAt: deleted}
+		deletedUser  = &types.User{UID: uuid.New(), AccountType: types.AccountTypeUser, DeletedAt: deleted}
+		bot          = &types.User{UID: uuid.New(), Email: "bot@bot.erochat.invalid", AccountType: types.AccountTypeBot, OwnerUID: uuid.NullUUID{UUID: owner.UID, Valid: true}}
+		orphanBot    = &types.User{UID: uuid.New(), AccountType: types.AccountTypeBot, OwnerUID: uuid.NullUUID{UUID: deletedOwner.UID, Valid: true}}
+		missingBot   = &types.User{UID: uuid.New(), AccountType: types.AccountTypeBot, OwnerUID: uuid.NullUUID{UUID: uuid.New(), Valid: true}}
+	)
+	users := &fakeUserStore{users: map[uuid.UUID]*types.User{}}
+	tokens := &fakeAPITokenStore{tokens: map[string]*types.APIToken{}}
+	tokenOf := map[*types.User]string{}
+	for _, user := range []*types.User{owner, deletedOwner, deletedUser, bot, orphanBot, missingBot} {
+		users.users[user.UID] = user
+
+		token, _, hash, err := service.GenerateAPIToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens.tokens[hash] = &types.APIToken{UID: uuid.New(), UserUID: user.UID}
+		tokenOf[user] = token
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  *types.User
+	}{
+		{"user", tokenOf[owner], owner},
+		{"bot", tokenOf[bot], bot},
+		{"deleted user", tokenOf[deletedUser], nil},
+		{"bot of a deleted owner", tokenOf[orphanBot], nil},
+		{"bot of a missing owner", tokenOf[missingBot], nil},
+		{"unknown token", service.APITokenPrefix + "unknown", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			called := false
+			err := JWTMiddleware(JWTMiddlewareConfig{APITokenStore: tokens, UserStore: users})(func(c echo.Context) error {
+				called = true
+				return nil
+			})(c)
+
+			if tt.want == nil {
+				if err != echo.ErrUnauthorized || called {
+					t.Errorf("got error %v and next called %v, want unauthorized", err, called)
+				}
+				return
+			}
+			if err != nil || !called {
+				t.Fatalf("got error %v and next called %v, want authorized", err, called)
+			}
+			if c.Get("uid") != tt.want.UID.String() || c.Get("auth") != AuthMethodAPIToken {
+				t.Errorf("got uid %v and auth %v, want %s authenticated by api token", c.Get("uid"), c.Get("auth"), tt.want.UID)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coderero/erochat-server/api/utils"
+	"github.com/labstack/echo/v4"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	const token = "0a1b2c3d4e5f"
+
+	tests := []struct {
+		name   string
+		auth   string
+		method string
+		cookie string
+		header string
+		err    error
+		issued bool
+	}{
+		{name: "bearer unsafe without token", auth: AuthMethodBearer, method: http.MethodPost},
+		{name: "api token unsafe without token", auth: AuthMethodAPIToken, method: http.MethodDelete},
+		{name: "unauthenticated unsafe without token", method: http.MethodPost},
+		{name: "cookie safe issues the token", auth: AuthMethodCookie, method: http.MethodGet, issued: true},
+		{name: "cookie safe keeps the token", auth: AuthMethodCookie, method: http.MethodHead, cookie: token},
+		{name: "cookie unsafe with matching token", auth: AuthMethodCookie, method: http.MethodPost, cookie: token, header: token},
+		{name: "cookie unsafe without header", auth: AuthMethodCookie, method: http.MethodPut, cookie: token, err: ErrCSRFTokenInvalid},
+		{name: "cookie unsafe without cookie", auth: AuthMethodCookie, method: http.MethodPatch, header: token, err: ErrCSRFTokenInvalid},
+		{name: "cookie unsafe without both", auth: AuthMethodCookie, method: http.MethodDelete, err: ErrCSRFTokenInvalid},
+		{name: "cookie unsafe with other token", auth: AuthMethodCookie, method: http.MethodPost, cookie: token, header: token + "0", err: ErrCSRFTokenInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: utils.CSRFCookie, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set(utils.CSRFHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+			if tt.auth != "" {
+				c.Set("auth", tt.auth)
+			}
+
+			called := false
+			err := CSRFMiddleware()(func(c echo.Context) error {
+				called = true
+				return nil
+			})(c)
+
+			if err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if called != (tt.err == nil) {
+				t.Errorf("next called: %v, want %v", called, tt.err == nil)
+			}
+
+			issued := strings.HasPrefix(rec.Header().Get(echo.HeaderSetCookie), utils.CSRFCookie+"=")
+			if issued != tt.issued {
+				t.Errorf("token issued: %v, want %v", issued, tt.issued)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/coderero/erochat-server/api/utils"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// LastSeenMiddleware records the last time the authenticated user made a
+// request. It must run after JWTMiddleware.
+func LastSeenMiddleware(userStore interfaces.UserStore) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+
+			uid, ok := c.Get("uid").(string)
+			if !ok {
+				return next(c)
+			}
+
+			userID, err := uuid.Parse(uid)
+			if err != nil {
+				return next(c)
+			}
+
+			// Last seen is informational, a failure must not reject the request.
+			if err := userStore.TouchLastSeen(ctx, userID); err != nil {
+				utils.LogError(c, "failed to record last seen", err)
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/coderero/erochat-server/api/utils"
+	"github.com/coderero/erochat-server/tracing"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// maxRequestIDLength bounds the request ids accepted from the clients.
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware assigns an id to every request and stores a logger
+// carrying it on the context.
+//
+// The id is taken from the X-Request-ID header when a proxy set a valid one,
+// and is echoed in the response header so clients can report it. The trace
+// id is logged too when the request is traced.
+func RequestIDMiddleware(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(echo.HeaderXRequestID)
+			if !validRequestID(id) {
+				id = uuid.NewString()
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+
+			attrs := []any{slog.String("request_id", id)}
+			if traceID := tracing.TraceID(c.Request().Context()); traceID != "" {
+				attrs = append(attrs, slog.String("trace_id", traceID))
+			}
+			utils.SetLogger(c, logger.With(attrs...))
+			return next(c)
+		}
+	}
+}
+
+// RequestLoggerMiddleware logs every request once it's served, it must run
+// after RequestIDMiddleware.
+func RequestLoggerMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			started := time.Now()
+
+			err := next(c)
+			if err != nil {
+				// Let the error handler write the response so its status is logged.
+				c.Error(err)
+			}
+
+			attrs := []any{
+				slog.String("method", c.Request().Method),
+				slog.String("route", c.Path()),
+				slog.String("uri", c.Request().RequestURI),
+				slog.Int("status", c.Response().Status),
+				slog.Duration("latency", time.Since(started)),
+				slog.String("ip", c.RealIP()),
+				slog.Int64("bytes_out", c.Response().Size),
+			}
+			if uid, ok := c.Get("uid").(string); ok {
+				attrs = append(attrs, slog.String("user_uid", uid))
+			}
+
+			level := slog.LevelInfo
+			if c.Response().Status >= 500 {
+				level = slog.LevelError
+			}
+			utils.Logger(c).Log(c.Request().Context(), level, "request", attrs...)
+			return nil
+		}
+	}
+}
+
+// validRequestID reports whether a client request id can be reused.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/coderero/erochat-server/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+// MetricsMiddleware records the count and latency of the requests per route
+// and status.
+//
+// Requests matching no route are recorded under an empty route, so scans of
+// random paths don't create new series.
+func MetricsMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			started := time.Now()
+			err := next(c)
+
+			// Errors are written by the error handler after the middleware
+			// chain, their status comes from the error.
+			status := c.Response().Status
+			if err != nil {
+				status = http.StatusInternalServerError
+				var he *echo.HTTPError
+				if errors.As(err, &he) {
+					status = he.Code
+				}
+			}
+
+			metrics.ObserveRequest(c.Request().Method, c.Path(), status, time.Since(started))
+			return err
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/coderero/erochat-server/types"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// ErrInsufficientScope is returned when the api token lacks the scope of the route.
+	ErrInsufficientScope = &echo.HTTPError{
+		Code:    http.StatusForbidden,
+		Message: "api token is missing the required scope",
+	}
+
+	// ErrSessionRequired is returned when a route can't be used with an api token.
+	ErrSessionRequired = &echo.HTTPError{
+		Code:    http.StatusForbidden,
+		Message: "this route requires a user session",
+	}
+)
+
+// RequireScope is a middleware that enforces the scope of a route group for
+// requests authenticated with a personal api token.
+//
+// Safe methods require the "<resource>:read" scope, every other method
+// requires "<resource>:write". Session requests are not restricted.
+func RequireScope(resource string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Get("auth") != AuthMethodAPIToken {
+				return next(c)
+			}
+
+			token, ok := c.Get("api_token").(*types.APIToken)
+			if !ok {
+				return echo.ErrUnauthorized
+			}
+
+			scope := resource + ":write"
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				scope = resource + ":read"
+			}
+
+			if !token.HasScope(scope) {
+				return ErrInsufficientScope
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireSession is a middleware that rejects requests authenticated with a
+// personal api token.
+func RequireSession() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Get("auth") == AuthMethodAPIToken {
+				return ErrSessionRequired
+			}
+			return next(c)
+		}
+	}
+}
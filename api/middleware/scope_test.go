@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coderero/erochat-server/types"
+	"github.com/labstack/echo/v4"
+)
+
+func TestRequireScope(t *testing.T) {
+	token := &types.APIToken{Scopes: []string{"status:read", "profile:write"}}
+
+	tests := []struct {
+		name     string
+		auth     string
+		token    any
+		resource string
+		method   string
+		err      error
+	}{
+		{name: "session", auth: AuthMethodCookie, resource: "friends", method: http.MethodPost},
+		{name: "bearer", auth: AuthMethodBearer, resource: "friends", method: http.MethodDelete},
+		{name: "read scope", auth: AuthMethodAPIToken, token: token, resource: "status", method: http.MethodGet},
+		{name: "read scope on head", auth: AuthMethodAPIToken, token: token, resource: "status", method: http.MethodHead},
+		{name: "read scope on options", auth: AuthMethodAPIToken, token: token, resource: "status", method: http.MethodOptions},
+		{name: "read scope doesn't write", auth: AuthMethodAPIToken, token: token, resource: "status", method: http.MethodPost, err: ErrInsufficientScope},
+		{name: "write scope", auth: AuthMethodAPIToken, token: token, resource: "profile", method: http.MethodPatch},
+		{name: "write scope doesn't read", auth: AuthMethodAPIToken, token: token, resource: "profile", method: http.MethodGet, err: ErrInsufficientScope},
+		{name: "other resource", auth: AuthMethodAPIToken, token: token, resource: "friends", method: http.MethodGet, err: ErrInsufficientScope},
+		{name: "token missing", auth: AuthMethodAPIToken, resource: "status", method: http.MethodGet, err: echo.ErrUnauthorized},
+		{name: "token of another type", auth: AuthMethodAPIToken, token: "status:read", resource: "status", method: http.MethodGet, err: echo.ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := echo.New().NewContext(httptest.NewRequest(tt.method, "/", nil), httptest.NewRecorder())
+			c.Set("auth", tt.auth)
+			if tt.token != nil {
+				c.Set("api_token", tt.token)
+			}
+
+			called := false
+			err := RequireScope(tt.resource)(func(c echo.Context) error {
+				called = true
+				return nil
+			})(c)
+
+			if err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if called != (tt.err == nil) {
+				t.Errorf("next called: %v, want %v", called, tt.err == nil)
+			}
+		})
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	for auth, want := range map[string]error{
+		AuthMethodCookie:   nil,
+		AuthMethodBearer:   nil,
+		AuthMethodAPIToken: ErrSessionRequired,
+	} {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		c.Set("auth", auth)
+
+		if err := RequireSession()(func(c echo.Context) error { return nil })(c); err != want {
+			t.Errorf("%s: got %v, want %v", auth, err, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/coderero/erochat-server/api/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// TimeoutConfig is the configuration of the request deadlines.
+type TimeoutConfig struct {
+	// Default is the deadline of the requests matching no route prefix.
+	Default time.Duration
+
+	// Routes are the deadlines of the route groups by route prefix, the
+	// longest matching prefix applies.
+	Routes map[string]time.Duration
+}
+
+// TimeoutMiddleware sets the deadline of every request on its context, so
+// the database calls of a request are canceled when it runs out of time or
+// when the client goes away.
+//
+// A handler failing once its context is done gets a 504 when the deadline
+// passed, or a 499 when the client went away, whatever error it returned.
+func TimeoutMiddleware(config TimeoutConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx, cancel := context.WithTimeout(req.Context(), config.timeout(c.Path()))
+			defer cancel()
+
+			c.SetRequest(req.WithContext(ctx))
+			defer c.SetRequest(req)
+
+			err := next(c)
+			if err != nil && ctx.Err() != nil {
+				return utils.ContextError(ctx.Err(), err)
+			}
+			return err
+		}
+	}
+}
+
+// timeout returns the deadline of a route.
+func (config TimeoutConfig) timeout(route string) time.Duration {
+	var (
+		timeout = config.Default
+		longest = -1
+	)
+	for prefix, d := range config.Routes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if len(prefix) <= longest {
+			continue
+		}
+		if route == prefix || strings.HasPrefix(route, prefix+"/") {
+			timeout, longest = d, len(prefix)
+		}
+	}
+	return timeout
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coderero/erochat-server/api/utils"
+	"github.com/labstack/echo/v4"
+)
+
+func TestTimeoutConfigTimeout(t *testing.T) {
+	config := TimeoutConfig{
+		Default: time.Second * 10,
+		Routes: map[string]time.Duration{
+			"/api/v1/admin":       time.Minute * 2,
+			"/api/v1/admin/jobs/": time.Minute * 5,
+			"/api/v1/media":       time.Second * 30,
+		},
+	}
+
+	tests := []struct {
+		route string
+		want  time.Duration
+	}{
+		{"/api/v1/friends", time.Second * 10},
+		{"/api/v1/admin", time.Minute * 2},
+		{"/api/v1/admin/audit", time.Minute * 2},
+		{"/api/v1/admin/jobs", time.Minute * 5},
+		{"/api/v1/admin/jobs/:name", time.Minute * 5},
+		{"/api/v1/admin/jobsearch", time.Minute * 2},
+		{"/api/v1/administrators", time.Second * 10},
+		{"/api/v1/media/:uid", time.Second * 30},
+		{"/api/v1", time.Second * 10},
+		{"", time.Second * 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			if got := config.timeout(tt.route); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeoutConfigRootPrefix(t *testing.T) {
+	config := TimeoutConfig{
+		Default: time.Second * 10,
+		Routes:  map[string]time.Duration{"/": time.Second * 20, "/api/v1/admin": time.Minute},
+	}
+
+	for route, want := range map[string]time.Duration{
+		"/api/v1/friends": time.Second * 20,
+		"/api/v1/admin":   time.Minute,
+	} {
+		if got := config.timeout(route); got != want {
+			t.Errorf("%s: got %v, want %v", route, got, want)
+		}
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	errFailed := errors.New("query failed")
+
+	tests := []struct {
+		name    string
+		handler echo.HandlerFunc
+		code    int
+		err     error
+	}{
+		{
+			name:    "in time",
+			handler: func(c echo.Context) error { return nil },
+		},
+		{
+			name:    "failing in time",
+			handler: func(c echo.Context) error { return errFailed },
+			err:     errFailed,
+		},
+		{
+			name: "deadline",
+			handler: func(c echo.Context) error {
+				<-c.Request().Context().Done()
+				return errFailed
+			},
+			code: utils.ErrRequestTimeout.Code,
+			err:  errFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+			c.SetPath("/api/v1/friends")
+
+			err := TimeoutMiddleware(TimeoutConfig{Default: time.Millisecond * 10})(tt.handler)(c)
+
+			var he *echo.HTTPError
+			if errors.As(err, &he) {
+				if he.Code != tt.code || he.Internal != tt.err {
+					t.Errorf("got %d with %v, want %d with %v", he.Code, he.Internal, tt.code, tt.err)
+				}
+			} else if tt.code != 0 || err != tt.err {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+			if c.Request().Context().Err() != nil {
+				t.Error("the request keeps the context of the deadline")
+			}
+		})
+	}
+}
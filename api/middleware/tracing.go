@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+)
+
+// untracedPaths are the probe and scrape routes, they aren't traced.
+var untracedPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// TracingMiddleware starts a span for every request, continuing the trace of
+// an incoming traceparent header. It must run first so the request logger
+// and the error handler see the span.
+func TracingMiddleware(service string) echo.MiddlewareFunc {
+	return otelecho.Middleware(service, otelecho.WithSkipper(func(c echo.Context) bool {
+		return untracedPaths[c.Path()]
+	}))
+}
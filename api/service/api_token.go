@@ -0,0 +1,46 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APITokenPrefix is the prefix of every personal api token, it lets the
+// middleware tell them apart from JWTs.
+const APITokenPrefix = "ero_pat_"
+
+// apiTokenLength is the number of random bytes in a personal api token.
+const apiTokenLength = 32
+
+// GenerateAPIToken generates a new personal api token.
+//
+// It returns the token to hand to the user, a short display prefix and the
+// hash to store. The token itself is never stored.
+func GenerateAPIToken() (token, prefix, hash string, err error) {
+	b := make([]byte, apiTokenLength)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+
+	token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	prefix = token[:len(APITokenPrefix)+6]
+	hash = HashAPIToken(token)
+
+	return token, prefix, hash, nil
+}
+
+// HashAPIToken hashes a personal api token for storage and lookup.
+//
+// The tokens carry 256 bits of entropy, so a fast hash is enough.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIToken reports whether the token looks like a personal api token.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"crypto/rsa"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -73,7 +75,7 @@ func NewJWTService(privateKey, publicKey []byte, tokenDuration, refreshTokenDura
 }
 
 // GenerateTokens generates a token and a refresh token.
-func (s *JWTService) GenerateTokens(email string, userId uuid.UUID) (string, string, error) {
+func (s *JWTService) GenerateTokens(ctx context.Context, email string, userId uuid.UUID) (string, string, error) {
 	var (
 		token        string
 		refreshToken string
@@ -96,7 +98,7 @@ func (s *JWTService) GenerateTokens(email string, userId uuid.UUID) (string, str
 }
 
 // ValidateToken validates a token.
-func (s *JWTService) ValidateToken(tokenString string) (bool, error) {
+func (s *JWTService) ValidateToken(ctx context.Context, tokenString string) (bool, error) {
 	var (
 		token *jwt.Token
 		err   error
@@ -116,7 +118,7 @@ func (s *JWTService) ValidateToken(tokenString string) (bool, error) {
 }
 
 // GetClaims gets the claims from a token.
-func (s *JWTService) GetClaims(tokenString string) (jwt.MapClaims, error) {
+func (s *JWTService) GetClaims(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
 	var (
 		token  *jwt.Token
 		claims jwt.MapClaims
@@ -142,19 +144,19 @@ func (s *JWTService) GetClaims(tokenString string) (jwt.MapClaims, error) {
 }
 
 // GenerateToken generates a token.
-func (s *JWTService) GenerateToken(email string, userId uuid.UUID, tokenType TokenType) (string, error) {
+func (s *JWTService) GenerateToken(ctx context.Context, email string, userId uuid.UUID, tokenType TokenType) (string, error) {
 	d := tokenType.Duration(s)
 	return s.createToken(email, userId, time.Now().Add(d).Unix())
 }
 
-func (s *JWTService) RefreshToken(refreshToken string) (string, error) {
+func (s *JWTService) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
 	// Validate the refresh token.
-	if ok, err := s.ValidateToken(refreshToken); err != nil || !ok {
+	if ok, err := s.ValidateToken(ctx, refreshToken); err != nil || !ok {
 		return "", err
 	}
 
 	// Get the claims from the refresh token.
-	claims, err := s.GetClaims(refreshToken)
+	claims, err := s.GetClaims(ctx, refreshToken)
 	if err != nil {
 		return "", err
 	}
@@ -181,6 +183,23 @@ func (s *JWTService) RefreshToken(refreshToken string) (string, error) {
 	return s.createToken(email, uid, time.Now().Add(s.TokenDuration).Unix())
 }
 
+// Check signs and validates a probe token, it fails when the signing keys
+// are unusable.
+func (s *JWTService) Check(ctx context.Context) error {
+	if s.RSAPrivateKey == nil || s.RSAPublicKey == nil {
+		return errors.New("signing keys are not loaded")
+	}
+
+	token, err := s.createToken("", uuid.Nil, 60)
+	if err != nil {
+		return err
+	}
+	if ok, err := s.ValidateToken(ctx, token); err != nil || !ok {
+		return errors.Join(errors.New("probe token is invalid"), err)
+	}
+	return nil
+}
+
 // createToken creates a token.
 func (s *JWTService) createToken(email string, userId uuid.UUID, duration int64) (string, error) {
 	var (
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/coderero/erochat-server/types"
+	"golang.org/x/net/html"
+)
+
+const (
+	// linkPreviewMaxBytes is the most of a page read looking for its metadata.
+	linkPreviewMaxBytes = 512 << 10
+
+	// linkPreviewMaxRedirects is the most redirects followed for a link.
+	linkPreviewMaxRedirects = 3
+
+	// linkPreviewUserAgent is sent with the preview requests.
+	linkPreviewUserAgent = "erochat-link-preview/1.0"
+)
+
+var (
+	// ErrUnsupportedLink is returned for links that aren't http or https.
+	ErrUnsupportedLink = errors.New("unsupported link")
+
+	// errPrivateAddress is returned when a link resolves to a private address.
+	errPrivateAddress = errors.New("link resolves to a private address")
+)
+
+// LinkPreviewService fetches the preview metadata of links from their Open
+// Graph tags, falling back to the page title and description.
+//
+// Links resolving to loopback, private, shared, link-local or any other
+// special purpose address are refused, the check runs on every dial so
+// redirects and DNS changes can't bypass it.
+type LinkPreviewService struct {
+	// client is the HTTP client used to fetch the pages.
+	client *http.Client
+}
+
+// NewLinkPreviewService creates a new LinkPreviewService.
+func NewLinkPreviewService(timeout time.Duration) *LinkPreviewService {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublicIP(addrPort.Addr()) {
+				return errPrivateAddress
+			}
+			return nil
+		},
+	}
+
+	return &LinkPreviewService{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:               nil,
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= linkPreviewMaxRedirects {
+					return http.ErrUseLastResponse
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return ErrUnsupportedLink
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Fetch fetches the preview metadata of a link.
+func (s *LinkPreviewService) Fetch(ctx context.Context, rawURL string) (*types.LinkPreview, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrUnsupportedLink
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", linkPreviewUserAgent)
+	req.Header.Set("Accept", "text/html")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("link preview: unexpected status %d", res.StatusCode)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType != "text/html" {
+		return nil, fmt.Errorf("link preview: unexpected content type %q", mediaType)
+	}
+
+	preview := parseLinkPreview(io.LimitReader(res.Body, linkPreviewMaxBytes), res.Request.URL)
+	preview.URL = u.String()
+	return preview, nil
+}
+
+// parseLinkPreview reads the metadata of the head of a page.
+func parseLinkPreview(r io.Reader, base *url.URL) *types.LinkPreview {
+	var (
+		preview     = &types.LinkPreview{}
+		title       string
+		description string
+		inTitle     bool
+	)
+
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		name, _ := z.TagName()
+		tag := string(name)
+		if tt == html.EndTagToken && tag == "head" || tt == html.StartTagToken && tag == "body" {
+			break
+		}
+
+		switch {
+		case tt == html.StartTagToken && tag == "title":
+			inTitle = true
+		case tt == html.EndTagToken && tag == "title":
+			inTitle = false
+		case tt == html.TextToken && inTitle && title == "":
+			title = strings.TrimSpace(string(z.Text()))
+		case (tt == html.StartTagToken || tt == html.SelfClosingTagToken) && tag == "meta":
+			var key, content string
+			for {
+				k, v, more := z.TagAttr()
+				switch string(k) {
+				case "property", "name":
+					key = strings.ToLower(string(v))
+				case "content":
+					content = strings.TrimSpace(string(v))
+				}
+				if !more {
+					break
+				}
+			}
+
+			switch key {
+			case "og:title":
+				preview.Title = content
+			case "og:description":
+				preview.Description = content
+			case "og:image":
+				if img, err := base.Parse(content); err == nil && (img.Scheme == "http" || img.Scheme == "https") {
+					preview.Image = img.String()
+				}
+			case "og:site_name":
+				preview.SiteName = content
+			case "description":
+				description = content
+			}
+		}
+	}
+
+	if preview.Title == "" {
+		preview.Title = title
+	}
+	if preview.Description == "" {
+		preview.Description = description
+	}
+	if preview.SiteName == "" {
+		preview.SiteName = base.Hostname()
+	}
+
+	preview.Title = truncate(preview.Title, 255)
+	preview.Description = truncate(preview.Description, 500)
+	preview.SiteName = truncate(preview.SiteName, 100)
+	if len(preview.Image) > 2048 {
+		preview.Image = ""
+	}
+	return preview
+}
+
+// nonPublicPrefixes are the special purpose ranges of the IANA registries,
+// they may reach internal services on cloud networks.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // shared address space, CGNAT
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local, cloud metadata
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, broadcast
+
+	netip.MustParsePrefix("::/128"),         // unspecified
+	netip.MustParsePrefix("::1/128"),        // loopback
+	netip.MustParsePrefix("::ffff:0:0/96"),  // IPv4-mapped, checked unmapped
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, embeds an IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local NAT64
+	netip.MustParsePrefix("100::/64"),       // discard
+	netip.MustParsePrefix("2001::/23"),      // IETF protocol assignments, Teredo
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+	netip.MustParsePrefix("2002::/16"),      // 6to4, embeds an IPv4 address
+	netip.MustParsePrefix("3fff::/20"),      // documentation
+	netip.MustParsePrefix("fc00::/7"),       // unique local
+	netip.MustParsePrefix("fe80::/10"),      // link-local
+	netip.MustParsePrefix("fec0::/10"),      // site-local
+	netip.MustParsePrefix("ff00::/8"),       // multicast
+}
+
+// isPublicIP reports whether an address is routable on the internet. The
+// IPv4-mapped IPv6 addresses are checked as IPv4, and the zone is dropped
+// since the prefixes never contain zoned addresses.
+func isPublicIP(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	addr = addr.Unmap().WithZone("")
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// truncate shortens a string to at most n runes.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/coderero/erochat-server/types"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.215.14", true},
+		{"8.8.8.8", true},
+		{"100.63.255.255", true},
+		{"100.128.0.0", true},
+		{"198.20.0.1", true},
+		{"2606:4700::1111", true},
+		{"2a00:1450:4001::1", true},
+
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"10.1.2.3", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"127.0.0.1", false},
+		{"169.254.169.254", false},
+		{"172.16.0.1", false},
+		{"172.31.255.255", false},
+		{"192.0.0.8", false},
+		{"192.0.2.1", false},
+		{"192.88.99.1", false},
+		{"192.168.1.1", false},
+		{"198.18.0.1", false},
+		{"198.19.255.255", false},
+		{"198.51.100.1", false},
+		{"203.0.113.1", false},
+		{"224.0.0.1", false},
+		{"240.0.0.1", false},
+		{"255.255.255.255", false},
+
+		{"::", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"::ffff:100.64.0.1", false},
+		{"64:ff9b::a00:1", false},
+		{"64:ff9b:1::1", false},
+		{"100::1", false},
+		{"2001::1", false},
+		{"2001:db8::1", false},
+		{"2002:a00:1::1", false},
+		{"fc00::1", false},
+		{"fd12:3456::1", false},
+		{"fe80::1", false},
+		{"fe80::1%eth0", false},
+		{"fec0::1", false},
+		{"ff02::1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := isPublicIP(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("isPublicIP(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+
+	// The ::ffff:93.184.215.14 mapped form of a public address stays public.
+	if !isPublicIP(netip.MustParseAddr("::ffff:93.184.215.14")) {
+		t.Error("a mapped public address is refused")
+	}
+	if isPublicIP(netip.Addr{}) {
+		t.Error("the zero address is accepted")
+	}
+}
+
+func TestParseLinkPreview(t *testing.T) {
+	base, _ := url.Parse("https://example.com/articles/1")
+
+	tests := []struct {
+		name string
+		page string
+		want types.LinkPreview
+	}{
+		{
+			name: "open graph",
+			page: `<html><head>
+				<title>Page title</title>
+				<meta property="og:title" content=" OG title ">
+				<meta property="og:description" content="OG description">
+				<meta property="og:image" content="/images/cover.png">
+				<meta property="og:site_name" content="Example">
+				<meta name="description" content="Meta description">
+			</head><body></body></html>`,
+			want: types.LinkPreview{
+				Title:       "OG title",
+				Description: "OG description",
+				Image:       "https://example.com/images/cover.png",
+				SiteName:    "Example",
+			},
+		},
+		{
+			name: "fallbacks",
+			page: `<html><head><title> Page title </title><meta name="Description" content="Meta description"></head></html>`,
+			want: types.LinkPreview{
+				Title:       "Page title",
+				Description: "Meta description",
+				SiteName:    "example.com",
+			},
+		},
+		{
+			name: "uppercase property",
+			page: `<head><meta property="OG:TITLE" content="Shouted"/></head>`,
+			want: types.LinkPreview{Title: "Shouted", SiteName: "example.com"},
+		},
+		{
+			name: "unsupported image scheme",
+			page: `<head><meta property="og:image" content="javascript:alert(1)"><meta property="og:image" content="data:image/png;base64,AAAA"></head>`,
+			want: types.LinkPreview{SiteName: "example.com"},
+		},
+		{
+			name: "tags after the head are ignored",
+			page: `<html><head><title>Head</title></head><body><meta property="og:title" content="Body"><title>Body</title></body></html>`,
+			want: types.LinkPreview{Title: "Head", SiteName: "example.com"},
+		},
+		{
+			name: "not html",
+			page: "\x00\x01binary",
+			want: types.LinkPreview{SiteName: "example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseLinkPreview(strings.NewReader(tt.page), base); *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLinkPreviewTruncates(t *testing.T) {
+	base, _ := url.Parse("https://example.com")
+	page := `<head>` +
+		`<meta property="og:title" content="` + strings.Repeat("é", 300) + `">` +
+		`<meta property="og:description" content="` + strings.Repeat("d", 600) + `">` +
+		`<meta property="og:site_name" content="` + strings.Repeat("s", 150) + `">` +
+		`<meta property="og:image" content="https://example.com/` + strings.Repeat("i", 2100) + `">` +
+		`</head>`
+
+	got := parseLinkPreview(strings.NewReader(page), base)
+	if n := len([]rune(got.Title)); n != 255 {
+		t.Errorf("title has %d runes, want 255", n)
+	}
+	if n := len(got.Description); n != 500 {
+		t.Errorf("description has %d bytes, want 500", n)
+	}
+	if n := len(got.SiteName); n != 100 {
+		t.Errorf("site name has %d bytes, want 100", n)
+	}
+	if got.Image != "" {
+		t.Errorf("image of %d bytes is kept", len(got.Image))
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// RecordAudit appends a security event about a user to the audit log.
+//
+// The ip, user agent and request id are taken from the request. A failure to
+// record the event is logged and never fails the request.
+func RecordAudit(c echo.Context, store interfaces.AuditStore, eventType types.AuditEventType, userUID uuid.UUID, metadata map[string]string) {
+	if store == nil {
+		return
+	}
+
+	// The action already happened, the event is recorded even when the
+	// client went away.
+	ctx := context.WithoutCancel(c.Request().Context())
+
+	event := &types.AuditEvent{
+		UID:       uuid.New(),
+		UserUID:   userUID,
+		Type:      eventType,
+		IP:        c.RealIP(),
+		UserAgent: truncate(c.Request().UserAgent(), 512),
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+		Metadata:  metadata,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	// Admin routes record the admin as the actor.
+	if actor, ok := c.Get("audit_actor").(uuid.UUID); ok && actor != userUID {
+		event.ActorUID = uuid.NullUUID{UUID: actor, Valid: true}
+	}
+
+	if err := store.Record(ctx, event); err != nil {
+		LogError(c, "failed to record audit event", err, slog.String("event_type", string(eventType)))
+	}
+}
+
+// truncate shortens a string to at most n bytes.
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -1,20 +1,53 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/coderero/erochat-server/tracing"
 	"github.com/coderero/erochat-server/types"
 	"github.com/labstack/echo/v4"
 )
 
+// StatusClientClosedRequest is the status of the requests whose client went
+// away before the response, as logged by nginx.
+const StatusClientClosedRequest = 499
+
+var (
+	// ErrRequestTimeout is returned when a request ran out of time.
+	ErrRequestTimeout = echo.NewHTTPError(http.StatusGatewayTimeout, "request timed out")
+
+	// ErrClientClosedRequest is returned when the client went away before
+	// the response.
+	ErrClientClosedRequest = echo.NewHTTPError(StatusClientClosedRequest, "client closed request")
+)
+
+// ContextError returns the error of a request that failed because its
+// context is done, a timeout or a client closed request depending on the
+// context error. The cause is kept as the internal error.
+func ContextError(ctxErr, err error) *echo.HTTPError {
+	var he *echo.HTTPError
+	if errors.As(err, &he) && he.Internal != nil {
+		err = he.Internal
+	}
+
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		return ErrRequestTimeout.WithInternal(err)
+	}
+	return ErrClientClosedRequest.WithInternal(err)
+}
+
 // Custom HTTPErrorHandler is a custom error handler for HTTP errors.
 func CustomHTTPErrorHandler(e *echo.Echo) echo.HTTPErrorHandler {
 	return func(err error, c echo.Context) {
 		var (
 			errRes = types.ApiResponse{
 				Status: types.Failure.String(),
+				// Support correlates a reported error with its trace.
+				TraceID: tracing.TraceID(c.Request().Context()),
 			}
 		)
 
@@ -22,14 +55,27 @@ func CustomHTTPErrorHandler(e *echo.Echo) echo.HTTPErrorHandler {
 			return
 		}
 
+		// The stores return the context errors of the requests that ran out
+		// of time or whose client went away, they're answered as such.
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			if he, ok := err.(*echo.HTTPError); !ok || he.Code == http.StatusInternalServerError {
+				err = ContextError(err, err)
+			}
+		}
+
 		he, ok := err.(*echo.HTTPError)
 		if ok {
 			if he.Internal != nil {
 				if herr, ok := he.Internal.(*echo.HTTPError); ok {
 					he = herr
+				} else if he.Code != StatusClientClosedRequest {
+					// The cause is logged, the client gets the generic message.
+					LogError(c, "request failed", he.Internal)
 				}
 			}
 		} else {
+			LogError(c, "request failed", err)
+
 			he = &echo.HTTPError{
 				Code:    http.StatusInternalServerError,
 				Message: err.Error(),
@@ -87,6 +133,12 @@ func extractErrorType(err error) types.ErrorType {
 		return types.ErrorTypeServiceUnavailable
 	case http.StatusUnauthorized:
 		return types.ErrorTypeUnauthorized
+	case http.StatusForbidden:
+		return types.ErrorTypeForbidden
+	case http.StatusGatewayTimeout:
+		return types.ErrorTypeTimeout
+	case StatusClientClosedRequest:
+		return types.ErrorTypeCanceled
 	default:
 		return types.ErrorTypeUnknown
 	}
@@ -1,26 +1,106 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// AccessTokenCookie is the name of the cookie holding the access token.
+	AccessTokenCookie = "__a"
+
+	// RefreshTokenCookie is the name of the cookie holding the refresh token.
+	RefreshTokenCookie = "__r"
+
+	// CSRFCookie is the name of the cookie holding the CSRF token.
+	CSRFCookie = "__c"
+
+	// CSRFHeader is the header the client must echo the CSRF token in.
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// CookieConfig holds the attributes applied to the cookies set by the server.
+type CookieConfig struct {
+	// Domain is the domain the cookies are scoped to.
+	Domain string
+
+	// Secure restricts the cookies to HTTPS.
+	Secure bool
+
+	// HttpOnly hides the cookies from JavaScript.
+	HttpOnly bool
+
+	// SameSite is the SameSite attribute of the cookies.
+	SameSite http.SameSite
+
+	// MaxAge is the lifetime of the cookies.
+	MaxAge time.Duration
+
+	// RefreshMaxAge is the lifetime of the refresh token cookie, MaxAge when
+	// zero.
+	RefreshMaxAge time.Duration
+}
+
+// cookieConfig is the cookie configuration used by SaveCookie and DeleteCookie.
+var cookieConfig = CookieConfig{
+	HttpOnly:      true,
+	SameSite:      http.SameSiteLaxMode,
+	MaxAge:        24 * time.Hour,
+	RefreshMaxAge: 24 * time.Hour,
+}
+
+// ErrInsecureSameSiteNone is returned when SameSite=None cookies aren't
+// restricted to HTTPS, browsers reject them.
+var ErrInsecureSameSiteNone = errors.New("SameSite=None cookies must be secure")
+
+// SetCookieConfig sets the cookie configuration used by the server.
+func SetCookieConfig(config CookieConfig) error {
+	if config.SameSite == http.SameSiteNoneMode && !config.Secure {
+		return ErrInsecureSameSiteNone
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = 24 * time.Hour
+	}
+	if config.RefreshMaxAge <= 0 {
+		config.RefreshMaxAge = config.MaxAge
+	}
+	cookieConfig = config
+	return nil
+}
+
+// ParseSameSite parses a SameSite mode from its name, defaulting to lax.
+func ParseSameSite(mode string) http.SameSite {
+	switch strings.ToLower(mode) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// SaveCookie saves a cookie with the configured attributes.
 func SaveCookie(c echo.Context, key, value string) {
-	cookie := new(http.Cookie)
-	cookie.Name = key
-	cookie.Value = value
-	cookie.Path = "/"
-	cookie.Expires = time.Now().Add(24 * time.Hour)
-	c.SetCookie(cookie)
+	c.SetCookie(newCookie(key, value, cookieConfig.HttpOnly))
+}
+
+// SaveReadableCookie saves a cookie that is readable by JavaScript.
+func SaveReadableCookie(c echo.Context, key, value string) {
+	c.SetCookie(newCookie(key, value, false))
 }
 
 func DeleteCookie(c echo.Context, key string) {
-	cookie := new(http.Cookie)
-	cookie.Name = key
-	cookie.Value = ""
-	cookie.Expires = time.Now()
+	cookie := newCookie(key, "", cookieConfig.HttpOnly)
+	cookie.Expires = time.Unix(0, 0)
+	cookie.MaxAge = -1
 	c.SetCookie(cookie)
 }
 
@@ -31,3 +111,40 @@ func GetCookie(c echo.Context, key string) string {
 	}
 	return cookie.Value
 }
+
+// GenerateCSRFToken generates a random CSRF token.
+func GenerateCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CompareCSRFToken compares two CSRF tokens in constant time.
+func CompareCSRFToken(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// newCookie creates a cookie with the configured attributes, the refresh
+// token cookie outlives the others.
+func newCookie(key, value string, httpOnly bool) *http.Cookie {
+	maxAge := cookieConfig.MaxAge
+	if key == RefreshTokenCookie {
+		maxAge = cookieConfig.RefreshMaxAge
+	}
+
+	cookie := new(http.Cookie)
+	cookie.Name = key
+	cookie.Value = value
+	cookie.Path = "/"
+	cookie.Domain = cookieConfig.Domain
+	cookie.Secure = cookieConfig.Secure
+	cookie.HttpOnly = httpOnly
+	cookie.SameSite = cookieConfig.SameSite
+	cookie.Expires = time.Now().Add(maxAge)
+	return cookie
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestCookieLifetime(t *testing.T) {
+	saved := cookieConfig
+	t.Cleanup(func() { cookieConfig = saved })
+
+	tests := []struct {
+		name    string
+		config  CookieConfig
+		access  time.Duration
+		refresh time.Duration
+	}{
+		{"configured", CookieConfig{MaxAge: time.Minute * 15, RefreshMaxAge: time.Hour * 24 * 30}, time.Minute * 15, time.Hour * 24 * 30},
+		{"refresh unset", CookieConfig{MaxAge: time.Minute * 15}, time.Minute * 15, time.Minute * 15},
+		{"unset", CookieConfig{}, time.Hour * 24, time.Hour * 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetCookieConfig(tt.config); err != nil {
+				t.Fatal(err)
+			}
+
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+			SaveCookie(c, AccessTokenCookie, "access")
+			SaveCookie(c, RefreshTokenCookie, "refresh")
+			SaveReadableCookie(c, CSRFCookie, "csrf")
+
+			want := map[string]time.Duration{
+				AccessTokenCookie:  tt.access,
+				RefreshTokenCookie: tt.refresh,
+				CSRFCookie:         tt.access,
+			}
+			cookies := rec.Result().Cookies()
+			if len(cookies) != len(want) {
+				t.Fatalf("got %d cookies, want %d", len(cookies), len(want))
+			}
+			for _, cookie := range cookies {
+				// The cookie header keeps the expiry to the second.
+				lifetime := time.Until(cookie.Expires)
+				if lifetime > want[cookie.Name] || lifetime < want[cookie.Name]-time.Second*5 {
+					t.Errorf("%s expires in %v, want %v", cookie.Name, lifetime, want[cookie.Name])
+				}
+			}
+		})
+	}
+}
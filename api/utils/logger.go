@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"log/slog"
+
+	"github.com/coderero/erochat-server/tracing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// loggerKey is the echo.Context key of the request logger.
+const loggerKey = "logger"
+
+// SetLogger stores the request logger on the context.
+func SetLogger(c echo.Context, logger *slog.Logger) {
+	c.Set(loggerKey, logger)
+}
+
+// Logger returns the request logger, it carries the request id. The default
+// logger is returned outside of a request.
+func Logger(c echo.Context) *slog.Logger {
+	if logger, ok := c.Get(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// LogError logs an internal error of a request with its route, user and
+// the given attributes, and records it on the request span. The client only
+// gets a generic message.
+func LogError(c echo.Context, msg string, err error, attrs ...any) {
+	attrs = append(attrs,
+		slog.String("method", c.Request().Method),
+		slog.String("route", c.Path()),
+		slog.Any("error", err),
+	)
+	if uid, ok := c.Get("uid").(string); ok {
+		attrs = append(attrs, slog.String("user_uid", uid))
+	}
+	Logger(c).Error(msg, attrs...)
+	tracing.RecordError(c.Request().Context(), err)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/coderero/erochat-server/config"
+)
+
+// configUsage is printed when the config command is misused.
+const configUsage = "usage: erochat config print"
+
+// runConfig runs the config command, `erochat config print`.
+//
+// The configuration is printed with the secrets redacted, followed by the
+// invalid settings.
+func runConfig(cfg *config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+
+	if err := cfg.Print(os.Stdout); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return nil
+}
@@ -1,108 +1,215 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
-	"strconv"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/coderero/erochat-server/api/handler"
 	apiMiddleware "github.com/coderero/erochat-server/api/middleware"
 	"github.com/coderero/erochat-server/api/service"
 	"github.com/coderero/erochat-server/api/utils"
+	"github.com/coderero/erochat-server/config"
+	"github.com/coderero/erochat-server/db/blob"
 	"github.com/coderero/erochat-server/db/cassd"
+	cassdMigrations "github.com/coderero/erochat-server/db/cassd/migrations"
+	"github.com/coderero/erochat-server/db/memsearch"
 	"github.com/coderero/erochat-server/db/mysql"
+	mysqlMigrations "github.com/coderero/erochat-server/db/mysql/migrations"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/jobs"
+	"github.com/coderero/erochat-server/metrics"
+	"github.com/coderero/erochat-server/tracing"
+	"github.com/coderero/erochat-server/types"
 	"github.com/go-playground/validator/v10"
-	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-func init() {
-	if err := godotenv.Load(); err != nil {
+// version is the build version, set with -ldflags "-X main.version=...".
+var version = "dev"
+
+func main() {
+	/* Configuration */
+
+	// Load the configuration, the arguments left are the command to run.
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
 		log.Fatal(err)
 	}
-}
 
-func main() {
-	/* My SQL Connection Pool */
+	// Structured logs, the log package writes through them too.
+	logger := cfg.Logger(os.Stderr)
+	slog.SetDefault(logger)
+
+	// `erochat config print` prints the configuration with the secrets
+	// redacted and exits.
+	if len(args) > 0 && args[0] == "config" {
+		if err := runConfig(cfg, args[1:]); err != nil {
+			fatal("config command failed", err)
+		}
+		return
+	}
 
-	// Configuration variables.
-	var (
-		// MySQL DSN.
-		dsn = os.Getenv("MYSQL_DSN")
+	if err := cfg.Validate(); err != nil {
+		fatal("invalid configuration", err)
+	}
+	slog.Info("configuration loaded", slog.String("version", version), slog.Any("config", cfg.Redacted()))
 
-		// Maximum number of connections in the pool.
-		maxConnections = os.Getenv("MYSQL_MAX_CONNECTIONS")
-	)
+	/* Tracing */
 
-	// Parse the maximum number of connections.
-	maxConnectionsInt, err := strconv.Atoi(maxConnections)
+	// Install the tracer provider before the database clients are created,
+	// it's flushed during the graceful shutdown.
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, version)
 	if err != nil {
-		panic(err)
+		fatal("failed to set up tracing", err)
 	}
 
-	// Create a new connection pool.
-	db, err := mysql.NewConnectionPool(dsn, maxConnectionsInt)
+	/* MySQL Database */
+
+	// Open the primary and the read replicas.
+	db, err := mysql.NewDB(mysql.DBConfig{
+		DSN:             cfg.MySQL.DSN,
+		ReplicaDSNs:     cfg.MySQL.ReplicaDSNs,
+		MaxOpenConns:    cfg.MySQL.MaxConnections,
+		MaxIdleConns:    cfg.MySQLMaxIdleConnections(),
+		ConnMaxLifetime: cfg.MySQL.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.MySQL.ConnMaxIdleTime,
+	})
 	if err != nil {
 		panic(err)
 	}
 
-	// Close the connection pool when the main function returns.
-	obj, err := db.Get()
+	// Close the databases when the main function returns, after the
+	// Cassandra session.
+	defer db.Close()
+
+	/* Cassandra Session */
+
+	// `erochat migrate ...` migrates the schemas and exits.
+	migrateCommand := len(args) > 0 && args[0] == "migrate"
+
+	// The keyspace must exist before a session can use it.
+	if migrateCommand || cfg.Migrations.OnStart {
+		replication := cassd.Replication{
+			Strategy: cfg.Cassandra.ReplicationStrategy,
+			Factor:   cfg.Cassandra.ReplicationFactor,
+		}
+		replication.DataCenters, err = cassd.ParseDataCenters(cfg.Cassandra.DataCenters)
+		if err != nil {
+			panic(err)
+		}
+
+		if err = cassd.CreateKeyspace(cfg.Cassandra.Host, cfg.Cassandra.Username, cfg.Cassandra.Password, cfg.Cassandra.Keyspace, replication); err != nil {
+			panic(err)
+		}
+	}
+
+	// Create a new Cassandra session.
+	session, err := cassd.NewSession(cfg.Cassandra.Host, cfg.Cassandra.Username, cfg.Cassandra.Password, cfg.Cassandra.Keyspace)
 	if err != nil {
 		panic(err)
 	}
-	defer obj.Close()
+	defer session.Close()
 
-	/* Cassandra Session */
+	// SIGINT and SIGTERM cancel the migrations and start the graceful
+	// shutdown.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Configuration variables.
-	var (
-		// Cassandra Host.
-		cassandraHost = os.Getenv("CASSANDRA_HOST")
+	/* Schema Migrations */
 
-		// Cassandra Username.
-		cassandraUsername = os.Getenv("CASSANDRA_USERNAME")
+	migrators := []storeMigrator{
+		{name: "mysql", migrator: mysql.NewMigrator(db, mysqlMigrations.FS)},
+		{name: "cassandra", migrator: cassd.NewMigrator(session, cassdMigrations.FS)},
+	}
 
-		// Cassandra Password.
-		cassandraPassword = os.Getenv("CASSANDRA_PASSWORD")
-	)
+	// The migrations give up when interrupted or past their deadline.
+	migrateCtx, cancelMigrate := context.WithTimeout(ctx, cfg.Migrations.Timeout)
+	defer cancelMigrate()
 
-	// Create a new Cassandra session.
-	_, err = cassd.NewSession(cassandraHost, cassandraUsername, cassandraPassword, "erochat")
-	if err != nil {
-		panic(err)
+	if migrateCommand {
+		if err := runMigrate(migrateCtx, migrators, args[1:]); err != nil {
+			fatal("migrate command failed", err)
+		}
+		return
+	}
+
+	// Apply the pending migrations before serving.
+	if cfg.Migrations.OnStart {
+		if err := migrateOnStart(migrateCtx, migrators); err != nil {
+			panic(err)
+		}
 	}
 
+	/* Audit Log */
+
+	// Backend of the audit log.
+	var audit interfaces.AuditStore = mysql.NewAuditStore(db)
+	if cfg.Audit.Backend == "cassandra" {
+		audit = cassd.NewAuditStore(session)
+	}
+	audit = tracing.NewAuditStore(audit)
+
 	// Get RSA keys for JWT from the certificate files.
-	privKey, err := utils.GetFile("certs/app.rsa.key")
+	privKey, err := utils.GetFile(cfg.Auth.PrivateKeyFile)
 	if err != nil {
 		panic(err)
 	}
-	pubKey, err := utils.GetFile("certs/app.rsa.pub")
+	pubKey, err := utils.GetFile(cfg.Auth.PublicKeyFile)
 	if err != nil {
 		panic(err)
 	}
 
 	// Create a new token service.
-	tokenService, err := service.NewJWTService(privKey, pubKey, time.Hour*24, time.Hour*24*7)
+	tokenService, err := service.NewJWTService(privKey, pubKey, cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL)
+	if err != nil {
+		panic(err)
+	}
+
+	/* Cookies */
+
+	err = utils.SetCookieConfig(utils.CookieConfig{
+		Domain:        cfg.Cookie.Domain,
+		Secure:        cfg.CookieSecure(),
+		HttpOnly:      true,
+		SameSite:      utils.ParseSameSite(cfg.CookieSameSite()),
+		MaxAge:        cfg.Auth.AccessTokenTTL,
+		RefreshMaxAge: cfg.Auth.RefreshTokenTTL,
+	})
 	if err != nil {
 		panic(err)
 	}
 
 	// Echo and HTTP server Configuration variables.
 
+	// Deadlines of the route groups, validated with the configuration.
+	routeTimeouts, _ := cfg.RouteTimeouts()
+
 	var (
 		// Echo instance.
 		app = echo.New()
 
 		// Echo middleware.
-		recover = middleware.Recover()
-		logger  = middleware.Logger()
-		cors    = middleware.CORSWithConfig(middleware.CORSConfig{
-			AllowOrigins: []string{"*"},
-			AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
+		recover       = middleware.Recover()
+		requestID     = apiMiddleware.RequestIDMiddleware(logger)
+		requestLogger = apiMiddleware.RequestLoggerMiddleware()
+		cors          = middleware.CORSWithConfig(middleware.CORSConfig{
+			AllowOrigins:     cfg.CORS.AllowOrigins,
+			AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, utils.CSRFHeader, "traceparent", "tracestate"},
+			ExposeHeaders:    []string{echo.HeaderXRequestID},
+			AllowCredentials: true,
+		})
+		timeout = apiMiddleware.TimeoutMiddleware(apiMiddleware.TimeoutConfig{
+			Default: cfg.App.RequestTimeout,
+			Routes:  routeTimeouts,
 		})
 
 		// Echo Routes.
@@ -110,79 +217,283 @@ func main() {
 		apiAuthV1 = app.Group("/api/auth/v1")
 
 		// Service initialization.
-		passService     = service.NewScryptService(1<<14, 8, 1, 32, 22)
+		passService     = metrics.NewPassService(service.NewScryptService(cfg.Auth.Scrypt.N, cfg.Auth.Scrypt.R, cfg.Auth.Scrypt.P, cfg.Auth.Scrypt.KeyLen, cfg.Auth.Scrypt.SaltLen))
 		jwtTokenService = tokenService
+		linkPreviews    = service.NewLinkPreviewService(time.Second * 5)
 
 		// Middleware initialization.
-		auth = apiMiddleware.JWTMiddleware(jwtTokenService)
+		csrf = apiMiddleware.CSRFMiddleware()
 
 		// Store initialization.
-		user    = mysql.NewUserStore(db)
-		profile = mysql.NewProfileStore(db)
-		status  = mysql.NewStatusStore(db)
-		friend  = mysql.NewFriendStore(db)
+		user       = metrics.NewUserStore(tracing.NewUserStore(mysql.NewUserStore(db)))
+		profile    = metrics.NewProfileStore(tracing.NewProfileStore(mysql.NewProfileStore(db)))
+		status     = metrics.NewStatusStore(tracing.NewStatusStore(mysql.NewStatusStore(db, cfg.Status.TTL)))
+		friend     = metrics.NewFriendStore(tracing.NewFriendStore(mysql.NewFriendStore(db, cfg.Status.TTL)))
+		apiToken   = tracing.NewAPITokenStore(mysql.NewAPITokenStore(db))
+		privacy    = tracing.NewPrivacyStore(mysql.NewPrivacyStore(db))
+		lists      = tracing.NewFriendListStore(mysql.NewFriendListStore(db))
+		highlights = tracing.NewHighlightStore(mysql.NewHighlightStore(db))
+		messages   = tracing.NewMessageStore(cassd.NewMessageStore(session))
+		blobs      = blob.NewLocalStore(cfg.Media.Dir, cfg.Media.BaseURL)
+
+		// Background job runner, leader only jobs run on the instance
+		// holding the MySQL lock.
+		jobRunner = jobs.NewRunner(mysql.NewLeaderLock(db, "erochat_jobs_leader"), cfg.Jobs.Jitter)
+	)
+
+	// Search index initialization, the in-memory index is filled by its
+	// rebuild job.
+	var (
+		search       interfaces.SearchIndex = tracing.NewSearchIndex(mysql.NewSearchIndex(db))
+		memorySearch *memsearch.Index
+	)
+	if cfg.Search.Backend == "memory" {
+		memorySearch = memsearch.NewIndex(user, friend)
+		search = tracing.NewSearchIndex(memorySearch)
+	}
+
+	var (
+		// Authentication middleware initialization.
+		auth = apiMiddleware.JWTMiddleware(apiMiddleware.JWTMiddlewareConfig{
+			TokenService:  jwtTokenService,
+			APITokenStore: apiToken,
+			UserStore:     user,
+			AuditStore:    audit,
+		})
+		lastSeen = apiMiddleware.LastSeenMiddleware(user)
+		admin    = apiMiddleware.AdminMiddleware(apiMiddleware.AdminMiddlewareConfig{
+			UserStore:  user,
+			AuditStore: audit,
+		})
 
 		// Validator initialization.
 		validator = validator.New()
 
 		// Handler initialization.
-		authHandler       = handler.NewAuthHandler(validator, user, passService, jwtTokenService)
-		profileHandler    = handler.NewProfileHandler(validator, profile, user)
-		statusHandler     = handler.NewUserStatusHandler(validator, user, status)
-		friendshipHandler = handler.NewUserFriendShipHandler(validator, user, friend)
+		authHandler       = handler.NewAuthHandler(validator, user, passService, jwtTokenService, apiToken, audit)
+		profileHandler    = handler.NewProfileHandler(validator, profile, user, friend, audit, privacy, search, highlights, apiToken)
+		statusHandler     = handler.NewUserStatusHandler(validator, user, status, lists, friend, linkPreviews)
+		friendshipHandler = handler.NewUserFriendShipHandler(validator, user, friend, privacy, status, cfg.Friends.DeclineCooldown)
+		apiTokenHandler   = handler.NewAPITokenHandler(validator, user, apiToken)
+		auditHandler      = handler.NewAuditHandler(audit)
+		searchHandler     = handler.NewSearchHandler(search)
+		privacyHandler    = handler.NewPrivacyHandler(validator, privacy, search)
+		friendListHandler = handler.NewFriendListHandler(validator, friend, lists)
+		messageHandler    = handler.NewMessageHandler(validator, friend, status, messages)
+		highlightHandler  = handler.NewHighlightHandler(validator, highlights)
+		jobHandler        = handler.NewJobHandler(jobRunner)
+		healthHandler     = handler.NewHealthHandler(map[string]interfaces.HealthChecker{
+			"mysql":        db,
+			"cassandra":    cassd.NewHealthCheck(session),
+			"signing_keys": tokenService,
+		}, cfg.App.HealthCheckTimeout, db, version)
 	)
 
 	// Use middleware.
 
 	/* Main App */
+	app.Use(apiMiddleware.TracingMiddleware(cfg.Tracing.ServiceName))
+	app.Use(requestID)
+	app.Use(requestLogger)
 	app.Use(recover)
-	app.Use(logger)
+	app.Use(apiMiddleware.MetricsMiddleware())
 	app.Use(cors)
+	app.Use(timeout)
 
 	/* API V1 */
 	apiV1.Use(auth)
+	apiV1.Use(csrf)
+	apiV1.Use(lastSeen)
 
 	// Echo configration
 	app.HTTPErrorHandler = utils.CustomHTTPErrorHandler(app)
+	app.HideBanner = true
+	app.HidePort = true
 
 	// Validator configuration.
 	validator.RegisterTagNameFunc(utils.ValidatorTagFunc)
 
 	// Routes.
 
+	/* Health routes. */
+	app.GET("/healthz", healthHandler.Healthz)
+	app.GET("/readyz", healthHandler.Readyz)
+
+	/* Metrics routes. */
+	metrics.RegisterPool("mysql", db)
+	for i, replica := range db.Replicas() {
+		metrics.RegisterPool(fmt.Sprintf("mysql_replica_%d", i+1), replica)
+	}
+	app.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+
 	/* Auth routes. */
 	apiAuthV1.POST("/login", authHandler.Login)
 	apiAuthV1.POST("/register", authHandler.Register)
 	apiAuthV1.POST("/logout", authHandler.Logout)
 
+	// Route groups, personal api tokens are limited to their scopes and can't
+	// manage tokens or bots. Groups must be created after apiV1.Use.
+	var (
+		profileV1  = apiV1.Group("/user/profile", apiMiddleware.RequireScope(types.ScopeProfile))
+		statusV1   = apiV1.Group("/user/status", apiMiddleware.RequireScope(types.ScopeStatus))
+		friendsV1  = apiV1.Group("/user/friends", apiMiddleware.RequireScope(types.ScopeFriends))
+		searchV1   = apiV1.Group("/user/search", apiMiddleware.RequireScope(types.ScopeProfile))
+		messagesV1 = apiV1.Group("/user/messages", apiMiddleware.RequireScope(types.ScopeMessages))
+		tokensV1   = apiV1.Group("/user/tokens", apiMiddleware.RequireSession())
+		botsV1     = apiV1.Group("/user/bots", apiMiddleware.RequireSession())
+		accountV1  = apiV1.Group("/user/account", apiMiddleware.RequireSession())
+		adminV1    = apiV1.Group("/admin", apiMiddleware.RequireSession(), admin)
+	)
+
 	/* User routes. */
-	apiV1.GET("/user/profile", profileHandler.GetProfile)
-	apiV1.POST("/user/profile", profileHandler.CreateProfile)
-	apiV1.PUT("/user/profile", profileHandler.UpdateProfile)
-	apiV1.GET("/user/profile/:uid", profileHandler.GetProfileByID)
-	apiV1.POST("/user/profile/:uid", profileHandler.AddFriend)
-	apiV1.DELETE("/user/profile", profileHandler.DeleteProfile)
-	apiV1.PATCH("/user/profile/reactivate", profileHandler.ReactivateProfile)
+	profileV1.GET("", profileHandler.GetProfile)
+	profileV1.POST("", profileHandler.CreateProfile)
+	profileV1.PUT("", profileHandler.UpdateProfile)
+	profileV1.GET("/:uid", profileHandler.GetProfileByID)
+	profileV1.POST("/:uid", profileHandler.AddFriend)
+	profileV1.DELETE("", profileHandler.DeleteProfile)
+	profileV1.PATCH("/reactivate", profileHandler.ReactivateProfile)
 
 	/* Status routes. */
-	apiV1.GET("/user/status", statusHandler.GetStatus)
-	apiV1.POST("/user/status", statusHandler.CreateStatus)
-	apiV1.DELETE("/user/status/:uid", statusHandler.DeleteStatus)
+	statusV1.GET("", statusHandler.GetStatus)
+	statusV1.POST("", statusHandler.CreateStatus)
+	statusV1.GET("/archive", statusHandler.GetStatusArchive)
+	statusV1.DELETE("/:uid", statusHandler.DeleteStatus)
+	statusV1.GET("/:uid/viewers", statusHandler.GetStatusViewers)
+	statusV1.GET("/hidden", friendListHandler.GetStatusHiddenFrom)
+	statusV1.PUT("/hidden/:uid", friendListHandler.HideStatusFrom)
+	statusV1.DELETE("/hidden/:uid", friendListHandler.UnhideStatusFrom)
+	statusV1.GET("/highlights", highlightHandler.GetHighlights)
+	statusV1.POST("/highlights", highlightHandler.CreateHighlight)
+	statusV1.PATCH("/highlights/:uid", highlightHandler.RenameHighlight)
+	statusV1.DELETE("/highlights/:uid", highlightHandler.DeleteHighlight)
+	statusV1.PUT("/highlights/:uid/statuses/:sid", highlightHandler.AddHighlightStatus)
+	statusV1.DELETE("/highlights/:uid/statuses/:sid", highlightHandler.RemoveHighlightStatus)
 
 	/* Friend routes. */
-	apiV1.GET("/user/friends/details", friendshipHandler.GetFriends)
-	apiV1.GET("/user/friends/details/:uid", friendshipHandler.GetFriend)
-	apiV1.DELETE("/user/friends/details/:uid", friendshipHandler.DeleteFriend)
-	apiV1.GET("/user/friends/requests", friendshipHandler.GetFriendRequests)
-	apiV1.GET("/user/friends/requests/:uid", friendshipHandler.GetFriendRequest)
-	apiV1.PATCH("/user/friends/requests/:uid", friendshipHandler.AcceptFriendRequest)
-	apiV1.DELETE("/user/friends/requests/:uid", friendshipHandler.DeleteFriendRequest)
-	apiV1.GET("/user/friends/status", friendshipHandler.GetFriendsStatus)
-	apiV1.GET("/user/friends/status/:uid", friendshipHandler.GetFriendStatus)
+	friendsV1.GET("/details", friendshipHandler.GetFriends)
+	friendsV1.GET("/details/:uid", friendshipHandler.GetFriend)
+	friendsV1.DELETE("/details/:uid", friendshipHandler.DeleteFriend)
+	friendsV1.GET("/requests", friendshipHandler.GetFriendRequests)
+	friendsV1.GET("/requests/incoming", friendshipHandler.GetIncomingFriendRequests)
+	friendsV1.GET("/requests/outgoing", friendshipHandler.GetOutgoingFriendRequests)
+	friendsV1.GET("/requests/:uid", friendshipHandler.GetFriendRequest)
+	friendsV1.PATCH("/requests/:uid", friendshipHandler.AcceptFriendRequest)
+	friendsV1.DELETE("/requests/:uid", friendshipHandler.DeleteFriendRequest)
+	friendsV1.POST("/requests/:uid/cancel", friendshipHandler.CancelFriendRequest)
+	friendsV1.POST("/requests/:uid/decline", friendshipHandler.DeclineFriendRequest)
+	friendsV1.GET("/status", friendshipHandler.GetFriendsStatus)
+	friendsV1.GET("/status/muted", friendshipHandler.GetMutedFriends)
+	friendsV1.PUT("/status/muted/:uid", friendshipHandler.MuteStatus)
+	friendsV1.DELETE("/status/muted/:uid", friendshipHandler.UnmuteStatus)
+	friendsV1.GET("/status/:uid", friendshipHandler.GetFriendStatus)
+	friendsV1.POST("/status/:uid/view", friendshipHandler.MarkStatusViewed)
+	friendsV1.POST("/status/:uid/reply", messageHandler.ReplyToStatus, apiMiddleware.RequireScope(types.ScopeMessages))
+	friendsV1.GET("/suggestions", friendshipHandler.GetFriendSuggestions)
+	friendsV1.GET("/lists", friendListHandler.GetFriendLists)
+	friendsV1.POST("/lists", friendListHandler.CreateFriendList)
+	friendsV1.PATCH("/lists/:uid", friendListHandler.RenameFriendList)
+	friendsV1.DELETE("/lists/:uid", friendListHandler.DeleteFriendList)
+	friendsV1.GET("/lists/:uid/members", friendListHandler.GetFriendListMembers)
+	friendsV1.PUT("/lists/:uid/members/:member", friendListHandler.AddFriendListMember)
+	friendsV1.DELETE("/lists/:uid/members/:member", friendListHandler.RemoveFriendListMember)
+	friendsV1.GET("/blocks", friendshipHandler.GetBlockedUsers)
+	friendsV1.POST("/blocks/:uid", friendshipHandler.BlockUser)
+	friendsV1.DELETE("/blocks/:uid", friendshipHandler.UnblockUser)
+
+	/* Search routes. */
+	searchV1.GET("", searchHandler.SearchUsers)
+
+	/* Message routes. */
+	messagesV1.GET("/:uid", messageHandler.GetConversation)
+	messagesV1.POST("/:uid", messageHandler.SendMessage)
+
+	/* Personal api token routes. */
+	tokensV1.GET("", apiTokenHandler.GetTokens)
+	tokensV1.POST("", apiTokenHandler.CreateToken)
+	tokensV1.DELETE("/:uid", apiTokenHandler.RevokeToken)
+
+	/* Bot routes. */
+	botsV1.GET("", apiTokenHandler.GetBots)
+	botsV1.POST("", apiTokenHandler.CreateBot)
+	botsV1.GET("/:uid/tokens", apiTokenHandler.GetBotTokens)
+	botsV1.POST("/:uid/tokens", apiTokenHandler.CreateBotToken)
+	botsV1.DELETE("/:uid/tokens/:tid", apiTokenHandler.RevokeBotToken)
+
+	/* Account security routes. */
+	accountV1.PUT("/password", authHandler.ChangePassword)
+	accountV1.GET("/security-events", auditHandler.GetSecurityEvents)
+	accountV1.GET("/privacy", privacyHandler.GetPrivacySettings)
+	accountV1.PUT("/privacy", privacyHandler.UpdatePrivacySettings)
+
+	/* Admin routes. */
+	adminV1.GET("/audit", auditHandler.QueryAuditLog)
+	adminV1.GET("/jobs", jobHandler.GetJobs)
+	adminV1.GET("/diagnostics", healthHandler.GetDiagnostics)
+
+	/* Background jobs. */
+	jobRunner.Register(jobs.FriendSuggestions(friend, cfg.Friends.SuggestionsRefreshInterval))
+	jobRunner.Register(jobs.StatusCleanup(status, blobs, jobs.StatusCleanupConfig{
+		Interval:         cfg.Status.CleanupInterval,
+		DeletedRetention: cfg.Status.DeletedRetention,
+		PurgeExpired:     cfg.Status.CleanupMode == "purge",
+	}))
+	if memorySearch != nil {
+		// Every instance keeps its own in-memory index.
+		jobRunner.Register(jobs.Job{
+			Name:     "rebuild search index",
+			Interval: cfg.Search.RebuildInterval,
+			Run:      memorySearch.Rebuild,
+		})
+	}
+	jobRunner.Start()
 
 	/* Start the HTTP server. */
 
-	if err := app.Start(":8080"); err != nil {
-		log.Fatal(err)
+	go func() {
+		slog.Info("http server started", slog.String("addr", cfg.App.Addr))
+		if err := app.Start(cfg.App.Addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("http server failed", err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	// A second signal kills the server right away.
+	stop()
+
+	/* Graceful shutdown. */
+
+	slog.Info("shutting down", slog.Duration("timeout", cfg.App.ShutdownTimeout))
+
+	// Report unready so the load balancers stop sending requests.
+	healthHandler.ShutDown()
+	time.Sleep(cfg.App.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
+	defer cancel()
+
+	// Stop accepting connections and drain the in-flight requests.
+	if err := app.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to drain the http server", slog.Any("error", err))
+	}
+
+	// Stop the background jobs and give up the leadership.
+	if err := jobRunner.Stop(shutdownCtx); err != nil {
+		slog.Error("failed to stop the background jobs", slog.Any("error", err))
+	}
+
+	// Send the last spans.
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("failed to flush the traces", slog.Any("error", err))
 	}
+
+	// The Cassandra session and the MySQL pool are closed by the deferred
+	// calls, in that order.
+}
+
+// fatal logs an error and exits, the deferred calls don't run.
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+)
+
+// migrateUsage is printed when the migrate command is misused.
+const migrateUsage = "usage: erochat migrate [mysql|cassandra] up|down [steps]|status"
+
+// storeMigrator is the migrator of a data store.
+type storeMigrator struct {
+	// name is the name of the data store.
+	name string
+
+	// migrator migrates the schema of the data store.
+	migrator interfaces.Migrator
+}
+
+// runMigrate runs the migrate command, `erochat migrate [store] up|down [steps]|status`.
+//
+// Without a store, up and status run on every store in order, down needs a
+// store since the versions of the stores are unrelated.
+func runMigrate(ctx context.Context, migrators []storeMigrator, args []string) error {
+	if len(args) > 0 {
+		for _, m := range migrators {
+			if args[0] == m.name {
+				migrators, args = []storeMigrator{m}, args[1:]
+				break
+			}
+		}
+	}
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		for _, m := range migrators {
+			applied, err := m.migrator.Up(ctx)
+			for _, s := range applied {
+				fmt.Printf("%s: applied %d_%s\n", m.name, s.Version, s.Name)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", m.name, err)
+			}
+			if len(applied) == 0 {
+				fmt.Printf("%s: no pending migrations\n", m.name)
+			}
+		}
+		return nil
+
+	case "down":
+		if len(migrators) != 1 {
+			return errors.New("down needs a store, " + migrateUsage)
+		}
+
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return errors.New("steps must be a positive number")
+			}
+			steps = n
+		}
+
+		rolledBack, err := migrators[0].migrator.Down(ctx, steps)
+		for _, s := range rolledBack {
+			fmt.Printf("%s: rolled back %d_%s\n", migrators[0].name, s.Version, s.Name)
+		}
+		return err
+
+	case "status":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "STORE\tVERSION\tNAME\tSTATE\tAPPLIED AT")
+		for _, m := range migrators {
+			statuses, err := m.migrator.Status(ctx)
+			if err != nil {
+				return fmt.Errorf("%s: %w", m.name, err)
+			}
+			printMigrationStatus(w, m.name, statuses)
+		}
+		return w.Flush()
+	}
+
+	return errors.New(migrateUsage)
+}
+
+// migrateOnStart applies the pending migrations of every store before serving.
+func migrateOnStart(ctx context.Context, migrators []storeMigrator) error {
+	for _, m := range migrators {
+		applied, err := m.migrator.Up(ctx)
+		for _, s := range applied {
+			slog.Info("applied migration", slog.String("store", m.name), slog.Int64("version", s.Version), slog.String("name", s.Name))
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+	}
+	return nil
+}
+
+// printMigrationStatus prints the migrations of a store as table rows.
+func printMigrationStatus(w *tabwriter.Writer, store string, statuses []types.MigrationStatus) {
+	for _, s := range statuses {
+		appliedAt := "-"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", store, s.Version, s.Name, s.State, appliedAt)
+	}
+}
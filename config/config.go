@@ -0,0 +1,479 @@
+// Package config loads the typed configuration of the server.
+//
+// Every setting has a default, and can be overridden by an optional JSON
+// config file, then by the environment (including a .env file), then by the
+// command line flags. Settings are described by the struct tags of their
+// fields: json is the key in the config file, env the environment variable,
+// and the flag is the lowercase variable name with dashes. Fields tagged
+// secret are redacted when the configuration is printed.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// EnvProduction is the production application environment, it hardens
+	// the defaults.
+	EnvProduction = "production"
+
+	// EnvDevelopment is the development application environment.
+	EnvDevelopment = "development"
+)
+
+// Config is the configuration of the server.
+type Config struct {
+	App        AppConfig        `json:"app"`
+	Log        LogConfig        `json:"log"`
+	Tracing    TracingConfig    `json:"tracing"`
+	MySQL      MySQLConfig      `json:"mysql"`
+	Cassandra  CassandraConfig  `json:"cassandra"`
+	Migrations MigrationsConfig `json:"migrations"`
+	Auth       AuthConfig       `json:"auth"`
+	Cookie     CookieConfig     `json:"cookie"`
+	CORS       CORSConfig       `json:"cors"`
+	Audit      AuditConfig      `json:"audit"`
+	Friends    FriendsConfig    `json:"friends"`
+	Status     StatusConfig     `json:"status"`
+	Media      MediaConfig      `json:"media"`
+	Jobs       JobsConfig       `json:"jobs"`
+	Search     SearchConfig     `json:"search"`
+}
+
+// AppConfig is the configuration of the application.
+type AppConfig struct {
+	// Env is the application environment, development or production.
+	Env string `json:"env" env:"APP_ENV" usage:"application environment, development or production"`
+
+	// Addr is the address the HTTP server listens on.
+	Addr string `json:"addr" env:"APP_ADDR" usage:"address the HTTP server listens on"`
+
+	// ShutdownTimeout is how long the in-flight requests and the running
+	// jobs are waited for on shutdown.
+	ShutdownTimeout time.Duration `json:"shutdown_timeout" env:"APP_SHUTDOWN_TIMEOUT" usage:"how long in-flight requests and jobs are waited for on shutdown"`
+
+	// ShutdownDelay is how long the instance reports unready before it stops
+	// accepting connections, so the load balancers take it out first.
+	ShutdownDelay time.Duration `json:"shutdown_delay" env:"APP_SHUTDOWN_DELAY" usage:"how long the instance reports unready before it stops accepting connections"`
+
+	// RequestTimeout is the deadline of the requests, the database calls of
+	// a request are canceled once it passes.
+	RequestTimeout time.Duration `json:"request_timeout" env:"APP_REQUEST_TIMEOUT" usage:"deadline of the requests"`
+
+	// RouteTimeouts overrides the deadline of the route groups, as
+	// prefix=duration pairs like /api/v1/admin=2m. The longest matching
+	// prefix applies.
+	RouteTimeouts []string `json:"route_timeouts" env:"APP_ROUTE_TIMEOUTS" usage:"deadlines of the route groups, comma separated prefix=duration pairs"`
+
+	// HealthCheckTimeout bounds every dependency check of the readiness.
+	HealthCheckTimeout time.Duration `json:"health_check_timeout" env:"APP_HEALTH_CHECK_TIMEOUT" usage:"timeout of every readiness dependency check"`
+}
+
+// LogConfig is the configuration of the logs.
+type LogConfig struct {
+	// Level is the minimum level logged, debug, info, warn or error.
+	Level string `json:"level" env:"LOG_LEVEL" usage:"minimum log level, debug, info, warn or error"`
+
+	// Format is the format of the logs, json or text.
+	Format string `json:"format" env:"LOG_FORMAT" usage:"log format, json or text"`
+}
+
+// TracingConfig is the configuration of the OpenTelemetry traces.
+type TracingConfig struct {
+	// Exporter is where the spans are sent, none, stdout or otlp.
+	Exporter string `json:"exporter" env:"TRACING_EXPORTER" usage:"trace exporter, none, stdout or otlp"`
+
+	// Endpoint is the host and port of the OTLP HTTP collector, the
+	// OTEL_EXPORTER_OTLP_* variables apply when it's empty.
+	Endpoint string `json:"endpoint" env:"TRACING_OTLP_ENDPOINT" usage:"host:port of the OTLP HTTP collector"`
+
+	// Insecure sends the spans to the collector over plain HTTP.
+	Insecure bool `json:"insecure" env:"TRACING_OTLP_INSECURE" usage:"send the spans to the OTLP collector without TLS"`
+
+	// ServiceName is the service name of the spans.
+	ServiceName string `json:"service_name" env:"TRACING_SERVICE_NAME" usage:"service name of the spans"`
+
+	// SampleRatio is the ratio of the traces started here that are sampled,
+	// the decision of an incoming traceparent is kept.
+	SampleRatio float64 `json:"sample_ratio" env:"TRACING_SAMPLE_RATIO" usage:"ratio of the new traces sampled, from 0 to 1"`
+}
+
+// MySQLConfig is the configuration of the MySQL database.
+type MySQLConfig struct {
+	// DSN is the data source name of the database.
+	DSN string `json:"dsn" env:"MYSQL_DSN" secret:"true" usage:"MySQL data source name"`
+
+	// ReplicaDSNs are the data source names of the read replicas, the
+	// reads are spread over them.
+	ReplicaDSNs []string `json:"replica_dsns" env:"MYSQL_REPLICA_DSNS" secret:"true" usage:"comma separated MySQL read replica data source names"`
+
+	// MaxConnections is the maximum number of open connections of every
+	// database, the queries wait for a free connection beyond it.
+	MaxConnections int `json:"max_connections" env:"MYSQL_MAX_CONNECTIONS" usage:"maximum number of MySQL connections"`
+
+	// MaxIdleConnections is the maximum number of idle connections kept
+	// open for every database, 0 keeps up to MaxConnections. It's capped
+	// at MaxConnections.
+	MaxIdleConnections int `json:"max_idle_connections" env:"MYSQL_MAX_IDLE_CONNECTIONS" usage:"maximum number of idle MySQL connections, 0 for MYSQL_MAX_CONNECTIONS"`
+
+	// ConnMaxLifetime is how long a connection is reused, 0 reuses it
+	// forever.
+	ConnMaxLifetime time.Duration `json:"conn_max_lifetime" env:"MYSQL_CONN_MAX_LIFETIME" usage:"how long a MySQL connection is reused"`
+
+	// ConnMaxIdleTime is how long a connection stays idle before it's
+	// closed, 0 keeps it.
+	ConnMaxIdleTime time.Duration `json:"conn_max_idle_time" env:"MYSQL_CONN_MAX_IDLE_TIME" usage:"how long an idle MySQL connection is kept"`
+}
+
+// CassandraConfig is the configuration of the Cassandra cluster.
+type CassandraConfig struct {
+	// Host is the address of a node of the cluster.
+	Host string `json:"host" env:"CASSANDRA_HOST" usage:"Cassandra host"`
+
+	// Username is the user of the cluster.
+	Username string `json:"username" env:"CASSANDRA_USERNAME" usage:"Cassandra username"`
+
+	// Password is the password of the user.
+	Password string `json:"password" env:"CASSANDRA_PASSWORD" secret:"true" usage:"Cassandra password"`
+
+	// Keyspace is the keyspace of the tables.
+	Keyspace string `json:"keyspace" env:"CASSANDRA_KEYSPACE" usage:"Cassandra keyspace"`
+
+	// ReplicationStrategy is the replication strategy of the keyspace when
+	// the migrations create it, SimpleStrategy or NetworkTopologyStrategy.
+	ReplicationStrategy string `json:"replication_strategy" env:"CASSANDRA_REPLICATION_STRATEGY" usage:"keyspace replication strategy, SimpleStrategy or NetworkTopologyStrategy"`
+
+	// ReplicationFactor is the replication factor with SimpleStrategy.
+	ReplicationFactor int `json:"replication_factor" env:"CASSANDRA_REPLICATION_FACTOR" usage:"keyspace replication factor with SimpleStrategy"`
+
+	// DataCenters is the comma separated datacenter:factor pairs with
+	// NetworkTopologyStrategy.
+	DataCenters string `json:"datacenters" env:"CASSANDRA_DATACENTERS" usage:"comma separated datacenter:factor pairs with NetworkTopologyStrategy"`
+}
+
+// MigrationsConfig is the configuration of the schema migrations.
+type MigrationsConfig struct {
+	// OnStart applies the pending migrations before serving.
+	OnStart bool `json:"on_start" env:"MIGRATE_ON_START" usage:"apply the pending schema migrations on start"`
+
+	// Timeout bounds the migrations applied on start or by the migrate
+	// command, lock waits included.
+	Timeout time.Duration `json:"timeout" env:"MIGRATE_TIMEOUT" usage:"maximum duration of the schema migrations"`
+}
+
+// AuthConfig is the configuration of the authentication.
+type AuthConfig struct {
+	// PrivateKeyFile is the RSA private key signing the tokens.
+	PrivateKeyFile string `json:"private_key_file" env:"AUTH_PRIVATE_KEY_FILE" usage:"RSA private key signing the tokens"`
+
+	// PublicKeyFile is the RSA public key verifying the tokens.
+	PublicKeyFile string `json:"public_key_file" env:"AUTH_PUBLIC_KEY_FILE" usage:"RSA public key verifying the tokens"`
+
+	// AccessTokenTTL is the lifetime of the access tokens and their cookie.
+	AccessTokenTTL time.Duration `json:"access_token_ttl" env:"AUTH_ACCESS_TOKEN_TTL" usage:"lifetime of the access tokens"`
+
+	// RefreshTokenTTL is the lifetime of the refresh tokens.
+	RefreshTokenTTL time.Duration `json:"refresh_token_ttl" env:"AUTH_REFRESH_TOKEN_TTL" usage:"lifetime of the refresh tokens"`
+
+	// Scrypt is the password hashing configuration.
+	Scrypt ScryptConfig `json:"scrypt"`
+}
+
+// ScryptConfig is the configuration of the password hashing.
+type ScryptConfig struct {
+	// N is the CPU/memory cost parameter, a power of two.
+	N int `json:"n" env:"SCRYPT_N" usage:"scrypt CPU/memory cost, a power of two"`
+
+	// R is the block size parameter.
+	R int `json:"r" env:"SCRYPT_R" usage:"scrypt block size"`
+
+	// P is the parallelization parameter.
+	P int `json:"p" env:"SCRYPT_P" usage:"scrypt parallelization"`
+
+	// KeyLen is the length of the derived key.
+	KeyLen int `json:"key_len" env:"SCRYPT_KEY_LEN" usage:"scrypt derived key length"`
+
+	// SaltLen is the length of the salt.
+	SaltLen int `json:"salt_len" env:"SCRYPT_SALT_LEN" usage:"scrypt salt length"`
+}
+
+// CookieConfig is the configuration of the auth cookies.
+type CookieConfig struct {
+	// Domain is the domain the cookies are scoped to.
+	Domain string `json:"domain" env:"COOKIE_DOMAIN" usage:"domain the auth cookies are scoped to"`
+
+	// Secure restricts the cookies to HTTPS, by default in production.
+	Secure *bool `json:"secure" env:"COOKIE_SECURE" usage:"restrict the auth cookies to HTTPS (default true in production)"`
+
+	// SameSite is the SameSite mode of the cookies, strict, lax or none,
+	// strict by default in production.
+	SameSite string `json:"samesite" env:"COOKIE_SAMESITE" usage:"SameSite mode of the auth cookies, strict, lax or none"`
+}
+
+// CORSConfig is the configuration of the cross-origin requests.
+type CORSConfig struct {
+	// AllowOrigins is the origins allowed to make credentialed requests.
+	AllowOrigins []string `json:"allow_origins" env:"CORS_ALLOW_ORIGINS" usage:"comma separated origins allowed by CORS"`
+}
+
+// AuditConfig is the configuration of the audit log.
+type AuditConfig struct {
+	// Backend is the store of the audit log, mysql or cassandra.
+	Backend string `json:"backend" env:"AUDIT_BACKEND" usage:"audit log backend, mysql or cassandra"`
+}
+
+// FriendsConfig is the configuration of the friendships.
+type FriendsConfig struct {
+	// DeclineCooldown is how long a sender can't send a new request after
+	// being declined.
+	DeclineCooldown time.Duration `json:"decline_cooldown" env:"FRIEND_REQUEST_DECLINE_COOLDOWN" usage:"how long a declined sender can't send a new request"`
+
+	// SuggestionsRefreshInterval is how often the friend suggestions are
+	// precomputed.
+	SuggestionsRefreshInterval time.Duration `json:"suggestions_refresh_interval" env:"FRIEND_SUGGESTIONS_REFRESH_INTERVAL" usage:"how often friend suggestions are precomputed"`
+}
+
+// StatusConfig is the configuration of the statuses.
+type StatusConfig struct {
+	// TTL is how long a status is shown before it moves to the owner's archive.
+	TTL time.Duration `json:"ttl" env:"STATUS_TTL" usage:"how long a status is shown before it's archived"`
+
+	// CleanupMode keeps the expired statuses in the owner's archive or
+	// purges them, archive or purge.
+	CleanupMode string `json:"cleanup_mode" env:"STATUS_CLEANUP_MODE" usage:"expired statuses are archived or purged, archive or purge"`
+
+	// CleanupInterval is how often the statuses are purged.
+	CleanupInterval time.Duration `json:"cleanup_interval" env:"STATUS_CLEANUP_INTERVAL" usage:"how often statuses are purged"`
+
+	// DeletedRetention is how long the deleted statuses are kept.
+	DeletedRetention time.Duration `json:"deleted_retention" env:"STATUS_DELETED_RETENTION" usage:"how long deleted statuses are kept"`
+}
+
+// MediaConfig is the configuration of the uploaded media.
+type MediaConfig struct {
+	// Dir is the directory of the uploaded media, nothing is deleted when
+	// it's empty.
+	Dir string `json:"dir" env:"MEDIA_DIR" usage:"directory of the uploaded media"`
+
+	// BaseURL is the url the media is served under.
+	BaseURL string `json:"base_url" env:"MEDIA_BASE_URL" usage:"url the media is served under"`
+}
+
+// JobsConfig is the configuration of the background jobs.
+type JobsConfig struct {
+	// Jitter is the maximum random delay added to the job intervals.
+	Jitter time.Duration `json:"jitter" env:"JOBS_JITTER" usage:"maximum random delay added to the job intervals"`
+}
+
+// SearchConfig is the configuration of the user search.
+type SearchConfig struct {
+	// Backend is the user search backend, mysql or memory.
+	Backend string `json:"backend" env:"SEARCH_BACKEND" usage:"user search backend, mysql or memory"`
+
+	// RebuildInterval is how often the in-memory index is rebuilt.
+	RebuildInterval time.Duration `json:"rebuild_interval" env:"SEARCH_REBUILD_INTERVAL" usage:"how often the in-memory search index is rebuilt"`
+}
+
+// Default returns the default configuration.
+func Default() *Config {
+	return &Config{
+		App: AppConfig{
+			Env:                EnvDevelopment,
+			Addr:               ":8080",
+			ShutdownTimeout:    time.Second * 30,
+			HealthCheckTimeout: time.Second * 2,
+			RequestTimeout:     time.Second * 15,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "erochat",
+			SampleRatio: 1,
+		},
+		MySQL: MySQLConfig{
+			MaxConnections:  10,
+			ConnMaxLifetime: time.Minute * 30,
+			ConnMaxIdleTime: time.Minute * 5,
+		},
+		Migrations: MigrationsConfig{
+			Timeout: time.Minute * 5,
+		},
+		Cassandra: CassandraConfig{
+			Keyspace:            "erochat",
+			ReplicationStrategy: "SimpleStrategy",
+			ReplicationFactor:   1,
+		},
+		Auth: AuthConfig{
+			PrivateKeyFile:  "certs/app.rsa.key",
+			PublicKeyFile:   "certs/app.rsa.pub",
+			AccessTokenTTL:  time.Hour * 24,
+			RefreshTokenTTL: time.Hour * 24 * 7,
+			Scrypt: ScryptConfig{
+				N:       1 << 14,
+				R:       8,
+				P:       1,
+				KeyLen:  32,
+				SaltLen: 22,
+			},
+		},
+		Audit: AuditConfig{
+			Backend: "mysql",
+		},
+		Friends: FriendsConfig{
+			DeclineCooldown:            time.Hour * 24 * 7,
+			SuggestionsRefreshInterval: time.Hour,
+		},
+		Status: StatusConfig{
+			TTL:              time.Hour * 24,
+			CleanupMode:      "archive",
+			CleanupInterval:  time.Hour,
+			DeletedRetention: time.Hour * 24 * 7,
+		},
+		Jobs: JobsConfig{
+			Jitter: time.Minute,
+		},
+		Search: SearchConfig{
+			Backend:         "mysql",
+			RebuildInterval: time.Minute * 15,
+		},
+	}
+}
+
+// Production reports whether the application runs in production.
+func (c *Config) Production() bool {
+	return c.App.Env == EnvProduction
+}
+
+// CookieSecure reports whether the auth cookies are restricted to HTTPS.
+func (c *Config) CookieSecure() bool {
+	if c.Cookie.Secure != nil {
+		return *c.Cookie.Secure
+	}
+	return c.Production()
+}
+
+// CookieSameSite returns the SameSite mode of the auth cookies.
+func (c *Config) CookieSameSite() string {
+	if c.Cookie.SameSite == "" && c.Production() {
+		return "strict"
+	}
+	return c.Cookie.SameSite
+}
+
+// MySQLMaxIdleConnections returns the maximum number of idle connections of
+// every MySQL database, MaxConnections when unset or above it.
+func (c *Config) MySQLMaxIdleConnections() int {
+	if c.MySQL.MaxIdleConnections == 0 || c.MySQL.MaxIdleConnections > c.MySQL.MaxConnections {
+		return c.MySQL.MaxConnections
+	}
+	return c.MySQL.MaxIdleConnections
+}
+
+// RouteTimeouts returns the deadlines of the route groups by route prefix.
+func (c *Config) RouteTimeouts() (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration, len(c.App.RouteTimeouts))
+	for _, pair := range c.App.RouteTimeouts {
+		prefix, v, ok := strings.Cut(pair, "=")
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if !ok || err != nil || d <= 0 || !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("APP_ROUTE_TIMEOUTS: expected /prefix=duration, got %q", pair)
+		}
+		timeouts[strings.TrimSpace(prefix)] = d
+	}
+	return timeouts, nil
+}
+
+// Logger returns a logger writing to w with the configured level and format.
+func (c *Config) Logger(w io.Writer) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		level = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	if c.Log.Format == "text" {
+		return slog.New(slog.NewTextHandler(w, opts))
+	}
+	return slog.New(slog.NewJSONHandler(w, opts))
+}
+
+// Validate reports every invalid setting.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.App.Env == EnvDevelopment || c.App.Env == EnvProduction, "APP_ENV must be %s or %s", EnvDevelopment, EnvProduction)
+	check(c.App.Addr != "", "APP_ADDR is required")
+	check(c.App.ShutdownTimeout > 0, "APP_SHUTDOWN_TIMEOUT must be positive")
+	check(c.Migrations.Timeout > 0, "MIGRATE_TIMEOUT must be positive")
+	check(c.App.ShutdownDelay >= 0, "APP_SHUTDOWN_DELAY can't be negative")
+	check(c.App.HealthCheckTimeout > 0, "APP_HEALTH_CHECK_TIMEOUT must be positive")
+	check(c.App.RequestTimeout > 0, "APP_REQUEST_TIMEOUT must be positive")
+	if _, err := c.RouteTimeouts(); err != nil {
+		errs = append(errs, err)
+	}
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "unknown LOG_LEVEL %s", c.Log.Level)
+	check(c.Log.Format == "json" || c.Log.Format == "text", "unknown LOG_FORMAT %s", c.Log.Format)
+
+	check(c.Tracing.Exporter == "none" || c.Tracing.Exporter == "stdout" || c.Tracing.Exporter == "otlp", "unknown TRACING_EXPORTER %s", c.Tracing.Exporter)
+	check(c.Tracing.ServiceName != "", "TRACING_SERVICE_NAME is required")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+
+	check(c.MySQL.DSN != "", "MYSQL_DSN is required")
+	check(c.MySQL.MaxConnections > 0, "MYSQL_MAX_CONNECTIONS must be positive")
+	check(c.MySQL.MaxIdleConnections >= 0, "MYSQL_MAX_IDLE_CONNECTIONS can't be negative")
+	check(c.MySQL.ConnMaxLifetime >= 0, "MYSQL_CONN_MAX_LIFETIME can't be negative")
+	check(c.MySQL.ConnMaxIdleTime >= 0, "MYSQL_CONN_MAX_IDLE_TIME can't be negative")
+
+	check(c.Cassandra.Host != "", "CASSANDRA_HOST is required")
+	check(c.Cassandra.Keyspace != "", "CASSANDRA_KEYSPACE is required")
+	check(c.Cassandra.ReplicationStrategy == "SimpleStrategy" || c.Cassandra.ReplicationStrategy == "NetworkTopologyStrategy", "unknown CASSANDRA_REPLICATION_STRATEGY %s", c.Cassandra.ReplicationStrategy)
+
+	check(c.Auth.PrivateKeyFile != "" && c.Auth.PublicKeyFile != "", "AUTH_PRIVATE_KEY_FILE and AUTH_PUBLIC_KEY_FILE are required")
+	check(c.Auth.AccessTokenTTL > 0, "AUTH_ACCESS_TOKEN_TTL must be positive")
+	check(c.Auth.RefreshTokenTTL >= c.Auth.AccessTokenTTL, "AUTH_REFRESH_TOKEN_TTL must be at least AUTH_ACCESS_TOKEN_TTL")
+
+	s := c.Auth.Scrypt
+	check(s.N > 1 && s.N&(s.N-1) == 0, "SCRYPT_N must be a power of two greater than 1")
+	check(s.R > 0 && s.P > 0 && s.R*s.P < 1<<30, "SCRYPT_R and SCRYPT_P must be positive and r*p < 2^30")
+	check(s.KeyLen >= 16, "SCRYPT_KEY_LEN must be at least 16")
+	check(s.SaltLen >= 16, "SCRYPT_SALT_LEN must be at least 16")
+
+	sameSite := c.Cookie.SameSite
+	check(sameSite == "" || sameSite == "strict" || sameSite == "lax" || sameSite == "none", "unknown COOKIE_SAMESITE %s", sameSite)
+	check(c.CookieSameSite() != "none" || c.CookieSecure(), "COOKIE_SAMESITE none requires COOKIE_SECURE, browsers reject insecure SameSite=None cookies")
+	check(len(c.CORS.AllowOrigins) > 0 || !c.Production(), "CORS_ALLOW_ORIGINS is required in production")
+
+	check(c.Audit.Backend == "mysql" || c.Audit.Backend == "cassandra", "unknown AUDIT_BACKEND %s", c.Audit.Backend)
+
+	check(c.Friends.DeclineCooldown >= 0, "FRIEND_REQUEST_DECLINE_COOLDOWN can't be negative")
+	check(c.Friends.SuggestionsRefreshInterval > 0, "FRIEND_SUGGESTIONS_REFRESH_INTERVAL must be positive")
+
+	check(c.Status.TTL >= time.Second, "STATUS_TTL must be at least a second")
+	check(c.Status.CleanupMode == "archive" || c.Status.CleanupMode == "purge", "unknown STATUS_CLEANUP_MODE %s", c.Status.CleanupMode)
+	check(c.Status.CleanupInterval > 0, "STATUS_CLEANUP_INTERVAL must be positive")
+	check(c.Status.DeletedRetention >= 0, "STATUS_DELETED_RETENTION can't be negative")
+
+	check(c.Media.Dir == "" || c.Media.BaseURL != "", "MEDIA_BASE_URL is required with MEDIA_DIR")
+
+	check(c.Jobs.Jitter >= 0, "JOBS_JITTER can't be negative")
+
+	check(c.Search.Backend == "mysql" || c.Search.Backend == "memory", "unknown SEARCH_BACKEND %s", c.Search.Backend)
+	check(c.Search.RebuildInterval > 0, "SEARCH_REBUILD_INTERVAL must be positive")
+
+	return errors.Join(errs...)
+}
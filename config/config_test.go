@@ -0,0 +1,178 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig returns the defaults with the required settings.
+func validConfig() *Config {
+	cfg := Default()
+	cfg.MySQL.DSN = "erochat:erochat@tcp(localhost:3306)/erochat"
+	cfg.Cassandra.Host = "localhost"
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("the valid configuration fails: %v", err)
+	}
+
+	production := func(c *Config) {
+		c.App.Env = EnvProduction
+		c.CORS.AllowOrigins = []string{"https://erochat.example"}
+	}
+	insecure := false
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		error  string
+	}{
+		{"env", func(c *Config) { c.App.Env = "staging" }, "APP_ENV"},
+		{"addr", func(c *Config) { c.App.Addr = "" }, "APP_ADDR"},
+		{"shutdown timeout", func(c *Config) { c.App.ShutdownTimeout = 0 }, "APP_SHUTDOWN_TIMEOUT"},
+		{"shutdown delay", func(c *Config) { c.App.ShutdownDelay = -time.Second }, "APP_SHUTDOWN_DELAY"},
+		{"health check timeout", func(c *Config) { c.App.HealthCheckTimeout = 0 }, "APP_HEALTH_CHECK_TIMEOUT"},
+		{"request timeout", func(c *Config) { c.App.RequestTimeout = -time.Second }, "APP_REQUEST_TIMEOUT"},
+		{"route timeout without prefix", func(c *Config) { c.App.RouteTimeouts = []string{"api=1m"} }, "APP_ROUTE_TIMEOUTS"},
+		{"route timeout without duration", func(c *Config) { c.App.RouteTimeouts = []string{"/api/v1/admin"} }, "APP_ROUTE_TIMEOUTS"},
+		{"route timeout not positive", func(c *Config) { c.App.RouteTimeouts = []string{"/api/v1/admin=0s"} }, "APP_ROUTE_TIMEOUTS"},
+		{"migrate timeout", func(c *Config) { c.Migrations.Timeout = 0 }, "MIGRATE_TIMEOUT"},
+		{"log level", func(c *Config) { c.Log.Level = "verbose" }, "LOG_LEVEL"},
+		{"log format", func(c *Config) { c.Log.Format = "xml" }, "LOG_FORMAT"},
+		{"tracing exporter", func(c *Config) { c.Tracing.Exporter = "jaeger" }, "TRACING_EXPORTER"},
+		{"tracing service name", func(c *Config) { c.Tracing.ServiceName = "" }, "TRACING_SERVICE_NAME"},
+		{"tracing sample ratio", func(c *Config) { c.Tracing.SampleRatio = 1.5 }, "TRACING_SAMPLE_RATIO"},
+		{"mysql dsn", func(c *Config) { c.MySQL.DSN = "" }, "MYSQL_DSN"},
+		{"mysql max connections", func(c *Config) { c.MySQL.MaxConnections = 0 }, "MYSQL_MAX_CONNECTIONS"},
+		{"mysql idle connections negative", func(c *Config) { c.MySQL.MaxIdleConnections = -1 }, "MYSQL_MAX_IDLE_CONNECTIONS"},
+		{"mysql conn max lifetime", func(c *Config) { c.MySQL.ConnMaxLifetime = -time.Second }, "MYSQL_CONN_MAX_LIFETIME"},
+		{"mysql conn max idle time", func(c *Config) { c.MySQL.ConnMaxIdleTime = -time.Second }, "MYSQL_CONN_MAX_IDLE_TIME"},
+		{"cassandra host", func(c *Config) { c.Cassandra.Host = "" }, "CASSANDRA_HOST"},
+		{"cassandra keyspace", func(c *Config) { c.Cassandra.Keyspace = "" }, "CASSANDRA_KEYSPACE"},
+		{"cassandra replication", func(c *Config) { c.Cassandra.ReplicationStrategy = "LocalStrategy" }, "CASSANDRA_REPLICATION_STRATEGY"},
+		{"auth keys", func(c *Config) { c.Auth.PublicKeyFile = "" }, "AUTH_PRIVATE_KEY_FILE"},
+		{"access token ttl", func(c *Config) { c.Auth.AccessTokenTTL = 0 }, "AUTH_ACCESS_TOKEN_TTL"},
+		{"refresh token ttl", func(c *Config) { c.Auth.RefreshTokenTTL = c.Auth.AccessTokenTTL - time.Second }, "AUTH_REFRESH_TOKEN_TTL"},
+		{"scrypt n", func(c *Config) { c.Auth.Scrypt.N = 1000 }, "SCRYPT_N"},
+		{"scrypt r", func(c *Config) { c.Auth.Scrypt.R = 0 }, "SCRYPT_R"},
+		{"scrypt r*p", func(c *Config) { c.Auth.Scrypt.R, c.Auth.Scrypt.P = 1<<15, 1<<15 }, "SCRYPT_R"},
+		{"scrypt key length", func(c *Config) { c.Auth.Scrypt.KeyLen = 8 }, "SCRYPT_KEY_LEN"},
+		{"scrypt salt length", func(c *Config) { c.Auth.Scrypt.SaltLen = 8 }, "SCRYPT_SALT_LEN"},
+		{"cookie samesite", func(c *Config) { c.Cookie.SameSite = "loose" }, "unknown COOKIE_SAMESITE"},
+		{"cookie samesite none", func(c *Config) { c.Cookie.SameSite = "none" }, "COOKIE_SAMESITE none requires COOKIE_SECURE"},
+		{"cookie samesite none insecure in production", func(c *Config) {
+			production(c)
+			c.Cookie.SameSite = "none"
+			c.Cookie.Secure = &insecure
+		}, "COOKIE_SAMESITE none requires COOKIE_SECURE"},
+		{"cors in production", func(c *Config) {
+			production(c)
+			c.CORS.AllowOrigins = nil
+		}, "CORS_ALLOW_ORIGINS"},
+		{"audit backend", func(c *Config) { c.Audit.Backend = "file" }, "AUDIT_BACKEND"},
+		{"decline cooldown", func(c *Config) { c.Friends.DeclineCooldown = -time.Second }, "FRIEND_REQUEST_DECLINE_COOLDOWN"},
+		{"suggestions interval", func(c *Config) { c.Friends.SuggestionsRefreshInterval = 0 }, "FRIEND_SUGGESTIONS_REFRESH_INTERVAL"},
+		{"status ttl", func(c *Config) { c.Status.TTL = time.Millisecond }, "STATUS_TTL"},
+		{"status cleanup mode", func(c *Config) { c.Status.CleanupMode = "delete" }, "STATUS_CLEANUP_MODE"},
+		{"status cleanup interval", func(c *Config) { c.Status.CleanupInterval = 0 }, "STATUS_CLEANUP_INTERVAL"},
+		{"status deleted retention", func(c *Config) { c.Status.DeletedRetention = -time.Second }, "STATUS_DELETED_RETENTION"},
+		{"media base url", func(c *Config) { c.Media.Dir = "/var/lib/erochat/media" }, "MEDIA_BASE_URL"},
+		{"jobs jitter", func(c *Config) { c.Jobs.Jitter = -time.Second }, "JOBS_JITTER"},
+		{"search backend", func(c *Config) { c.Search.Backend = "elastic" }, "SEARCH_BACKEND"},
+		{"search rebuild interval", func(c *Config) { c.Search.RebuildInterval = 0 }, "SEARCH_REBUILD_INTERVAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("got %v, want an error containing %q", err, tt.error)
+			}
+		})
+	}
+}
+
+func TestValidateValid(t *testing.T) {
+	secure := true
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+	}{
+		{"production", func(c *Config) {
+			c.App.Env = EnvProduction
+			c.CORS.AllowOrigins = []string{"https://erochat.example"}
+		}},
+		{"production samesite none", func(c *Config) {
+			c.App.Env = EnvProduction
+			c.CORS.AllowOrigins = []string{"https://erochat.example"}
+			c.Cookie.SameSite = "none"
+		}},
+		{"secure samesite none", func(c *Config) {
+			c.Cookie.SameSite = "none"
+			c.Cookie.Secure = &secure
+		}},
+		{"max connections below the default idle", func(c *Config) { c.MySQL.MaxConnections = 5 }},
+		{"idle connections over max", func(c *Config) { c.MySQL.MaxIdleConnections = c.MySQL.MaxConnections + 1 }},
+		{"route timeouts", func(c *Config) { c.App.RouteTimeouts = []string{"/api/v1/admin=2m", "/api/v1/media=30s"} }},
+		{"media", func(c *Config) {
+			c.Media.Dir = "/var/lib/erochat/media"
+			c.Media.BaseURL = "https://cdn.erochat.example/media"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEvery(t *testing.T) {
+	cfg := validConfig()
+	cfg.MySQL.DSN = ""
+	cfg.Cassandra.Host = ""
+	cfg.Log.Format = "xml"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("got no error")
+	}
+	for _, name := range []string{"MYSQL_DSN", "CASSANDRA_HOST", "LOG_FORMAT"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("%s isn't reported in %v", name, err)
+		}
+	}
+}
+
+func TestMySQLMaxIdleConnections(t *testing.T) {
+	tests := []struct {
+		name      string
+		max, idle int
+		want      int
+	}{
+		{"unset", 5, 0, 5},
+		{"below max", 20, 4, 4},
+		{"max", 20, 20, 20},
+		{"above max", 5, 10, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.MySQL.MaxConnections, cfg.MySQL.MaxIdleConnections = tt.max, tt.idle
+
+			if got := cfg.MySQLMaxIdleConnections(); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	// fileEnv is the environment variable of the config file.
+	fileEnv = "CONFIG_FILE"
+
+	// fileFlag is the flag of the config file.
+	fileFlag = "config"
+
+	// redacted replaces the secrets when the configuration is printed.
+	redacted = "[REDACTED]"
+)
+
+// setting is a leaf field of the configuration.
+type setting struct {
+	// path is the json keys of the field from the root.
+	path []string
+
+	// env is the environment variable of the field.
+	env string
+
+	// secret redacts the field when the configuration is printed.
+	secret bool
+
+	// usage describes the field in the flags help.
+	usage string
+
+	// value is the field.
+	value reflect.Value
+}
+
+// flagName returns the command line flag of the setting.
+func (s *setting) flagName() string {
+	return strings.ReplaceAll(strings.ToLower(s.env), "_", "-")
+}
+
+// Load loads the configuration from the defaults, the config file, the
+// environment and the command line arguments, in that order of precedence.
+//
+// A missing .env file is ignored. The arguments left after the flags are
+// returned, they are the command to run.
+func Load(args []string) (*Config, []string, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+
+	cfg := Default()
+	settings := cfg.settings()
+
+	// Flags are parsed first to find the config file, they're applied last.
+	flags := flag.NewFlagSet("erochat", flag.ContinueOnError)
+	file := flags.String(fileFlag, os.Getenv(fileEnv), "JSON config file")
+	values := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		values[s.flagName()] = flags.String(s.flagName(), "", s.usage+" ($"+s.env+")")
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *file != "" {
+		if err := cfg.loadFile(*file); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(s.env); ok && v != "" {
+			if err := setValue(s.value, v); err != nil {
+				return nil, nil, fmt.Errorf("config: %s: %w", s.env, err)
+			}
+		}
+	}
+
+	var err error
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if err == nil && f.Name == s.flagName() {
+				if e := setValue(s.value, *values[f.Name]); e != nil {
+					err = fmt.Errorf("config: -%s: %w", f.Name, e)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, flags.Args(), nil
+}
+
+// loadFile applies a JSON config file, its keys follow the json tags.
+func (c *Config) loadFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var root map[string]any
+	if err = json.NewDecoder(f).Decode(&root); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config: %s: %w", name, err)
+	}
+
+	for _, s := range c.settings() {
+		v, ok := lookup(root, s.path)
+		if !ok || v == nil {
+			continue
+		}
+
+		var raw string
+		switch v := v.(type) {
+		case string:
+			raw = v
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			raw = strings.Join(items, ",")
+		case float64:
+			raw = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			raw = fmt.Sprint(v)
+		}
+
+		if err = setValue(s.value, raw); err != nil {
+			return fmt.Errorf("config: %s: %s: %w", name, strings.Join(s.path, "."), err)
+		}
+	}
+	return nil
+}
+
+// lookup returns the value of a path of keys in a decoded JSON object.
+func lookup(root map[string]any, path []string) (any, bool) {
+	var v any = root
+	for _, key := range path {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// settings returns the leaf fields of the configuration.
+func (c *Config) settings() []*setting {
+	var settings []*setting
+
+	var walk func(v reflect.Value, path []string)
+	walk = func(v reflect.Value, path []string) {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			p := append(append([]string{}, path...), field.Tag.Get("json"))
+
+			env, ok := field.Tag.Lookup("env")
+			if !ok {
+				walk(v.Field(i), p)
+				continue
+			}
+
+			settings = append(settings, &setting{
+				path:   p,
+				env:    env,
+				secret: field.Tag.Get("secret") == "true",
+				usage:  field.Tag.Get("usage"),
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), nil)
+
+	return settings
+}
+
+// setValue parses a raw value into a field.
+func setValue(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	switch v.Interface().(type) {
+	case string:
+		v.SetString(raw)
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case *bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(&b))
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case []string:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// formatValue returns a field as a JSON value.
+func formatValue(v reflect.Value) any {
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return value.String()
+	case *bool:
+		if value == nil {
+			return nil
+		}
+		return *value
+	default:
+		return value
+	}
+}
+
+// Redacted returns the configuration as nested maps, with the secrets
+// redacted and the durations as strings.
+func (c *Config) Redacted() map[string]any {
+	root := map[string]any{}
+	for _, s := range c.settings() {
+		obj := root
+		for _, key := range s.path[:len(s.path)-1] {
+			next, ok := obj[key].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				obj[key] = next
+			}
+			obj = next
+		}
+
+		var value any = formatValue(s.value)
+		if s.secret && !s.value.IsZero() {
+			value = redacted
+		}
+		obj[s.path[len(s.path)-1]] = value
+	}
+	return root
+}
+
+// String returns the configuration as JSON with the secrets redacted, so it
+// can be logged.
+func (c *Config) String() string {
+	b, err := json.Marshal(c.Redacted())
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+// Print writes the configuration as indented JSON with the secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c.Redacted())
+}
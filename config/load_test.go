@@ -0,0 +1,184 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes a file in the test directory and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	file := writeFile(t, "config.json", `{
+		"app": {"addr": ":9000", "shutdown_timeout": "10s", "route_timeouts": ["/api/v1/admin=2m"]},
+		"log": {"level": "debug", "format": "text"},
+		"mysql": {"max_connections": 20, "dsn": null},
+		"cookie": {"secure": true}
+	}`)
+	t.Setenv("APP_ADDR", ":9001")
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("APP_REQUEST_TIMEOUT", "")
+
+	cfg, args, err := Load([]string{"-config", file, "-app-addr", ":9002", "migrate", "up"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"default", cfg.Tracing.ServiceName, "erochat"},
+		{"empty env keeps the default", cfg.App.RequestTimeout, time.Second * 15},
+		{"null in the file keeps the default", cfg.MySQL.DSN, ""},
+		{"file over default", cfg.Log.Format, "text"},
+		{"file duration", cfg.App.ShutdownTimeout, time.Second * 10},
+		{"file number", cfg.MySQL.MaxConnections, 20},
+		{"file list", cfg.App.RouteTimeouts, []string{"/api/v1/admin=2m"}},
+		{"file bool pointer", cfg.CookieSecure(), true},
+		{"env over file", cfg.Log.Level, "warn"},
+		{"flag over env", cfg.App.Addr, ":9002"},
+		{"arguments after the flags", args, []string{"migrate", "up"}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadFileFromEnv(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv(fileEnv, writeFile(t, "config.json", `{"search": {"backend": "memory"}, "cors": {"allow_origins": "https://a.example, https://b.example"}}`))
+
+	cfg, _, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Search.Backend != "memory" {
+		t.Errorf("got SEARCH_BACKEND %q, want the file's memory", cfg.Search.Backend)
+	}
+	if want := []string{"https://a.example", "https://b.example"}; !reflect.DeepEqual(cfg.CORS.AllowOrigins, want) {
+		t.Errorf("got CORS_ALLOW_ORIGINS %q, want %q", cfg.CORS.AllowOrigins, want)
+	}
+}
+
+func TestLoadDotEnv(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	cfg, _, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load without a .env file: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("got %s, want the defaults", cfg)
+	}
+
+	// godotenv sets the variables of the process, they're unset after.
+	t.Cleanup(func() { os.Unsetenv("STATUS_CLEANUP_MODE") })
+	if err = os.WriteFile(".env", []byte("STATUS_CLEANUP_MODE=purge\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, _, err = Load(nil); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Status.CleanupMode != "purge" {
+		t.Errorf("got STATUS_CLEANUP_MODE %q, want the .env's purge", cfg.Status.CleanupMode)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		args  []string
+		file  string
+		error string
+	}{
+		{name: "env", env: map[string]string{"MYSQL_MAX_CONNECTIONS": "ten"}, error: "MYSQL_MAX_CONNECTIONS"},
+		{name: "env duration", env: map[string]string{"STATUS_TTL": "1 day"}, error: "STATUS_TTL"},
+		{name: "flag", args: []string{"-cookie-secure", "maybe"}, error: "-cookie-secure"},
+		{name: "unknown flag", args: []string{"-no-such-flag"}, error: "no-such-flag"},
+		{name: "file value", file: `{"log": {"level": 3}, "app": {"shutdown_timeout": 5}}`, error: "app.shutdown_timeout"},
+		{name: "file syntax", file: `{"app": `, error: "config.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if tt.file != "" {
+				tt.args = append(tt.args, "-config", writeFile(t, "config.json", tt.file))
+			}
+
+			_, _, err := Load(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("got %v, want an error containing %q", err, tt.error)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		if _, _, err := Load([]string{"-config", "missing.json"}); !os.IsNotExist(err) {
+			t.Errorf("got %v, want a not exist error", err)
+		}
+	})
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.MySQL.DSN = "erochat:hunter2@tcp(db:3306)/erochat"
+	cfg.MySQL.ReplicaDSNs = []string{"erochat:hunter2@tcp(replica:3306)/erochat"}
+
+	redacted := cfg.Redacted()
+	mysql := redacted["mysql"].(map[string]any)
+	if mysql["dsn"] != "[REDACTED]" || mysql["replica_dsns"] != "[REDACTED]" {
+		t.Errorf("got dsn %v and replica_dsns %v, want them redacted", mysql["dsn"], mysql["replica_dsns"])
+	}
+	if password := redacted["cassandra"].(map[string]any)["password"]; password != "" {
+		t.Errorf("got the empty password as %v, want it empty", password)
+	}
+	if timeout := redacted["app"].(map[string]any)["shutdown_timeout"]; timeout != "30s" {
+		t.Errorf("got shutdown_timeout %v, want 30s", timeout)
+	}
+	if secure := redacted["cookie"].(map[string]any)["secure"]; secure != nil {
+		t.Errorf("got the unset cookie secure as %v, want null", secure)
+	}
+	if scrypt := redacted["auth"].(map[string]any)["scrypt"].(map[string]any); scrypt["n"] != 1<<14 {
+		t.Errorf("got scrypt n %v, want it nested", scrypt["n"])
+	}
+
+	for name, s := range map[string]string{"String": cfg.String(), "Print": printed(t, cfg)} {
+		if strings.Contains(s, "hunter2") {
+			t.Errorf("%s leaks the password: %s", name, s)
+		}
+	}
+}
+
+// printed returns the output of Print.
+func printed(t *testing.T, cfg *Config) string {
+	t.Helper()
+
+	var b strings.Builder
+	if err := cfg.Print(&b); err != nil {
+		t.Fatalf("Print: %v", err)
+	}
+	return b.String()
+}
@@ -0,0 +1,55 @@
+// Package blob contains the stores of the uploaded media.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore is a blob store on the local filesystem, the files are served
+// under a public base url.
+type LocalStore struct {
+	// dir is the directory of the files, the store is a no-op when empty.
+	dir string
+
+	// baseURL is the url the directory is served under.
+	baseURL string
+}
+
+// NewLocalStore creates a new LocalStore.
+func NewLocalStore(dir, baseURL string) *LocalStore {
+	return &LocalStore{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/") + "/",
+	}
+}
+
+// Delete deletes the file behind a url. Urls outside the base url aren't
+// managed by the store and are ignored, like missing files.
+func (s *LocalStore) Delete(ctx context.Context, uri string) error {
+	if s.dir == "" || !strings.HasPrefix(uri, s.baseURL) {
+		return nil
+	}
+
+	u, err := url.Parse(strings.TrimPrefix(uri, s.baseURL))
+	if err != nil {
+		return err
+	}
+
+	// Cleaning from the root keeps the path inside the directory.
+	name := filepath.Join(s.dir, filepath.FromSlash(path.Clean("/"+u.Path)))
+	if name == filepath.Clean(s.dir) {
+		return nil
+	}
+
+	if err = os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
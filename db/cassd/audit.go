@@ -0,0 +1,165 @@
+package cassd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/gocql/gocql"
+	"github.com/google/uuid"
+)
+
+// CQL queries for the audit log.
+const (
+	// createAuditEventByUser appends an event to the per user partition.
+	createAuditEventByUser = `INSERT INTO audit_events_by_user (user_uid, created_at, uid, actor_uid, event_type, ip, user_agent, request_id, metadata) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// createAuditEventByDay appends an event to the per day partition.
+	createAuditEventByDay = `INSERT INTO audit_events_by_day (day, created_at, uid, user_uid, actor_uid, event_type, ip, user_agent, request_id, metadata) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// getAuditEventsByUser returns the most recent events of a user.
+	getAuditEventsByUser = `SELECT uid, user_uid, actor_uid, event_type, ip, user_agent, request_id, metadata, created_at FROM audit_events_by_user WHERE user_uid = ? AND created_at < ? LIMIT ?`
+
+	// getAuditEventsByDay returns the events of a day in a time range.
+	getAuditEventsByDay = `SELECT uid, user_uid, actor_uid, event_type, ip, user_agent, request_id, metadata, created_at FROM audit_events_by_day WHERE day = ? AND created_at >= ? AND created_at < ?`
+)
+
+// auditMaxQueryDays bounds the number of day partitions scanned by an
+// unbounded admin query.
+const auditMaxQueryDays = 30
+
+// AuditStore is a Cassandra data store for the audit log.
+//
+// Every event is written to a partition per user, for the user's own history,
+// and to a partition per day, for the admin queries.
+type AuditStore struct {
+	// session is the Cassandra session.
+	session *gocql.Session
+}
+
+// NewAuditStore creates a new AuditStore.
+func NewAuditStore(session *gocql.Session) *AuditStore {
+	return &AuditStore{
+		session: session,
+	}
+}
+
+// Record appends an event to the audit log.
+func (s *AuditStore) Record(ctx context.Context, event *types.AuditEvent) error {
+	var actorUID *gocql.UUID
+	if event.ActorUID.Valid {
+		a := gocql.UUID(event.ActorUID.UUID)
+		actorUID = &a
+	}
+
+	batch := s.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	if event.UserUID != uuid.Nil {
+		batch.Query(createAuditEventByUser, gocql.UUID(event.UserUID), event.CreatedAt, gocql.UUID(event.UID), actorUID, string(event.Type), event.IP, event.UserAgent, event.RequestID, event.Metadata)
+	}
+	batch.Query(createAuditEventByDay, auditDay(event.CreatedAt), event.CreatedAt, gocql.UUID(event.UID), gocql.UUID(event.UserUID), actorUID, string(event.Type), event.IP, event.UserAgent, event.RequestID, event.Metadata)
+
+	if err := s.session.ExecuteBatch(batch); err != nil {
+		return fmt.Errorf("%w: %w", interfaces.ErrFailedToRecordAuditEvent, err)
+	}
+	return nil
+}
+
+// GetByUser returns the most recent events of a user created before the given time.
+func (s *AuditStore) GetByUser(ctx context.Context, userID uuid.UUID, before time.Time, limit int) ([]*types.AuditEvent, error) {
+	iter := s.session.Query(getAuditEventsByUser, gocql.UUID(userID), before, limit).WithContext(ctx).Iter()
+
+	events := []*types.AuditEvent{}
+	for {
+		event, ok := scanAuditEvent(iter)
+		if !ok {
+			break
+		}
+		events = append(events, event)
+	}
+	return events, iter.Close()
+}
+
+// Query returns the most recent events matching the filter.
+//
+// Day partitions are scanned from the newest to the oldest, the remaining
+// conditions are applied while reading.
+func (s *AuditStore) Query(ctx context.Context, filter types.AuditFilter) ([]*types.AuditEvent, error) {
+	until := filter.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	since := filter.Since
+	if since.IsZero() || until.Sub(since) > auditMaxQueryDays*24*time.Hour {
+		since = until.Add(-auditMaxQueryDays * 24 * time.Hour)
+	}
+
+	events := []*types.AuditEvent{}
+	for day := until.UTC().Truncate(24 * time.Hour); !day.Before(since.UTC().Truncate(24 * time.Hour)); day = day.Add(-24 * time.Hour) {
+		iter := s.session.Query(getAuditEventsByDay, auditDay(day), since, until).WithContext(ctx).Iter()
+		for {
+			event, ok := scanAuditEvent(iter)
+			if !ok {
+				break
+			}
+			if matchesAuditFilter(event, filter) {
+				events = append(events, event)
+			}
+			if len(events) >= filter.Limit {
+				return events, iter.Close()
+			}
+		}
+		if err := iter.Close(); err != nil {
+			return events, err
+		}
+	}
+	return events, nil
+}
+
+// scanAuditEvent scans the next audit event of an iterator.
+func scanAuditEvent(iter *gocql.Iter) (*types.AuditEvent, bool) {
+	var (
+		event    = &types.AuditEvent{}
+		uid      gocql.UUID
+		userUID  gocql.UUID
+		actorUID *gocql.UUID
+		evType   string
+	)
+	if !iter.Scan(&uid, &userUID, &actorUID, &evType, &event.IP, &event.UserAgent, &event.RequestID, &event.Metadata, &event.CreatedAt) {
+		return nil, false
+	}
+
+	event.UID = uuid.UUID(uid)
+	event.UserUID = uuid.UUID(userUID)
+	event.Type = types.AuditEventType(evType)
+	if actorUID != nil {
+		event.ActorUID = uuid.NullUUID{UUID: uuid.UUID(*actorUID), Valid: true}
+	}
+	return event, true
+}
+
+// matchesAuditFilter reports whether an event matches the filter conditions
+// that can't be expressed in the day partition query.
+func matchesAuditFilter(event *types.AuditEvent, filter types.AuditFilter) bool {
+	if filter.UserUID != uuid.Nil && event.UserUID != filter.UserUID {
+		return false
+	}
+	if filter.IP != "" && event.IP != filter.IP {
+		return false
+	}
+	if len(filter.Types) == 0 {
+		return true
+	}
+	for _, t := range filter.Types {
+		if event.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// auditDay returns the day partition key of a time.
+func auditDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
@@ -0,0 +1,61 @@
+package cassd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+func TestMatchesAuditFilter(t *testing.T) {
+	user := uuid.New()
+	event := &types.AuditEvent{
+		UserUID: user,
+		Type:    types.AuditLoginFailure,
+		IP:      "203.0.113.7",
+	}
+
+	tests := []struct {
+		name   string
+		filter types.AuditFilter
+		want   bool
+	}{
+		{"empty", types.AuditFilter{}, true},
+		{"user", types.AuditFilter{UserUID: user}, true},
+		{"other user", types.AuditFilter{UserUID: uuid.New()}, false},
+		{"ip", types.AuditFilter{IP: "203.0.113.7"}, true},
+		{"other ip", types.AuditFilter{IP: "203.0.113.8"}, false},
+		{"type", types.AuditFilter{Types: []types.AuditEventType{types.AuditLoginSuccess, types.AuditLoginFailure}}, true},
+		{"other type", types.AuditFilter{Types: []types.AuditEventType{types.AuditLoginSuccess}}, false},
+		{"every condition", types.AuditFilter{UserUID: user, IP: "203.0.113.7", Types: []types.AuditEventType{types.AuditLoginFailure}}, true},
+		{"one condition failing", types.AuditFilter{UserUID: user, IP: "203.0.113.8", Types: []types.AuditEventType{types.AuditLoginFailure}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesAuditFilter(event, tt.filter); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditDay(t *testing.T) {
+	// The partition of a time is its day in UTC, whatever its location.
+	tz := time.FixedZone("UTC+5", 5*60*60)
+
+	tests := []struct {
+		time time.Time
+		want string
+	}{
+		{time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), "2026-10-19"},
+		{time.Date(2026, 10, 19, 23, 59, 59, 0, time.UTC), "2026-10-19"},
+		{time.Date(2026, 10, 19, 3, 0, 0, 0, tz), "2026-10-18"},
+		{time.Date(2026, 10, 19, 6, 0, 0, 0, tz), "2026-10-19"},
+	}
+	for _, tt := range tests {
+		if got := auditDay(tt.time); got != tt.want {
+			t.Errorf("auditDay(%v) = %s, want %s", tt.time, got, tt.want)
+		}
+	}
+}
@@ -7,12 +7,24 @@ import (
 )
 
 func NewSession(host, username, password, keyspace string) (*gocql.Session, error) {
+	cluster := newCluster(host, username, password)
+	cluster.Keyspace = keyspace
+
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// newCluster returns the cluster configuration shared by the sessions.
+func newCluster(host, username, password string) *gocql.ClusterConfig {
 	cluster := gocql.NewCluster(host)
 	cluster.Authenticator = gocql.PasswordAuthenticator{
 		Username: username,
 		Password: password,
 	}
-	cluster.Keyspace = keyspace
 	cluster.Consistency = gocql.Quorum
 	cluster.ProtoVersion = 4
 	cluster.ConnectTimeout = time.Second * 10
@@ -27,11 +39,7 @@ func NewSession(host, username, password, keyspace string) (*gocql.Session, erro
 	cluster.PageSize = 5000
 	cluster.SerialConsistency = gocql.LocalSerial
 	cluster.SocketKeepalive = time.Second * 10
-
-	session, err := cluster.CreateSession()
-	if err != nil {
-		return nil, err
-	}
-
-	return session, nil
+	cluster.QueryObserver = newQueryTracer()
+	cluster.BatchObserver = newQueryTracer()
+	return cluster
 }
@@ -0,0 +1,39 @@
+package cassd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gocql/gocql"
+)
+
+// checkSession reads the version of the coordinator node.
+const checkSession = `SELECT release_version FROM system.local`
+
+var (
+	// ErrSessionClosed is returned when the session is closed.
+	ErrSessionClosed = errors.New("cassandra session is closed")
+)
+
+// HealthCheck checks a Cassandra session.
+type HealthCheck struct {
+	// session is the Cassandra session.
+	session *gocql.Session
+}
+
+// NewHealthCheck creates a new HealthCheck.
+func NewHealthCheck(session *gocql.Session) *HealthCheck {
+	return &HealthCheck{
+		session: session,
+	}
+}
+
+// Check queries a node of the cluster.
+func (h *HealthCheck) Check(ctx context.Context) error {
+	if h.session.Closed() {
+		return ErrSessionClosed
+	}
+
+	var version string
+	return h.session.Query(checkSession).WithContext(ctx).Consistency(gocql.One).Scan(&version)
+}
@@ -0,0 +1,140 @@
+package cassd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SimpleStrategy places the replicas on the next nodes of the ring, for
+	// single datacenter and development clusters.
+	SimpleStrategy = "SimpleStrategy"
+
+	// NetworkTopologyStrategy places the replicas per datacenter.
+	NetworkTopologyStrategy = "NetworkTopologyStrategy"
+)
+
+// createKeyspace creates a keyspace with a replication.
+const createKeyspace = `CREATE KEYSPACE IF NOT EXISTS %s WITH REPLICATION = %s`
+
+var (
+	// ErrInvalidKeyspace is returned when a keyspace name isn't a valid identifier.
+	ErrInvalidKeyspace = errors.New("invalid keyspace name")
+
+	// ErrInvalidReplication is returned when a replication can't be used.
+	ErrInvalidReplication = errors.New("invalid keyspace replication")
+)
+
+var (
+	// keyspacePattern matches the unquoted keyspace names.
+	keyspacePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,47}$`)
+
+	// dataCenterPattern matches the datacenter names.
+	dataCenterPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
+// Replication is the replication of a keyspace.
+type Replication struct {
+	// Strategy is SimpleStrategy or NetworkTopologyStrategy.
+	Strategy string
+
+	// Factor is the number of replicas with SimpleStrategy.
+	Factor int
+
+	// DataCenters is the number of replicas per datacenter with
+	// NetworkTopologyStrategy.
+	DataCenters map[string]int
+}
+
+// Validate reports whether the replication can be used.
+func (r Replication) Validate() error {
+	switch r.Strategy {
+	case SimpleStrategy:
+		if r.Factor <= 0 {
+			return fmt.Errorf("%w: replication factor must be positive", ErrInvalidReplication)
+		}
+	case NetworkTopologyStrategy:
+		if len(r.DataCenters) == 0 {
+			return fmt.Errorf("%w: %s needs at least one datacenter", ErrInvalidReplication, NetworkTopologyStrategy)
+		}
+		for dc, factor := range r.DataCenters {
+			if !dataCenterPattern.MatchString(dc) {
+				return fmt.Errorf("%w: invalid datacenter %q", ErrInvalidReplication, dc)
+			}
+			if factor <= 0 {
+				return fmt.Errorf("%w: replication factor of %s must be positive", ErrInvalidReplication, dc)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown strategy %q", ErrInvalidReplication, r.Strategy)
+	}
+	return nil
+}
+
+// String returns the replication as a CQL map.
+func (r Replication) String() string {
+	if r.Strategy == SimpleStrategy {
+		return fmt.Sprintf("{'class': '%s', 'replication_factor': %d}", SimpleStrategy, r.Factor)
+	}
+
+	dcs := make([]string, 0, len(r.DataCenters))
+	for dc := range r.DataCenters {
+		dcs = append(dcs, dc)
+	}
+	sort.Strings(dcs)
+
+	options := []string{fmt.Sprintf("'class': '%s'", NetworkTopologyStrategy)}
+	for _, dc := range dcs {
+		options = append(options, fmt.Sprintf("'%s': %d", dc, r.DataCenters[dc]))
+	}
+	return "{" + strings.Join(options, ", ") + "}"
+}
+
+// ParseDataCenters parses a comma separated list of datacenter:factor pairs.
+func ParseDataCenters(v string) (map[string]int, error) {
+	dcs := map[string]int{}
+	for _, pair := range strings.Split(v, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		dc, f, ok := strings.Cut(pair, ":")
+		factor, err := strconv.Atoi(strings.TrimSpace(f))
+		if !ok || err != nil {
+			return nil, fmt.Errorf("%w: expected datacenter:factor, got %q", ErrInvalidReplication, pair)
+		}
+		dcs[strings.TrimSpace(dc)] = factor
+	}
+	return dcs, nil
+}
+
+// CreateKeyspace creates a keyspace when it doesn't exist and waits for the
+// nodes to agree on the schema.
+//
+// The replication of an existing keyspace isn't changed, altering it needs a
+// repair and is left to the operators.
+func CreateKeyspace(host, username, password, keyspace string, replication Replication) error {
+	if !keyspacePattern.MatchString(keyspace) {
+		return fmt.Errorf("%w: %q", ErrInvalidKeyspace, keyspace)
+	}
+	if err := replication.Validate(); err != nil {
+		return err
+	}
+
+	session, err := newCluster(host, username, password).CreateSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	if err = session.Query(fmt.Sprintf(createKeyspace, keyspace, replication)).Exec(); err != nil {
+		return err
+	}
+	return session.AwaitSchemaAgreement(context.Background())
+}
@@ -0,0 +1,76 @@
+package cassd
+
+import (
+	"errors"
+	"maps"
+	"testing"
+)
+
+func TestReplication(t *testing.T) {
+	tests := []struct {
+		name        string
+		replication Replication
+		valid       bool
+		cql         string
+	}{
+		{
+			name:        "simple",
+			replication: Replication{Strategy: SimpleStrategy, Factor: 3},
+			valid:       true,
+			cql:         "{'class': 'SimpleStrategy', 'replication_factor': 3}",
+		},
+		{
+			name:        "network topology",
+			replication: Replication{Strategy: NetworkTopologyStrategy, DataCenters: map[string]int{"eu-west": 3, "dc1": 2}},
+			valid:       true,
+			cql:         "{'class': 'NetworkTopologyStrategy', 'dc1': 2, 'eu-west': 3}",
+		},
+		{name: "simple without factor", replication: Replication{Strategy: SimpleStrategy}},
+		{name: "network topology without datacenter", replication: Replication{Strategy: NetworkTopologyStrategy}},
+		{name: "datacenter without factor", replication: Replication{Strategy: NetworkTopologyStrategy, DataCenters: map[string]int{"dc1": 0}}},
+		{name: "quoted datacenter", replication: Replication{Strategy: NetworkTopologyStrategy, DataCenters: map[string]int{"dc1': 3, 'dc2": 3}}},
+		{name: "unknown strategy", replication: Replication{Strategy: "LocalStrategy", Factor: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.replication.Validate()
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidReplication) {
+					t.Errorf("got %v, want an invalid replication", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got %v", err)
+			}
+			if got := tt.replication.String(); got != tt.cql {
+				t.Errorf("got %s, want %s", got, tt.cql)
+			}
+		})
+	}
+}
+
+func TestParseDataCenters(t *testing.T) {
+	tests := []struct {
+		value string
+		want  map[string]int
+	}{
+		{"", map[string]int{}},
+		{"dc1:3", map[string]int{"dc1": 3}},
+		{" dc1 : 3 , eu-west:2, ", map[string]int{"dc1": 3, "eu-west": 2}},
+		{"dc1", nil},
+		{"dc1:three", nil},
+	}
+	for _, tt := range tests {
+		got, err := ParseDataCenters(tt.value)
+		if tt.want == nil {
+			if !errors.Is(err, ErrInvalidReplication) {
+				t.Errorf("%q: got %v, want an invalid replication", tt.value, err)
+			}
+			continue
+		}
+		if err != nil || !maps.Equal(got, tt.want) {
+			t.Errorf("%q: got %v, %v, want %v", tt.value, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package cassd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/gocql/gocql"
+	"github.com/google/uuid"
+)
+
+// CQL queries for private messages.
+const (
+	// createMessage appends a message to its conversation partition.
+	createMessage = `INSERT INTO messages_by_conversation (conversation_id, created_at, uid, sender_uid, recipient_uid, body, status_uid, status_owner_uid, status_title, status_thumbnail) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// getMessagesByConversation returns the most recent messages of a conversation.
+	getMessagesByConversation = `SELECT uid, conversation_id, sender_uid, recipient_uid, body, status_uid, status_owner_uid, status_title, status_thumbnail, created_at FROM messages_by_conversation WHERE conversation_id = ? AND created_at < ? LIMIT ?`
+)
+
+// MessageStore is a Cassandra data store for private messages, partitioned
+// by conversation.
+type MessageStore struct {
+	// session is the Cassandra session.
+	session *gocql.Session
+}
+
+// NewMessageStore creates a new MessageStore.
+func NewMessageStore(session *gocql.Session) *MessageStore {
+	return &MessageStore{
+		session: session,
+	}
+}
+
+// Send stores a new message.
+func (s *MessageStore) Send(ctx context.Context, message *types.Message) (*types.Message, error) {
+	uid := gocql.TimeUUID()
+	message.UID = uuid.UUID(uid)
+	message.CreatedAt = uid.Time().UTC().Truncate(time.Millisecond)
+	message.ConversationID = types.ConversationID(message.SenderUID, message.RecipientUID)
+
+	var (
+		statusUID, statusOwnerUID *gocql.UUID
+		statusTitle, statusThumb  string
+	)
+	if ref := message.StatusRef; ref != nil {
+		sid, oid := gocql.UUID(ref.StatusUID), gocql.UUID(ref.OwnerUID)
+		statusUID, statusOwnerUID = &sid, &oid
+		statusTitle, statusThumb = ref.Title, ref.ResourceThumbnail
+	}
+
+	err := s.session.Query(createMessage, message.ConversationID, message.CreatedAt, uid, gocql.UUID(message.SenderUID), gocql.UUID(message.RecipientUID), message.Body, statusUID, statusOwnerUID, statusTitle, statusThumb).WithContext(ctx).Exec()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", interfaces.ErrFailedToSendMessage, err)
+	}
+	return message, nil
+}
+
+// GetConversation returns the most recent messages of a conversation sent
+// before the given time.
+func (s *MessageStore) GetConversation(ctx context.Context, conversationID string, before time.Time, limit int) ([]*types.Message, error) {
+	iter := s.session.Query(getMessagesByConversation, conversationID, before, limit).WithContext(ctx).Iter()
+
+	messages := []*types.Message{}
+	for {
+		message, ok := scanMessage(iter)
+		if !ok {
+			break
+		}
+		messages = append(messages, message)
+	}
+	return messages, iter.Close()
+}
+
+// scanMessage scans the next message of an iterator.
+func scanMessage(iter *gocql.Iter) (*types.Message, bool) {
+	var (
+		message                   = &types.Message{}
+		uid, senderUID, recipient gocql.UUID
+		statusUID, statusOwnerUID *gocql.UUID
+		statusTitle, statusThumb  string
+	)
+	if !iter.Scan(&uid, &message.ConversationID, &senderUID, &recipient, &message.Body, &statusUID, &statusOwnerUID, &statusTitle, &statusThumb, &message.CreatedAt) {
+		return nil, false
+	}
+
+	message.UID = uuid.UUID(uid)
+	message.SenderUID = uuid.UUID(senderUID)
+	message.RecipientUID = uuid.UUID(recipient)
+	if statusUID != nil && statusOwnerUID != nil {
+		message.StatusRef = &types.StatusRef{
+			StatusUID:         uuid.UUID(*statusUID),
+			OwnerUID:          uuid.UUID(*statusOwnerUID),
+			Title:             statusTitle,
+			ResourceThumbnail: statusThumb,
+		}
+	}
+	return message, true
+}
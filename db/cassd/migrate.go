@@ -0,0 +1,303 @@
+package cassd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/coderero/erochat-server/db/migrate"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/gocql/gocql"
+)
+
+// CQL queries for the schema migrations.
+const (
+	// createMigrationsTable creates the table of the applied migrations.
+	createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint PRIMARY KEY, name text, checksum text, applied_at timestamp)`
+
+	// createMigrationsLockTable creates the table of the migration lock.
+	createMigrationsLockTable = `CREATE TABLE IF NOT EXISTS schema_migrations_lock (name text PRIMARY KEY, owner uuid)`
+
+	// getAppliedMigrations returns the applied migrations.
+	getAppliedMigrations = `SELECT version, name, checksum, applied_at FROM schema_migrations`
+
+	// createAppliedMigration records an applied migration.
+	createAppliedMigration = `INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`
+
+	// deleteAppliedMigration forgets a rolled back migration.
+	deleteAppliedMigration = `DELETE FROM schema_migrations WHERE version = ?`
+
+	// acquireMigrationLock takes the migration lock, it expires with its TTL
+	// when the owner dies.
+	acquireMigrationLock = `INSERT INTO schema_migrations_lock (name, owner) VALUES (?, ?) IF NOT EXISTS USING TTL ?`
+
+	// releaseMigrationLock releases the migration lock of an owner.
+	releaseMigrationLock = `DELETE FROM schema_migrations_lock WHERE name = ? IF owner = ?`
+)
+
+const (
+	// migrationLock is the name of the lock serializing the migrations.
+	migrationLock = "schema_migrations"
+
+	// migrationLockTTL bounds how long a dead migration holds the lock.
+	migrationLockTTL = 10 * time.Minute
+
+	// migrationLockTimeout is how long a migration waits for another one.
+	migrationLockTimeout = time.Minute
+
+	// migrationLockRetry is the delay between the attempts to take the lock.
+	migrationLockRetry = 2 * time.Second
+)
+
+// Migrator applies the versioned Cassandra schema migrations to the keyspace
+// of a session.
+//
+// Cassandra has no advisory locks, instances starting together serialize on
+// a lightweight transaction. The nodes must agree on the schema after every
+// statement, before the next one depends on it.
+type Migrator struct {
+	// session is the Cassandra session.
+	session *gocql.Session
+
+	// migrations holds the migration files.
+	migrations fs.FS
+}
+
+// appliedMigration is a row of the applied migrations table.
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// NewMigrator creates a new Migrator.
+func NewMigrator(session *gocql.Session, migrations fs.FS) *Migrator {
+	return &Migrator{
+		session:    session,
+		migrations: migrations,
+	}
+}
+
+// Up applies the pending migrations in order, it returns the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]types.MigrationStatus, error) {
+	migrations, err := migrate.Load(m.migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []types.MigrationStatus{}
+	err = m.withLock(ctx, func() error {
+		done, err := m.getAppliedMigrations(ctx)
+		if err != nil {
+			return err
+		}
+
+		checksums := make(map[int64]string, len(done))
+		for version, a := range done {
+			checksums[version] = a.checksum
+		}
+		pending, err := migrate.Pending(migrations, checksums)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range pending {
+			if err = m.execMigration(ctx, migration, migration.Up); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			if err = m.session.Query(createAppliedMigration, migration.Version, migration.Name, migration.Checksum, now).WithContext(ctx).Exec(); err != nil {
+				return err
+			}
+			applied = append(applied, types.MigrationStatus{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				State:     types.MigrationApplied,
+				AppliedAt: &now,
+			})
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last applied migrations, it returns the rolled back ones.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]types.MigrationStatus, error) {
+	migrations, err := migrate.Load(m.migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*migrate.Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	rolledBack := []types.MigrationStatus{}
+	err = m.withLock(ctx, func() error {
+		done, err := m.getAppliedMigrations(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Migrations applied by a newer build can't be rolled back from here.
+		for version := range done {
+			if _, ok := byVersion[version]; !ok {
+				return fmt.Errorf("migrate: version %d is unknown to this build", version)
+			}
+		}
+
+		for i := len(migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			migration := migrations[i]
+			a, ok := done[migration.Version]
+			if !ok {
+				continue
+			}
+			if a.checksum != migration.Checksum {
+				return fmt.Errorf("migrate: %d_%s: %w", migration.Version, migration.Name, migrate.ErrChecksumMismatch)
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migrate: %d_%s: %w", migration.Version, migration.Name, migrate.ErrNoDownMigration)
+			}
+
+			if err = m.execMigration(ctx, migration, migration.Down); err != nil {
+				return err
+			}
+			if err = m.session.Query(deleteAppliedMigration, migration.Version).WithContext(ctx).Exec(); err != nil {
+				return err
+			}
+
+			rolledBack = append(rolledBack, types.MigrationStatus{
+				Version: migration.Version,
+				Name:    migration.Name,
+				State:   types.MigrationPending,
+			})
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status returns the state of every migration.
+func (m *Migrator) Status(ctx context.Context) ([]types.MigrationStatus, error) {
+	migrations, err := migrate.Load(m.migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.createTables(ctx); err != nil {
+		return nil, err
+	}
+	done, err := m.getAppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]types.MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := types.MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+			State:   types.MigrationPending,
+		}
+		if a, ok := done[migration.Version]; ok {
+			status.State = types.MigrationApplied
+			if a.checksum != migration.Checksum {
+				status.State = types.MigrationModified
+			}
+			status.AppliedAt = &a.appliedAt
+			delete(done, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for version, a := range done {
+		statuses = append(statuses, types.MigrationStatus{
+			Version:   version,
+			Name:      a.name,
+			State:     types.MigrationUnknown,
+			AppliedAt: &a.appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// withLock runs a function holding the migration lock.
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	if err := m.createTables(ctx); err != nil {
+		return err
+	}
+
+	owner := gocql.TimeUUID()
+	deadline := time.Now().Add(migrationLockTimeout)
+	for {
+		applied, err := m.session.Query(acquireMigrationLock, migrationLock, owner, int(migrationLockTTL.Seconds())).WithContext(ctx).MapScanCAS(map[string]interface{}{})
+		if err != nil {
+			return err
+		}
+		if applied {
+			break
+		}
+		if time.Now().After(deadline) {
+			return interfaces.ErrMigrationLocked
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(migrationLockRetry):
+		}
+	}
+	// The lock is released even when the context is done, it would otherwise
+	// be held until its TTL.
+	defer m.session.Query(releaseMigrationLock, migrationLock, owner).WithContext(context.WithoutCancel(ctx)).MapScanCAS(map[string]interface{}{})
+
+	return fn()
+}
+
+// createTables creates the migration tables and waits for the schema agreement.
+func (m *Migrator) createTables(ctx context.Context) error {
+	for _, query := range []string{createMigrationsTable, createMigrationsLockTable} {
+		if err := m.session.Query(query).WithContext(ctx).Exec(); err != nil {
+			return err
+		}
+	}
+	return m.session.AwaitSchemaAgreement(ctx)
+}
+
+// getAppliedMigrations returns the applied migrations by version.
+func (m *Migrator) getAppliedMigrations(ctx context.Context) (map[int64]*appliedMigration, error) {
+	iter := m.session.Query(getAppliedMigrations).WithContext(ctx).Iter()
+
+	var (
+		applied = map[int64]*appliedMigration{}
+		version int64
+		a       = &appliedMigration{}
+	)
+	for iter.Scan(&version, &a.name, &a.checksum, &a.appliedAt) {
+		applied[version] = a
+		a = &appliedMigration{}
+	}
+	return applied, iter.Close()
+}
+
+// execMigration runs the statements of a migration file, waiting for the
+// schema agreement after each of them.
+func (m *Migrator) execMigration(ctx context.Context, migration *migrate.Migration, content string) error {
+	statements, err := migrate.Statements(content)
+	if err != nil {
+		return fmt.Errorf("migrate: %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	for i, statement := range statements {
+		if err = m.session.Query(statement).WithContext(ctx).Exec(); err != nil {
+			return fmt.Errorf("migrate: %d_%s: statement %d: %w", migration.Version, migration.Name, i+1, err)
+		}
+		if err = m.session.AwaitSchemaAgreement(ctx); err != nil {
+			return fmt.Errorf("migrate: %d_%s: statement %d: %w", migration.Version, migration.Name, i+1, err)
+		}
+	}
+	return nil
+}
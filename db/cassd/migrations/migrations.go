@@ -0,0 +1,14 @@
+// Package migrations contains the versioned Cassandra schema migrations.
+//
+// Each version has an up and a down file, named <version>_<name>.up.cql and
+// <version>_<name>.down.cql. Tables are created in the session's keyspace,
+// the keyspace itself is created by cassd.CreateKeyspace with the replication
+// of the environment.
+package migrations
+
+import "embed"
+
+// FS holds the migration files.
+//
+//go:embed *.cql
+var FS embed.FS
@@ -0,0 +1,74 @@
+package cassd
+
+import (
+	"context"
+	"time"
+
+	"github.com/coderero/erochat-server/tracing"
+	"github.com/gocql/gocql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the CQL spans.
+const tracerName = "github.com/coderero/erochat-server/db/cassd"
+
+// queryTracer records a span for every CQL query and batch run within a
+// trace, gocql reports them once they're done.
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+// newQueryTracer creates a new queryTracer using the global tracer provider.
+func newQueryTracer() *queryTracer {
+	return &queryTracer{tracer: otel.Tracer(tracerName)}
+}
+
+// ObserveQuery records the span of a query.
+func (t *queryTracer) ObserveQuery(ctx context.Context, q gocql.ObservedQuery) {
+	if !tracing.Traced(ctx) {
+		return
+	}
+
+	t.record(ctx, "cql.query", q.Start, q.End, q.Host, q.Err,
+		semconv.DBQueryText(q.Statement),
+		semconv.DBNamespace(q.Keyspace),
+		attribute.Int("db.cassandra.rows", q.Rows),
+		attribute.Int("db.cassandra.attempt", q.Attempt),
+	)
+}
+
+// ObserveBatch records the span of a batch.
+func (t *queryTracer) ObserveBatch(ctx context.Context, b gocql.ObservedBatch) {
+	if !tracing.Traced(ctx) {
+		return
+	}
+
+	t.record(ctx, "cql.batch", b.Start, b.End, b.Host, b.Err,
+		attribute.StringSlice("db.query.texts", b.Statements),
+		semconv.DBNamespace(b.Keyspace),
+		attribute.Int("db.operation.batch.size", len(b.Statements)),
+	)
+}
+
+// record records a span that already ended.
+func (t *queryTracer) record(ctx context.Context, name string, start, end time.Time, host *gocql.HostInfo, err error, attrs ...attribute.KeyValue) {
+	attrs = append(attrs, semconv.DBSystemCassandra)
+	if host != nil {
+		attrs = append(attrs, semconv.ServerAddress(host.ConnectAddress().String()), semconv.ServerPort(host.Port()))
+	}
+
+	_, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attrs...),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(end))
+}
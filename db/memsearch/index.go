@@ -0,0 +1,250 @@
+// Package memsearch is an embedded in-process search index of users, for
+// deployments where the MySQL full-text index isn't available or wanted.
+package memsearch
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+// Scores of a search term matching a word of a document.
+const (
+	scoreExact  = 3
+	scorePrefix = 2
+	scoreFuzzy  = 1
+
+	// scoreUsernamePrefix is added when the whole search prefixes the username.
+	scoreUsernamePrefix = 2
+)
+
+// document is an indexed user with its lowercase words.
+type document struct {
+	doc      *types.SearchDocument
+	username string
+	words    []string
+}
+
+// Index is an in-memory search index of users. It's loaded from the user
+// store with Rebuild and kept fresh with Reindex.
+type Index struct {
+	// userStore is the source of the indexed documents.
+	userStore interfaces.UserStore
+
+	// friendStore is used to rank friends of friends and filter blocks.
+	friendStore interfaces.FriendStore
+
+	mu   sync.RWMutex
+	docs map[uuid.UUID]*document
+}
+
+// NewIndex creates a new, empty, Index.
+func NewIndex(userStore interfaces.UserStore, friendStore interfaces.FriendStore) *Index {
+	return &Index{
+		userStore:   userStore,
+		friendStore: friendStore,
+		docs:        map[uuid.UUID]*document{},
+	}
+}
+
+// Rebuild replaces the whole index with the documents of the user store.
+func (i *Index) Rebuild(ctx context.Context) error {
+	docs, err := i.userStore.GetSearchDocuments(ctx)
+	if err != nil {
+		return err
+	}
+
+	indexed := make(map[uuid.UUID]*document, len(docs))
+	for _, doc := range docs {
+		indexed[doc.UID] = newDocument(doc)
+	}
+
+	i.mu.Lock()
+	i.docs = indexed
+	i.mu.Unlock()
+	return nil
+}
+
+// Reindex refreshes the document of a user, removing it if the user is no
+// longer searchable.
+func (i *Index) Reindex(ctx context.Context, userID uuid.UUID) error {
+	doc, err := i.userStore.GetSearchDocument(ctx, userID)
+	if err != nil && !errors.Is(err, interfaces.ErrUserNotFound) {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if doc == nil {
+		delete(i.docs, userID)
+		return nil
+	}
+	i.docs[userID] = newDocument(doc)
+	return nil
+}
+
+// Search returns a page of the users matching the query.
+func (i *Index) Search(ctx context.Context, query types.SearchQuery) (*types.SearchPage, error) {
+	page := &types.SearchPage{Results: []*types.SearchResult{}}
+
+	terms := types.SearchTerms(query.Query)
+	if len(terms) == 0 {
+		return page, nil
+	}
+
+	friendsOfFriends, err := i.uidSet(ctx, i.friendStore.GetFriendsOfFriends, query.ViewerUID)
+	if err != nil {
+		return page, err
+	}
+	blocked, err := i.uidSet(ctx, i.friendStore.GetBlockRelations, query.ViewerUID)
+	if err != nil {
+		return page, err
+	}
+
+	prefix := strings.Join(terms, "")
+
+	i.mu.RLock()
+	var results []*types.SearchResult
+	for uid, d := range i.docs {
+		if uid == query.ViewerUID || blocked[uid] {
+			continue
+		}
+
+		score := d.score(terms)
+		if score == 0 && !strings.HasPrefix(d.username, prefix) {
+			continue
+		}
+		if strings.HasPrefix(d.username, prefix) {
+			score += scoreUsernamePrefix
+		}
+		if friendsOfFriends[uid] {
+			score += types.SearchFriendOfFriendBoost
+		}
+
+		score = math.Round(score*1e4) / 1e4
+		if query.Cursor != nil && !query.Cursor.After(score, uid) {
+			continue
+		}
+
+		results = append(results, &types.SearchResult{
+			SearchDocument: *d.doc,
+			Score:          score,
+			FriendOfFriend: friendsOfFriends[uid],
+		})
+	}
+	i.mu.RUnlock()
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].UID.String() < results[b].UID.String()
+	})
+
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+		last := results[len(results)-1]
+		page.NextCursor = (&types.SearchCursor{Score: last.Score, UID: last.UID}).Encode()
+	}
+	page.Results = append(page.Results, results...)
+	return page, nil
+}
+
+// uidSet calls a store method returning uids and makes a set out of them.
+func (i *Index) uidSet(ctx context.Context, get func(context.Context, uuid.UUID) ([]uuid.UUID, error), userID uuid.UUID) (map[uuid.UUID]bool, error) {
+	uids, err := get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[uuid.UUID]bool, len(uids))
+	for _, uid := range uids {
+		set[uid] = true
+	}
+	return set, nil
+}
+
+// newDocument indexes a document.
+func newDocument(doc *types.SearchDocument) *document {
+	d := &document{
+		doc:      doc,
+		username: strings.ToLower(doc.Username),
+	}
+	d.words = append(d.words, types.SearchTerms(doc.Username)...)
+	d.words = append(d.words, types.SearchTerms(doc.FirstName)...)
+	d.words = append(d.words, types.SearchTerms(doc.LastName)...)
+	return d
+}
+
+// score sums the best match of every term against the words of the document.
+func (d *document) score(terms []string) float64 {
+	var total float64
+	for _, term := range terms {
+		best := 0
+		for _, word := range d.words {
+			switch {
+			case word == term:
+				best = max(best, scoreExact)
+			case strings.HasPrefix(word, term):
+				best = max(best, scorePrefix)
+			case isFuzzyMatch(term, word):
+				best = max(best, scoreFuzzy)
+			}
+		}
+		total += float64(best)
+	}
+	return total
+}
+
+// isFuzzyMatch reports whether the word is a typo away from the term, the
+// tolerance grows with the length of the term.
+func isFuzzyMatch(term, word string) bool {
+	maxDistance := 0
+	switch n := len([]rune(term)); {
+	case n >= 8:
+		maxDistance = 2
+	case n >= 4:
+		maxDistance = 1
+	}
+	if maxDistance == 0 {
+		return false
+	}
+
+	// Compare against the word cut to the term's length so that prefixes
+	// with a typo match too.
+	w := []rune(word)
+	if len(w) > len([]rune(term))+maxDistance {
+		w = w[:len([]rune(term))+maxDistance]
+	}
+	return levenshtein([]rune(term), w) <= maxDistance
+}
+
+// levenshtein returns the edit distance between two words.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
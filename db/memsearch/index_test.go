@@ -0,0 +1,126 @@
+package memsearch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+// fakeUserStore serves the search documents, the other methods panic.
+type fakeUserStore struct {
+	interfaces.UserStore
+	docs map[uuid.UUID]*types.SearchDocument
+}
+
+func (s *fakeUserStore) GetSearchDocuments(ctx context.Context) ([]*types.SearchDocument, error) {
+	docs := make([]*types.SearchDocument, 0, len(s.docs))
+	for _, doc := range s.docs {
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func (s *fakeUserStore) GetSearchDocument(ctx context.Context, id uuid.UUID) (*types.SearchDocument, error) {
+	doc, ok := s.docs[id]
+	if !ok {
+		return nil, interfaces.ErrUserNotFound
+	}
+	return doc, nil
+}
+
+// fakeFriendStore serves the relations of the viewer, the other methods panic.
+type fakeFriendStore struct {
+	interfaces.FriendStore
+	friendsOfFriends []uuid.UUID
+	blocked          []uuid.UUID
+}
+
+func (s *fakeFriendStore) GetFriendsOfFriends(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return s.friendsOfFriends, nil
+}
+
+func (s *fakeFriendStore) GetBlockRelations(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return s.blocked, nil
+}
+
+func TestIndexSearchExcludes(t *testing.T) {
+	ctx := context.Background()
+	var (
+		viewer  = &types.SearchDocument{UID: uuid.New(), Username: "alice_viewer"}
+		visible = &types.SearchDocument{UID: uuid.New(), Username: "alice_visible"}
+		blocked = &types.SearchDocument{UID: uuid.New(), Username: "alice_blocked"}
+		hidden  = &types.SearchDocument{UID: uuid.New(), Username: "alice_hidden"}
+	)
+	users := &fakeUserStore{docs: map[uuid.UUID]*types.SearchDocument{
+		viewer.UID:  viewer,
+		visible.UID: visible,
+		blocked.UID: blocked,
+		hidden.UID:  hidden,
+	}}
+	index := NewIndex(users, &fakeFriendStore{blocked: []uuid.UUID{blocked.UID}})
+	if err := index.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+
+	// The user turned searchable off, the store stops serving its document.
+	delete(users.docs, hidden.UID)
+	if err := index.Reindex(ctx, hidden.UID); err != nil {
+		t.Fatalf("Reindex: %v", err)
+	}
+
+	page, err := index.Search(ctx, types.SearchQuery{Query: "alice", ViewerUID: viewer.UID, Limit: 10})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(page.Results) != 1 || page.Results[0].UID != visible.UID {
+		var got []string
+		for _, result := range page.Results {
+			got = append(got, result.Username)
+		}
+		t.Errorf("got %q, want only %q", got, visible.Username)
+	}
+}
+
+func TestIndexSearchPages(t *testing.T) {
+	ctx := context.Background()
+	users := &fakeUserStore{docs: map[uuid.UUID]*types.SearchDocument{}}
+	for _, name := range []string{"bob", "bobby", "bobcat", "bobsleigh", "bobbin"} {
+		doc := &types.SearchDocument{UID: uuid.New(), Username: name}
+		users.docs[doc.UID] = doc
+	}
+	index := NewIndex(users, &fakeFriendStore{})
+	if err := index.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+
+	seen := map[uuid.UUID]bool{}
+	query := types.SearchQuery{Query: "bob", ViewerUID: uuid.New(), Limit: 2}
+	for pages := 0; ; pages++ {
+		if pages > len(users.docs) {
+			t.Fatal("the pages never end")
+		}
+
+		page, err := index.Search(ctx, query)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		for _, result := range page.Results {
+			if seen[result.UID] {
+				t.Errorf("%s is on two pages", result.Username)
+			}
+			seen[result.UID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		if query.Cursor, err = types.ParseSearchCursor(page.NextCursor); err != nil {
+			t.Fatalf("ParseSearchCursor: %v", err)
+		}
+	}
+	if len(seen) != len(users.docs) {
+		t.Errorf("got %d results, want %d", len(seen), len(users.docs))
+	}
+}
@@ -0,0 +1,194 @@
+// Package migrate loads versioned schema migrations, it's shared by the
+// migration runners of the data stores.
+package migrate
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// statementBegin starts a statement spanning several semicolons.
+	statementBegin = "-- +migrate StatementBegin"
+
+	// statementEnd ends a statement started with statementBegin.
+	statementEnd = "-- +migrate StatementEnd"
+)
+
+var (
+	// ErrChecksumMismatch is returned when an applied migration changed since.
+	ErrChecksumMismatch = errors.New("migration changed since it was applied")
+
+	// ErrNoDownMigration is returned when a migration can't be rolled back.
+	ErrNoDownMigration = errors.New("migration has no down file")
+)
+
+// Migration is a versioned schema migration.
+type Migration struct {
+	// Version orders the migrations.
+	Version int64
+
+	// Name describes the migration.
+	Name string
+
+	// Up is the content of the up file.
+	Up string
+
+	// Down is the content of the down file, empty when there is none.
+	Down string
+
+	// Checksum is the sha256 of the up file, it detects applied migrations
+	// that were edited.
+	Checksum string
+}
+
+// Load loads the migrations of a directory, sorted by version.
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (!strings.HasSuffix(name, ".up.sql") && !strings.HasSuffix(name, ".up.cql") && !strings.HasSuffix(name, ".down.sql") && !strings.HasSuffix(name, ".down.cql")) {
+			continue
+		}
+
+		base := strings.TrimSuffix(strings.TrimSuffix(name, ".sql"), ".cql")
+		up := strings.HasSuffix(base, ".up")
+		base = strings.TrimSuffix(strings.TrimSuffix(base, ".up"), ".down")
+
+		v, desc, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migrate: %s: expected <version>_<name>", name)
+		}
+		version, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migrate: %s: invalid version %q", name, v)
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: desc}
+			byVersion[version] = m
+		}
+		if m.Name != desc {
+			return nil, fmt.Errorf("migrate: version %d has two names, %q and %q", version, m.Name, desc)
+		}
+
+		if up {
+			m.Up = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migrate: version %d has no up file", m.Version)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Pending returns the migrations that aren't applied yet, in order. The
+// applied migrations are given by version with their checksum, one that
+// changed since fails with ErrChecksumMismatch.
+func Pending(migrations []*Migration, applied map[int64]string) ([]*Migration, error) {
+	pending := []*Migration{}
+	for _, m := range migrations {
+		checksum, ok := applied[m.Version]
+		if !ok {
+			pending = append(pending, m)
+			continue
+		}
+		if checksum != m.Checksum {
+			return nil, fmt.Errorf("migrate: %d_%s: %w", m.Version, m.Name, ErrChecksumMismatch)
+		}
+	}
+	return pending, nil
+}
+
+// Statements splits a migration into statements.
+//
+// A statement ends with a semicolon at the end of a line, unless it's
+// wrapped in StatementBegin and StatementEnd comments. Comment lines outside
+// of the wrapped statements are dropped.
+func Statements(content string) ([]string, error) {
+	var (
+		statements []string
+		current    strings.Builder
+		wrapped    bool
+	)
+
+	flush := func() {
+		statement := strings.TrimSpace(current.String())
+		statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == statementBegin:
+			if wrapped {
+				return nil, errors.New("migrate: nested StatementBegin")
+			}
+			flush()
+			wrapped = true
+			continue
+		case trimmed == statementEnd:
+			if !wrapped {
+				return nil, errors.New("migrate: StatementEnd without StatementBegin")
+			}
+			flush()
+			wrapped = false
+			continue
+		case !wrapped && strings.HasPrefix(trimmed, "--"):
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteByte('\n')
+		if !wrapped && strings.HasSuffix(trimmed, ";") {
+			flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if wrapped {
+		return nil, errors.New("migrate: StatementBegin without StatementEnd")
+	}
+
+	flush()
+	return statements, nil
+}
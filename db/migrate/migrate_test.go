@@ -0,0 +1,254 @@
+package migrate
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	cassdMigrations "github.com/coderero/erochat-server/db/cassd/migrations"
+	mysqlMigrations "github.com/coderero/erochat-server/db/mysql/migrations"
+)
+
+func TestStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "empty",
+			content: "",
+			want:    nil,
+		},
+		{
+			name:    "one per line",
+			content: "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n",
+			want:    []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+		},
+		{
+			name:    "spanning lines",
+			content: "CREATE TABLE\n    a (\n        id INT\n    );\n\nDROP TABLE b;",
+			want:    []string{"CREATE TABLE\n    a (\n        id INT\n    )", "DROP TABLE b"},
+		},
+		{
+			name:    "semicolon inside a line",
+			content: "INSERT INTO a VALUES ('x;y');\n",
+			want:    []string{"INSERT INTO a VALUES ('x;y')"},
+		},
+		{
+			name:    "comments dropped",
+			content: "-- the users\nCREATE TABLE a (id INT);\n  -- indented\nDROP TABLE b;\n",
+			want:    []string{"CREATE TABLE a (id INT)", "DROP TABLE b"},
+		},
+		{
+			name:    "missing final semicolon",
+			content: "DROP TABLE a;\nDROP TABLE b",
+			want:    []string{"DROP TABLE a", "DROP TABLE b"},
+		},
+		{
+			name: "wrapped statement",
+			content: "DROP PROCEDURE IF EXISTS p;\n\n" +
+				"-- +migrate StatementBegin\n" +
+				"CREATE PROCEDURE p () BEGIN\n" +
+				"-- kept inside the statement\n" +
+				"SELECT 1;\n" +
+				"SELECT 2;\n" +
+				"END;\n" +
+				"-- +migrate StatementEnd\n\n" +
+				"CALL p();\n",
+			want: []string{
+				"DROP PROCEDURE IF EXISTS p",
+				"CREATE PROCEDURE p () BEGIN\n-- kept inside the statement\nSELECT 1;\nSELECT 2;\nEND",
+				"CALL p()",
+			},
+		},
+		{
+			name: "statement before begin without semicolon",
+			content: "SET @a = 1\n" +
+				"  -- +migrate StatementBegin  \n" +
+				"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN SET NEW.id = 1; END;\n" +
+				"-- +migrate StatementEnd\n",
+			want: []string{
+				"SET @a = 1",
+				"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN SET NEW.id = 1; END",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Statements(tt.content)
+			if err != nil {
+				t.Fatalf("Statements: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatementsUnbalanced(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"nested begin", "-- +migrate StatementBegin\n-- +migrate StatementBegin\nSELECT 1;\n-- +migrate StatementEnd\n"},
+		{"end without begin", "SELECT 1;\n-- +migrate StatementEnd\n"},
+		{"begin without end", "-- +migrate StatementBegin\nSELECT 1;\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Statements(tt.content); err == nil {
+				t.Errorf("got %q, want an error", got)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"10_late.up.sql":      {Data: []byte("CREATE TABLE c (id INT);")},
+		"2_second.up.sql":     {Data: []byte("CREATE TABLE b (id INT);")},
+		"2_second.down.sql":   {Data: []byte("DROP TABLE b;")},
+		"1_first.up.cql":      {Data: []byte("CREATE TABLE a (id INT);")},
+		"1_first.down.cql":    {Data: []byte("DROP TABLE a;")},
+		"README.md":           {Data: []byte("not a migration")},
+		"fixtures/3_x.up.sql": {Data: []byte("SELECT 1;")},
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var got []string
+	for _, m := range migrations {
+		got = append(got, m.Name)
+	}
+	if want := []string{"first", "second", "late"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q ordered by version", got, want)
+	}
+
+	if m := migrations[1]; m.Version != 2 || m.Up != "CREATE TABLE b (id INT);" || m.Down != "DROP TABLE b;" {
+		t.Errorf("got %+v", *m)
+	}
+	if migrations[2].Down != "" {
+		t.Errorf("a migration without a down file has a down %q", migrations[2].Down)
+	}
+	if n := len(migrations[0].Checksum); n != 64 {
+		t.Errorf("checksum has %d hex digits, want 64", n)
+	}
+}
+
+func TestLoadChecksum(t *testing.T) {
+	load := func(up, down string) string {
+		t.Helper()
+		migrations, err := Load(fstest.MapFS{
+			"1_first.up.sql":   {Data: []byte(up)},
+			"1_first.down.sql": {Data: []byte(down)},
+		})
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		return migrations[0].Checksum
+	}
+
+	checksum := load("CREATE TABLE a (id INT);", "DROP TABLE a;")
+	if load("CREATE TABLE a (id INT);", "DROP TABLE a;") != checksum {
+		t.Error("the checksum isn't stable")
+	}
+	if load("CREATE TABLE a (id INT);", "DROP TABLE IF EXISTS a;") != checksum {
+		t.Error("editing the down file changes the checksum")
+	}
+	if load("CREATE TABLE a (id BIGINT);", "DROP TABLE a;") == checksum {
+		t.Error("editing the up file keeps the checksum")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		fsys  fstest.MapFS
+		error string
+	}{
+		{"no name", fstest.MapFS{"1.up.sql": {}}, "expected <version>_<name>"},
+		{"bad version", fstest.MapFS{"v1_first.up.sql": {}}, "invalid version"},
+		{"zero version", fstest.MapFS{"0_first.up.sql": {}}, "invalid version"},
+		{"two names", fstest.MapFS{"1_first.up.sql": {}, "1_other.down.sql": {}}, "two names"},
+		{"no up file", fstest.MapFS{"1_first.down.sql": {Data: []byte("DROP TABLE a;")}}, "no up file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("got %v, want an error containing %q", err, tt.error)
+			}
+		})
+	}
+}
+
+func TestPending(t *testing.T) {
+	migrations := []*Migration{
+		{Version: 1, Name: "first", Checksum: "aaa"},
+		{Version: 2, Name: "second", Checksum: "bbb"},
+		{Version: 3, Name: "third", Checksum: "ccc"},
+	}
+
+	tests := []struct {
+		name    string
+		applied map[int64]string
+		want    []int64
+		err     error
+	}{
+		{"none applied", nil, []int64{1, 2, 3}, nil},
+		{"some applied", map[int64]string{1: "aaa"}, []int64{2, 3}, nil},
+		{"gap", map[int64]string{1: "aaa", 3: "ccc"}, []int64{2}, nil},
+		{"all applied", map[int64]string{1: "aaa", 2: "bbb", 3: "ccc"}, []int64{}, nil},
+		{"unknown applied", map[int64]string{1: "aaa", 9: "zzz"}, []int64{2, 3}, nil},
+		{"modified", map[int64]string{1: "aaa", 2: "changed"}, nil, ErrChecksumMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending, err := Pending(migrations, tt.applied)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+
+			got := []int64{}
+			for _, m := range pending {
+				got = append(got, m.Version)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	for name, fsys := range map[string]fs.FS{"mysql": mysqlMigrations.FS, "cassandra": cassdMigrations.FS} {
+		t.Run(name, func(t *testing.T) {
+			migrations, err := Load(fsys)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			for i, m := range migrations {
+				if m.Version != int64(i+1) {
+					t.Errorf("%d_%s: want version %d, the versions must follow each other", m.Version, m.Name, i+1)
+				}
+				for _, content := range []string{m.Up, m.Down} {
+					if _, err := Statements(content); err != nil {
+						t.Errorf("%d_%s: %v", m.Version, m.Name, err)
+					}
+				}
+			}
+		})
+	}
+}
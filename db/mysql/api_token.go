@@ -0,0 +1,135 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/coderero/erochat-server/db/mysql/queries"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+// APITokenStore is a MySQL data store for personal api tokens.
+type APITokenStore struct {
+	// db is the database of the store.
+	db *DB
+}
+
+// NewAPITokenStore creates a new APITokenStore.
+func NewAPITokenStore(db *DB) *APITokenStore {
+	return &APITokenStore{
+		db: db,
+	}
+}
+
+// Create creates a new api token.
+func (s *APITokenStore) Create(ctx context.Context, token *types.APIToken) (*types.APIToken, error) {
+	db := s.db.Primary()
+
+	result, err := db.ExecContext(ctx, queries.CreateAPIToken, token.UserUID, token.Name, token.Prefix, token.Hash, strings.Join(token.Scopes, ","), token.ExpiresAt)
+	if err != nil {
+		return nil, interfaces.ErrFailedToCreateAPIToken
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, interfaces.ErrFailedToCreateAPIToken
+	}
+
+	created := &types.APIToken{}
+	if err = scanAPIToken(db.QueryRowContext(ctx, queries.GetAPITokenByID, id), created); err != nil {
+		return nil, interfaces.ErrFailedToCreateAPIToken
+	}
+	return created, nil
+}
+
+// GetByHash returns an active api token by its hash. It reads the primary so
+// a revoked token is refused at once.
+func (s *APITokenStore) GetByHash(ctx context.Context, hash string) (*types.APIToken, error) {
+	db := s.db.Primary()
+
+	token := &types.APIToken{}
+	err := scanAPIToken(db.QueryRowContext(ctx, queries.GetAPITokenByHash, hash), token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, interfaces.ErrAPITokenNotFound
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+// GetByUser returns the active api tokens of a user.
+func (s *APITokenStore) GetByUser(ctx context.Context, userID uuid.UUID) ([]*types.APIToken, error) {
+	var tokens []*types.APIToken
+	tokens = []*types.APIToken{}
+	db := s.db.Reader()
+
+	rows, err := db.QueryContext(ctx, queries.GetAPITokensByUser, userID)
+	if err != nil {
+		return tokens, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		token := &types.APIToken{}
+		if err = scanAPIToken(rows, token); err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// Revoke revokes an api token of a user.
+func (s *APITokenStore) Revoke(ctx context.Context, userID, uid uuid.UUID) error {
+	db := s.db.Primary()
+
+	a, err := db.ExecContext(ctx, queries.RevokeAPIToken, userID, uid)
+	if err != nil {
+		return err
+	}
+
+	if n, err := a.RowsAffected(); err != nil || n == 0 {
+		return interfaces.ErrAPITokenNotFound
+	}
+	return nil
+}
+
+// RevokeAll revokes the active api tokens of a user and of the bots it owns,
+// and returns how many were revoked.
+func (s *APITokenStore) RevokeAll(ctx context.Context, userID uuid.UUID) (int64, error) {
+	db := s.db.Primary()
+
+	a, err := db.ExecContext(ctx, queries.RevokeAPITokensByUser, userID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return a.RowsAffected()
+}
+
+// Touch records that an api token was used.
+func (s *APITokenStore) Touch(ctx context.Context, uid uuid.UUID) error {
+	db := s.db.Primary()
+
+	_, err := db.ExecContext(ctx, queries.TouchAPIToken, uid)
+	return err
+}
+
+// scanAPIToken scans a row selected with the api token columns into a token.
+func scanAPIToken(row scanner, token *types.APIToken) error {
+	var scopes string
+	err := row.Scan(&token.ID, &token.UID, &token.UserUID, &token.Name, &token.Prefix, &token.Hash, &scopes, &token.LastUsedAt, &token.ExpiresAt, &token.CreatedAt, &token.RevokedAt)
+	if err != nil {
+		return err
+	}
+
+	token.Scopes = []string{}
+	if scopes != "" {
+		token.Scopes = strings.Split(scopes, ",")
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coderero/erochat-server/db/mysql/queries"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+// AuditStore is a MySQL data store for the audit log.
+type AuditStore struct {
+	// db is the database of the store.
+	db *DB
+}
+
+// NewAuditStore creates a new AuditStore.
+func NewAuditStore(db *DB) *AuditStore {
+	return &AuditStore{
+		db: db,
+	}
+}
+
+// Record appends an event to the audit log.
+func (s *AuditStore) Record(ctx context.Context, event *types.AuditEvent) error {
+	db := s.db.Primary()
+
+	var metadata []byte
+	if len(event.Metadata) > 0 {
+		b, err := json.Marshal(event.Metadata)
+		if err != nil {
+			return fmt.Errorf("%w: %w", interfaces.ErrFailedToRecordAuditEvent, err)
+		}
+		metadata = b
+	}
+
+	var userUID uuid.NullUUID
+	if event.UserUID != uuid.Nil {
+		userUID = uuid.NullUUID{UUID: event.UserUID, Valid: true}
+	}
+
+	_, err := db.ExecContext(ctx, queries.CreateAuditEvent, event.UID, userUID, event.ActorUID, event.Type, event.IP, event.UserAgent, event.RequestID, metadata, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %w", interfaces.ErrFailedToRecordAuditEvent, err)
+	}
+	return nil
+}
+
+// GetByUser returns the most recent events of a user created before the given time.
+func (s *AuditStore) GetByUser(ctx context.Context, userID uuid.UUID, before time.Time, limit int) ([]*types.AuditEvent, error) {
+	db := s.db.Reader()
+
+	rows, err := db.QueryContext(ctx, queries.GetAuditEventsByUser, userID, before, limit)
+	if err != nil {
+		return []*types.AuditEvent{}, err
+	}
+	defer rows.Close()
+
+	return scanAuditEvents(rows)
+}
+
+// Query returns the most recent events matching the filter.
+func (s *AuditStore) Query(ctx context.Context, filter types.AuditFilter) ([]*types.AuditEvent, error) {
+	db := s.db.Primary()
+
+	var (
+		query strings.Builder
+		args  []any
+	)
+	query.WriteString(queries.QueryAuditEvents)
+
+	if filter.UserUID != uuid.Nil {
+		query.WriteString(" AND user_uid = ?")
+		args = append(args, filter.UserUID)
+	}
+	if len(filter.Types) > 0 {
+		query.WriteString(" AND event_type IN (?" + strings.Repeat(", ?", len(filter.Types)-1) + ")")
+		for _, t := range filter.Types {
+			args = append(args, t)
+		}
+	}
+	if filter.IP != "" {
+		query.WriteString(" AND ip = ?")
+		args = append(args, filter.IP)
+	}
+	if !filter.Since.IsZero() {
+		query.WriteString(" AND created_at >= ?")
+		args = append(args, filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query.WriteString(" AND created_at < ?")
+		args = append(args, filter.Until)
+	}
+	query.WriteString(" ORDER BY created_at DESC LIMIT ?")
+	args = append(args, filter.Limit)
+
+	rows, err := db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return []*types.AuditEvent{}, err
+	}
+	defer rows.Close()
+
+	return scanAuditEvents(rows)
+}
+
+// scanAuditEvents scans rows selected with the audit event columns.
+func scanAuditEvents(rows *sql.Rows) ([]*types.AuditEvent, error) {
+	events := []*types.AuditEvent{}
+	for rows.Next() {
+		var (
+			event    = &types.AuditEvent{}
+			userUID  uuid.NullUUID
+			metadata []byte
+		)
+		err := rows.Scan(&event.UID, &userUID, &event.ActorUID, &event.Type, &event.IP, &event.UserAgent, &event.RequestID, &metadata, &event.CreatedAt)
+		if err != nil {
+			return events, err
+		}
+
+		event.UserUID = userUID.UUID
+		if len(metadata) > 0 {
+			if err = json.Unmarshal(metadata, &event.Metadata); err != nil {
+				return events, err
+			}
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
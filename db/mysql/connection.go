@@ -1,75 +1,220 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
-	"sync"
-
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/XSAM/otelsql"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/tracing"
+	"github.com/coderero/erochat-server/types"
 	_ "github.com/go-sql-driver/mysql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-type ConnectionPool struct {
-	// Database connection pool.
-	pool *sql.DB
+// mysqlErrDuplicateEntry is the MySQL error number of a unique key violation.
+const mysqlErrDuplicateEntry = 1062
+
+// DBConfig is the configuration of the MySQL databases.
+type DBConfig struct {
+	// DSN is the data source name of the primary.
+	DSN string
+
+	// ReplicaDSNs are the data source names of the read replicas.
+	ReplicaDSNs []string
 
-	// Maximum number of connections in the pool.
-	maxConnections int
+	// MaxOpenConns is the maximum number of open connections of every
+	// database, the queries wait for a free connection beyond it.
+	MaxOpenConns int
 
-	// Number of connections currently in the pool.
-	conns int
+	// MaxIdleConns is the maximum number of idle connections of every
+	// database.
+	MaxIdleConns int
 
-	// Mutex to protect the pool.
-	mu *sync.Mutex
+	// ConnMaxLifetime is how long a connection is reused, 0 reuses it forever.
+	ConnMaxLifetime time.Duration
+
+	// ConnMaxIdleTime is how long a connection stays idle, 0 keeps it.
+	ConnMaxIdleTime time.Duration
 }
 
-var (
-	// ErrMaxConnections is returned when the maximum number of connections in the pool is reached.
-	ErrMaxConnections = errors.New("maximum number of connections in the pool is reached")
-)
+// DB is the MySQL database of the stores, a primary and its read replicas.
+//
+// The connections are pooled by database/sql, a query waits for a free
+// connection until its context is done.
+type DB struct {
+	// primary takes the writes and the reads that must see them.
+	primary *sql.DB
 
-func NewConnectionPool(dsn string, maxConnections int) (*ConnectionPool, error) {
-	// Create a new connection pool.
-	pool, err := sql.Open("mysql", dsn)
+	// replicas take the reads, in turn.
+	replicas []*sql.DB
+
+	// next is the turn of the replicas.
+	next atomic.Uint64
+}
+
+// NewDB opens the primary and the replicas and checks they're reachable.
+func NewDB(config DBConfig) (*DB, error) {
+	primary, err := open(config.DSN, config)
 	if err != nil {
 		return nil, err
 	}
+	db := &DB{primary: primary}
+
+	for i, dsn := range config.ReplicaDSNs {
+		replica, err := open(dsn, config)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		db.replicas = append(db.replicas, replica)
+	}
 
-	err = pool.Ping()
+	return db, nil
+}
+
+// open opens a database, the statements run within a trace get their own
+// spans.
+func open(dsn string, config DBConfig) (*sql.DB, error) {
+	db, err := otelsql.Open("mysql", dsn,
+		otelsql.WithAttributes(semconv.DBSystemMySQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitConnectorConnect: true,
+			OmitRows:             true,
+			DisableErrSkip:       true,
+			RecordError: func(err error) bool {
+				return !errors.Is(err, sql.ErrNoRows)
+			},
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return tracing.Traced(ctx)
+			},
+		}),
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	// Set the maximum number of connections in the pool.
-	pool.SetMaxOpenConns(maxConnections)
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Primary returns the primary, for the writes and the reads that must see
+// them.
+func (db *DB) Primary() *sql.DB {
+	return db.primary
+}
+
+// Reader returns the database serving the next read, the replicas in turn
+// or the primary without replicas.
+//
+// Replicas may lag behind the primary, Reader is only for the listings and
+// searches that tolerate it. The reads deciding an authentication or an
+// authorization, and the reads following a write of the same request, use
+// Primary.
+func (db *DB) Reader() *sql.DB {
+	if len(db.replicas) == 0 {
+		return db.primary
+	}
+	return db.replicas[(db.next.Add(1)-1)%uint64(len(db.replicas))]
+}
+
+// InTx runs a function in a transaction of the primary. The transaction is
+// committed when the function returns nil and rolled back otherwise, or
+// when it panics.
+func (db *DB) InTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.primary.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-	return &ConnectionPool{
-		pool:           pool,
-		maxConnections: maxConnections,
-		conns:          0,
-		mu:             &sync.Mutex{},
-	}, nil
+// Close closes the databases, it waits for the running queries to finish.
+func (db *DB) Close() error {
+	err := db.primary.Close()
+	for _, replica := range db.replicas {
+		err = errors.Join(err, replica.Close())
+	}
+	return err
 }
 
-func (p *ConnectionPool) Get() (*sql.DB, error) {
-	// Lock the pool.
-	p.mu.Lock()
-	defer p.mu.Unlock()
+// Check pings the primary and the replicas.
+func (db *DB) Check(ctx context.Context) error {
+	if err := db.primary.PingContext(ctx); err != nil {
+		return err
+	}
+	for i, replica := range db.replicas {
+		if err := replica.PingContext(ctx); err != nil {
+			return fmt.Errorf("replica %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Stats returns the statistics of the connection pool of the primary.
+func (db *DB) Stats() types.PoolStats {
+	return poolStats(db.primary)
+}
 
-	// If the number of connections in the pool is less than the maximum number of connections, create a new connection.
-	if p.conns < p.maxConnections {
-		p.conns++
-		return p.pool, nil
+// Replicas returns the statistics providers of the connection pools of the
+// replicas.
+func (db *DB) Replicas() []interfaces.PoolStatsProvider {
+	providers := make([]interfaces.PoolStatsProvider, 0, len(db.replicas))
+	for _, replica := range db.replicas {
+		providers = append(providers, replicaPool{replica})
 	}
+	return providers
+}
 
-	// If the number of connections in the pool is equal to the maximum number of connections, return an error.
-	return nil, ErrMaxConnections
+// replicaPool provides the statistics of the connection pool of a replica.
+type replicaPool struct {
+	db *sql.DB
 }
 
-func (p *ConnectionPool) Release() {
-	// Lock the pool.
-	p.mu.Lock()
-	defer p.mu.Unlock()
+// Stats returns the statistics of the connection pool.
+func (p replicaPool) Stats() types.PoolStats {
+	return poolStats(p.db)
+}
 
-	// Decrement the number of connections in the pool.
-	p.conns--
+// poolStats returns the statistics of the connection pool of a database.
+func poolStats(db *sql.DB) types.PoolStats {
+	stats := db.Stats()
+	return types.PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration.String(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
 }
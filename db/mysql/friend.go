@@ -1,39 +1,44 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/coderero/erochat-server/db/mysql/queries"
 	"github.com/coderero/erochat-server/interfaces"
 	"github.com/coderero/erochat-server/types"
+	driver "github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 )
 
 // FriendStore is a MySQL data store for friend.
 type FriendStore struct {
-	// ConnectionPool is a pool of connections to the database.
-	pool *ConnectionPool
+	// db is the database of the store.
+	db *DB
+
+	// statusTTL is how long a status is shown to friends before it expires.
+	statusTTL time.Duration
 }
 
 // NewFriendStore creates a new FriendStore.
-func NewFriendStore(pool *ConnectionPool) *FriendStore {
+func NewFriendStore(db *DB, statusTTL time.Duration) *FriendStore {
 	return &FriendStore{
-		pool: pool,
+		db:        db,
+		statusTTL: statusTTL,
 	}
 }
 
 // GetFriends gets the friends of a user.
-func (s *FriendStore) GetFriends(userID uuid.UUID) ([]*types.Friend, error) {
+func (s *FriendStore) GetFriends(ctx context.Context, userID uuid.UUID) ([]*types.Friend, error) {
 	var friends []*types.Friend
 	friends = []*types.Friend{}
-	db, err := s.pool.Get()
-	if err != nil {
-		return friends, err
-	}
-	defer s.pool.Release()
+	db := s.db.Reader()
 
-	rows, err := db.Query(queries.GetFriends, userID)
+	rows, err := db.QueryContext(ctx, queries.GetFriends, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return friends, interfaces.ErrFriendNotFound
@@ -44,7 +49,7 @@ func (s *FriendStore) GetFriends(userID uuid.UUID) ([]*types.Friend, error) {
 
 	for rows.Next() {
 		friend := &types.Friend{}
-		err = rows.Scan(&friend.RID, &friend.UID, &friend.Username, &friend.FirstName, &friend.LastName, &friend.Bio, &friend.Avatar, &friend.AcceptedAt)
+		err = scanFriend(rows, friend)
 		if err != nil {
 			return friends, err
 		}
@@ -54,15 +59,11 @@ func (s *FriendStore) GetFriends(userID uuid.UUID) ([]*types.Friend, error) {
 }
 
 // GetFriend gets a friend by its id.
-func (s *FriendStore) GetFriend(userID uuid.UUID, friendID uuid.UUID) (*types.Friend, error) {
-	db, err := s.pool.Get()
-	if err != nil {
-		return nil, err
-	}
-	defer s.pool.Release()
+func (s *FriendStore) GetFriend(ctx context.Context, userID uuid.UUID, friendID uuid.UUID) (*types.Friend, error) {
+	db := s.db.Reader()
 
 	friend := &types.Friend{}
-	err = db.QueryRow(queries.GetFriend, userID, friendID).Scan(&friend.RID, &friend.UID, &friend.Username, &friend.FirstName, &friend.LastName, &friend.Bio, &friend.Avatar, &friend.AcceptedAt)
+	err := scanFriend(db.QueryRowContext(ctx, queries.GetFriend, userID, friendID), friend)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, interfaces.ErrFriendNotFound
@@ -73,14 +74,10 @@ func (s *FriendStore) GetFriend(userID uuid.UUID, friendID uuid.UUID) (*types.Fr
 }
 
 // DeleteFriend deletes a friend by its id.
-func (s *FriendStore) DeleteFriend(userID, fID uuid.UUID) error {
-	db, err := s.pool.Get()
-	if err != nil {
-		return err
-	}
-	defer s.pool.Release()
+func (s *FriendStore) DeleteFriend(ctx context.Context, userID, fID uuid.UUID) error {
+	db := s.db.Primary()
 
-	a, err := db.Exec(queries.DeleteFriend, userID, fID, userID, fID)
+	a, err := db.ExecContext(ctx, queries.DeleteFriend, userID, fID, userID, fID)
 	if err != nil {
 		return err
 	}
@@ -92,15 +89,11 @@ func (s *FriendStore) DeleteFriend(userID, fID uuid.UUID) error {
 }
 
 // GetFriendRequests gets the friend requests of a user.
-func (s *FriendStore) GetFriendRequests(userID uuid.UUID) ([]*types.Friend, error) {
+func (s *FriendStore) GetFriendRequests(ctx context.Context, userID uuid.UUID) ([]*types.Friend, error) {
 	var friends []*types.Friend
-	db, err := s.pool.Get()
-	if err != nil {
-		return friends, err
-	}
-	defer s.pool.Release()
+	db := s.db.Reader()
 
-	rows, err := db.Query(queries.GetFriendRequests, userID)
+	rows, err := db.QueryContext(ctx, queries.GetFriendRequests, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return friends, interfaces.ErrFriendNotFound
@@ -112,7 +105,7 @@ func (s *FriendStore) GetFriendRequests(userID uuid.UUID) ([]*types.Friend, erro
 	friends = []*types.Friend{}
 	for rows.Next() {
 		friend := &types.Friend{}
-		err = rows.Scan(&friend.RID, &friend.UID, &friend.Username, &friend.FirstName, &friend.LastName, &friend.Bio, &friend.Avatar, &friend.AcceptedAt)
+		err = scanFriend(rows, friend)
 		if err != nil {
 			return friends, err
 		}
@@ -121,16 +114,36 @@ func (s *FriendStore) GetFriendRequests(userID uuid.UUID) ([]*types.Friend, erro
 	return friends, nil
 }
 
-// GetFriendRequest gets a friend request by its id.
-func (s *FriendStore) GetFriendRequest(userID, uid uuid.UUID) (*types.Friend, error) {
-	db, err := s.pool.Get()
+// GetFriendRequestsByDirection gets the incoming or outgoing friend requests of a user.
+func (s *FriendStore) GetFriendRequestsByDirection(ctx context.Context, userID uuid.UUID, direction types.FriendRequestDirection) ([]*types.Friend, error) {
+	var friends []*types.Friend
+	friends = []*types.Friend{}
+	db := s.db.Reader()
+
+	rows, err := db.QueryContext(ctx, queries.GetFriendRequestsByDirection, userID, direction == types.FriendRequestOutgoing)
 	if err != nil {
-		return nil, err
+		return friends, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		friend := &types.Friend{}
+		err = scanFriend(rows, friend)
+		if err != nil {
+			return friends, err
+		}
+		friends = append(friends, friend)
 	}
-	defer s.pool.Release()
+	return friends, nil
+}
+
+// GetFriendRequest gets a friend request by its id. It reads the primary,
+// the request is acted on right after it's sent.
+func (s *FriendStore) GetFriendRequest(ctx context.Context, userID, uid uuid.UUID) (*types.Friend, error) {
+	db := s.db.Primary()
 
 	friend := &types.Friend{}
-	err = db.QueryRow(queries.GetFriendRequest, userID, uid).Scan(&friend.RID, &friend.UID, &friend.Username, &friend.FirstName, &friend.LastName, &friend.Bio, &friend.Avatar, &friend.AcceptedAt)
+	err := scanFriend(db.QueryRowContext(ctx, queries.GetFriendRequest, userID, uid), friend)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, interfaces.ErrFriendNotFound
@@ -141,14 +154,25 @@ func (s *FriendStore) GetFriendRequest(userID, uid uuid.UUID) (*types.Friend, er
 }
 
 // AcceptFriendRequest accepts a friend request.
-func (s *FriendStore) AcceptFriendRequest(userUID, uid uuid.UUID) error {
-	db, err := s.pool.Get()
+func (s *FriendStore) AcceptFriendRequest(ctx context.Context, userUID, uid uuid.UUID) error {
+	db := s.db.Primary()
+
+	a, err := db.ExecContext(ctx, queries.AcceptFriendRequest, userUID, uid)
 	if err != nil {
 		return err
 	}
-	defer s.pool.Release()
 
-	a, err := db.Exec(queries.AcceptFriendRequest, userUID, userUID, uid)
+	if n, err := a.RowsAffected(); err != nil || n == 0 {
+		return interfaces.ErrFriendNotFound
+	}
+	return nil
+}
+
+// CancelFriendRequest cancels a friend request, only its sender can cancel it.
+func (s *FriendStore) CancelFriendRequest(ctx context.Context, userID, reqID uuid.UUID) error {
+	db := s.db.Primary()
+
+	a, err := db.ExecContext(ctx, queries.CancelFriendRequest, userID, reqID)
 	if err != nil {
 		return err
 	}
@@ -159,15 +183,50 @@ func (s *FriendStore) AcceptFriendRequest(userUID, uid uuid.UUID) error {
 	return nil
 }
 
-// DeleteFriendRequest deletes a friend request by its id.
-func (s *FriendStore) DeleteFriendRequest(userID, reqID uuid.UUID) error {
-	db, err := s.pool.Get()
+// DeclineFriendRequest declines a friend request, only its recipient can decline it.
+// New requests from the sender are suppressed until the cooldown ends.
+func (s *FriendStore) DeclineFriendRequest(ctx context.Context, userID, reqID uuid.UUID, cooldown time.Duration) error {
+	return s.db.InTx(ctx, func(tx *sql.Tx) error {
+		var senderID string
+		err := tx.QueryRowContext(ctx, queries.GetReceivedFriendRequestSender, userID, reqID).Scan(&senderID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return interfaces.ErrFriendNotFound
+			}
+			return err
+		}
+
+		if _, err = tx.ExecContext(ctx, queries.DeleteFriendRequestByID, reqID); err != nil {
+			return err
+		}
+
+		if cooldown > 0 {
+			if _, err = tx.ExecContext(ctx, queries.UpsertFriendRequestDecline, senderID, userID, int64(cooldown.Seconds())); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// IsFriendRequestSuppressed reports whether the recipient declined a request
+// from the sender less than a cooldown ago.
+func (s *FriendStore) IsFriendRequestSuppressed(ctx context.Context, senderID, recipientID uuid.UUID) (bool, error) {
+	db := s.db.Primary()
+
+	var suppressed bool
+	err := db.QueryRowContext(ctx, queries.IsFriendRequestSuppressed, senderID, recipientID).Scan(&suppressed)
 	if err != nil {
-		return err
+		return false, err
 	}
-	defer s.pool.Release()
+	return suppressed, nil
+}
+
+// DeleteFriendRequest deletes a friend request by its id.
+func (s *FriendStore) DeleteFriendRequest(ctx context.Context, userID, reqID uuid.UUID) error {
+	db := s.db.Primary()
 
-	a, err := db.Exec(queries.DeleteFriendRequest, userID, userID, reqID)
+	a, err := db.ExecContext(ctx, queries.DeleteFriendRequest, userID, userID, reqID)
 	if err != nil {
 		return err
 	}
@@ -179,17 +238,25 @@ func (s *FriendStore) DeleteFriendRequest(userID, reqID uuid.UUID) error {
 	return nil
 }
 
-// GetFriendsStatus gets the friends status of a user.
-func (s *FriendStore) GetFriendsStatus(userID uuid.UUID) ([]*types.FriendStatus, error) {
+// GetFriendsStatus gets the friends status of a user, the statuses of muted
+// friends are omitted unless the options include them.
+func (s *FriendStore) GetFriendsStatus(ctx context.Context, userID uuid.UUID, opts types.FriendsStatusOptions) ([]*types.FriendStatus, error) {
 	var friends []*types.FriendStatus
 	friends = []*types.FriendStatus{}
-	db, err := s.pool.Get()
+	db := s.db.Reader()
+
+	// The mutes are read first, the statuses hold their connection until
+	// they're all scanned.
+	mutedUIDs, err := queryUIDs(ctx, db, queries.GetMutedUIDs, userID)
 	if err != nil {
 		return friends, err
 	}
-	defer s.pool.Release()
+	muted := make(map[uuid.UUID]bool, len(mutedUIDs))
+	for _, uid := range mutedUIDs {
+		muted[uid] = true
+	}
 
-	rows, err := db.Query(queries.GetFriendsStatus, userID)
+	rows, err := db.QueryContext(ctx, queries.GetFriendsStatus, userID, int(s.statusTTL.Seconds()))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return friends, interfaces.ErrFriendNotFound
@@ -200,30 +267,298 @@ func (s *FriendStore) GetFriendsStatus(userID uuid.UUID) ([]*types.FriendStatus,
 
 	for rows.Next() {
 		friend := &types.FriendStatus{}
-		err = rows.Scan(&friend.RID, &friend.UID, &friend.StatusID, &friend.Username, &friend.FirstName, &friend.LastName, &friend.Avatar, &friend.Title, &friend.ResourceURI, &friend.ResourceThumbnail)
+		err = rows.Scan(&friend.RID, &friend.UID, &friend.StatusID, &friend.Username, &friend.FirstName, &friend.LastName, &friend.Avatar, &friend.Title, &friend.ResourceURI, &friend.ResourceThumbnail, &friend.Seen)
 		if err != nil {
 			return friends, err
 		}
+		friend.Muted = muted[friend.UID]
+		if friend.Muted && !opts.IncludeMuted {
+			continue
+		}
 		friends = append(friends, friend)
 	}
-	return friends, nil
-}
+	if err = rows.Err(); err != nil {
+		return friends, err
+	}
 
-// GetFriendStatus gets the friend status of a user.
-func (s *FriendStore) GetFriendStatus(userID uuid.UUID, friendID uuid.UUID) (*types.FriendStatus, error) {
-	db, err := s.pool.Get()
-	if err != nil {
-		return nil, err
+	// The result of the CALL is released before the items are read, Next
+	// leaves it open on the status result that follows the rows.
+	if err = rows.Close(); err != nil {
+		return friends, err
 	}
-	defer s.pool.Release()
+	return friends, attachFriendStatusItems(ctx, db, friends...)
+}
+
+// GetFriendStatus gets the friend status of a user. It reads the primary, a
+// block or a change of audience must hide the status at once.
+func (s *FriendStore) GetFriendStatus(ctx context.Context, userID uuid.UUID, friendID uuid.UUID) (*types.FriendStatus, error) {
+	db := s.db.Primary()
 
 	friend := &types.FriendStatus{}
-	err = db.QueryRow(queries.GetFriendStatus, userID, friendID).Scan(&friend.RID, &friend.UID, &friend.StatusID, &friend.Username, &friend.FirstName, &friend.LastName, &friend.Avatar, &friend.Title, &friend.ResourceURI, &friend.ResourceThumbnail)
+	err := db.QueryRowContext(ctx, queries.GetFriendStatus, userID, friendID, int(s.statusTTL.Seconds())).Scan(&friend.RID, &friend.UID, &friend.StatusID, &friend.Username, &friend.FirstName, &friend.LastName, &friend.Avatar, &friend.Title, &friend.ResourceURI, &friend.ResourceThumbnail, &friend.Seen)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, interfaces.ErrFriendStatusNotFound
 		}
 		return nil, err
 	}
-	return friend, nil
+	return friend, attachFriendStatusItems(ctx, db, friend)
+}
+
+// BlockUser blocks a user and removes any friendship or pending request between them.
+func (s *FriendStore) BlockUser(ctx context.Context, userID, blockedID uuid.UUID) error {
+	return s.db.InTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, queries.CreateBlock, userID, blockedID); err != nil {
+			var sqlErr *driver.MySQLError
+			if errors.As(err, &sqlErr) && sqlErr.Number == mysqlErrDuplicateEntry {
+				return interfaces.ErrAlreadyBlocked
+			}
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, queries.DeleteFriendshipsBetween, userID, blockedID, blockedID, userID)
+		return err
+	})
+}
+
+// UnblockUser unblocks a user.
+func (s *FriendStore) UnblockUser(ctx context.Context, userID, blockedID uuid.UUID) error {
+	db := s.db.Primary()
+
+	a, err := db.ExecContext(ctx, queries.DeleteBlock, userID, blockedID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := a.RowsAffected(); err != nil || n == 0 {
+		return interfaces.ErrBlockNotFound
+	}
+	return nil
+}
+
+// GetBlockedUsers gets the users blocked by a user.
+func (s *FriendStore) GetBlockedUsers(ctx context.Context, userID uuid.UUID) ([]*types.BlockedUser, error) {
+	var blocked []*types.BlockedUser
+	blocked = []*types.BlockedUser{}
+	db := s.db.Reader()
+
+	rows, err := db.QueryContext(ctx, queries.GetBlockedUsers, userID)
+	if err != nil {
+		return blocked, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := &types.BlockedUser{}
+		err = rows.Scan(&user.UID, &user.Username, &user.FirstName, &user.LastName, &user.Avatar, &user.BlockedAt)
+		if err != nil {
+			return blocked, err
+		}
+		blocked = append(blocked, user)
+	}
+	return blocked, nil
+}
+
+// MuteStatus mutes the statuses of a friend, muting twice is a no-op.
+func (s *FriendStore) MuteStatus(ctx context.Context, userID, friendID uuid.UUID) error {
+	db := s.db.Primary()
+
+	_, err := db.ExecContext(ctx, queries.CreateStatusMute, userID, friendID)
+	return err
+}
+
+// UnmuteStatus unmutes the statuses of a friend.
+func (s *FriendStore) UnmuteStatus(ctx context.Context, userID, friendID uuid.UUID) error {
+	db := s.db.Primary()
+
+	a, err := db.ExecContext(ctx, queries.DeleteStatusMute, userID, friendID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := a.RowsAffected(); err != nil || n == 0 {
+		return interfaces.ErrMuteNotFound
+	}
+	return nil
+}
+
+// GetMutedFriends gets the friends whose statuses are muted by a user.
+func (s *FriendStore) GetMutedFriends(ctx context.Context, userID uuid.UUID) ([]*types.MutedFriend, error) {
+	var muted []*types.MutedFriend
+	muted = []*types.MutedFriend{}
+	db := s.db.Reader()
+
+	rows, err := db.QueryContext(ctx, queries.GetMutedFriends, userID)
+	if err != nil {
+		return muted, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		friend := &types.MutedFriend{}
+		err = rows.Scan(&friend.UID, &friend.Username, &friend.FirstName, &friend.LastName, &friend.Avatar, &friend.MutedAt)
+		if err != nil {
+			return muted, err
+		}
+		muted = append(muted, friend)
+	}
+	return muted, rows.Err()
+}
+
+// IsBlocked reports whether either user has blocked the other. It reads the
+// primary so a block applies at once.
+func (s *FriendStore) IsBlocked(ctx context.Context, userID, otherID uuid.UUID) (bool, error) {
+	db := s.db.Primary()
+
+	var blocked bool
+	err := db.QueryRowContext(ctx, queries.IsBlocked, userID, otherID, otherID, userID).Scan(&blocked)
+	if err != nil {
+		return false, err
+	}
+	return blocked, nil
+}
+
+// AreFriends reports whether two users are friends, from the primary like
+// IsBlocked.
+func (s *FriendStore) AreFriends(ctx context.Context, userID, otherID uuid.UUID) (bool, error) {
+	db := s.db.Primary()
+
+	var friends bool
+	err := db.QueryRowContext(ctx, queries.AreFriends, userID, otherID, otherID, userID).Scan(&friends)
+	if err != nil {
+		return false, err
+	}
+	return friends, nil
+}
+
+// HaveMutualFriend reports whether two users share a friend.
+func (s *FriendStore) HaveMutualFriend(ctx context.Context, userID, otherID uuid.UUID) (bool, error) {
+	db := s.db.Primary()
+
+	var mutual bool
+	err := db.QueryRowContext(ctx, queries.HaveMutualFriend, otherID, userID, userID, userID, otherID, otherID).Scan(&mutual)
+	if err != nil {
+		return false, err
+	}
+	return mutual, nil
+}
+
+// scanFriend scans a row returned by the friend procedures into a friend.
+func scanFriend(row scanner, friend *types.Friend) error {
+	var direction sql.NullString
+	err := row.Scan(&friend.RID, &friend.UID, &friend.Username, &friend.FirstName, &friend.LastName, &friend.Bio, &friend.Avatar, &friend.AcceptedAt, &direction)
+	if err != nil {
+		return err
+	}
+	friend.Direction = types.FriendRequestDirection(direction.String)
+	return nil
+}
+
+const (
+	// mutualFriendPreviews is the number of mutual friends previewed per suggestion.
+	mutualFriendPreviews = 3
+
+	// maxFriendSuggestions is the number of suggestions precomputed per user.
+	maxFriendSuggestions = 100
+
+	// friendSuggestionsBatchSize is the number of users whose suggestions are
+	// recomputed per transaction.
+	friendSuggestionsBatchSize = 500
+)
+
+// GetFriendSuggestions gets a page of the precomputed friend suggestions of
+// a user, ranked by mutual friend count.
+func (s *FriendStore) GetFriendSuggestions(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*types.FriendSuggestion, error) {
+	var suggestions []*types.FriendSuggestion
+	suggestions = []*types.FriendSuggestion{}
+	db := s.db.Reader()
+
+	rows, err := db.QueryContext(ctx, queries.GetFriendSuggestions, userID, limit, offset)
+	if err != nil {
+		return suggestions, err
+	}
+	defer rows.Close()
+
+	byUID := map[uuid.UUID]*types.FriendSuggestion{}
+	for rows.Next() {
+		suggestion := &types.FriendSuggestion{MutualFriends: []*types.MutualFriend{}}
+		err = rows.Scan(&suggestion.UID, &suggestion.Username, &suggestion.FirstName, &suggestion.LastName, &suggestion.Avatar, &suggestion.MutualCount)
+		if err != nil {
+			return suggestions, err
+		}
+		suggestions = append(suggestions, suggestion)
+		byUID[suggestion.UID] = suggestion
+	}
+	if err = rows.Err(); err != nil || len(suggestions) == 0 {
+		return suggestions, err
+	}
+
+	// Load the mutual friend previews of the whole page at once.
+	args := []any{userID, userID, userID}
+	for _, suggestion := range suggestions {
+		args = append(args, suggestion.UID)
+	}
+	query := fmt.Sprintf(queries.GetMutualFriends, "?"+strings.Repeat(", ?", len(suggestions)-1))
+
+	mutualRows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return suggestions, err
+	}
+	defer mutualRows.Close()
+
+	for mutualRows.Next() {
+		var (
+			otherUID uuid.UUID
+			mutual   = &types.MutualFriend{}
+		)
+		err = mutualRows.Scan(&otherUID, &mutual.UID, &mutual.Username, &mutual.FirstName, &mutual.Avatar)
+		if err != nil {
+			return suggestions, err
+		}
+		if suggestion, ok := byUID[otherUID]; ok && len(suggestion.MutualFriends) < mutualFriendPreviews {
+			suggestion.MutualFriends = append(suggestion.MutualFriends, mutual)
+		}
+	}
+	return suggestions, mutualRows.Err()
+}
+
+// RefreshFriendSuggestions recomputes the friend suggestions of every user.
+func (s *FriendStore) RefreshFriendSuggestions(ctx context.Context) error {
+	db := s.db.Primary()
+
+	_, err := db.ExecContext(ctx, queries.RefreshFriendSuggestions, maxFriendSuggestions, friendSuggestionsBatchSize)
+	return err
+}
+
+// GetFriendsOfFriends returns the uids of the friends of the friends of a user.
+func (s *FriendStore) GetFriendsOfFriends(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	db := s.db.Reader()
+
+	return queryUIDs(ctx, db, queries.GetFriendsOfFriends, userID, userID, userID)
+}
+
+// GetBlockRelations returns the uids of the users blocked by or blocking a user.
+func (s *FriendStore) GetBlockRelations(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	db := s.db.Reader()
+
+	return queryUIDs(ctx, db, queries.GetBlockRelations, userID, userID)
+}
+
+// queryUIDs runs a query selecting a single uid column.
+func queryUIDs(ctx context.Context, db *sql.DB, query string, args ...any) ([]uuid.UUID, error) {
+	uids := []uuid.UUID{}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return uids, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var uid uuid.UUID
+		if err = rows.Scan(&uid); err != nil {
+			return uids, err
+		}
+		uids = append(uids, uid)
+	}
+	return uids, rows.Err()
 }
@@ -0,0 +1,224 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/coderero/erochat-server/db/mysql/queries"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+)
+
+// FriendListStore is a MySQL data store for friend lists.
+type FriendListStore struct {
+	// db is the database of the store.
+	db *DB
+}
+
+// NewFriendListStore creates a new FriendListStore.
+func NewFriendListStore(db *DB) *FriendListStore {
+	return &FriendListStore{
+		db: db,
+	}
+}
+
+// Create creates a new friend list.
+func (s *FriendListStore) Create(ctx context.Context, list *types.FriendList) (*types.FriendList, error) {
+	db := s.db.Primary()
+
+	result, err := db.ExecContext(ctx, queries.CreateFriendList, list.OwnerUID, list.Name)
+	if err != nil {
+		return nil, checkFriendListConstraint(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	created := &types.FriendList{}
+	if err = scanFriendList(db.QueryRowContext(ctx, queries.GetFriendListByID, id), created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetByOwner returns the friend lists of a user.
+func (s *FriendListStore) GetByOwner(ctx context.Context, ownerID uuid.UUID) ([]*types.FriendList, error) {
+	var lists []*types.FriendList
+	lists = []*types.FriendList{}
+	db := s.db.Reader()
+
+	rows, err := db.QueryContext(ctx, queries.GetFriendListsByOwner, ownerID)
+	if err != nil {
+		return lists, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		list := &types.FriendList{}
+		if err = scanFriendList(rows, list); err != nil {
+			return lists, err
+		}
+		lists = append(lists, list)
+	}
+	return lists, rows.Err()
+}
+
+// Get returns a friend list of a user. It reads the primary, a status may be
+// posted to a list right after it's created.
+func (s *FriendListStore) Get(ctx context.Context, ownerID, listID uuid.UUID) (*types.FriendList, error) {
+	db := s.db.Primary()
+
+	return getFriendList(ctx, db, ownerID, listID)
+}
+
+// Rename renames a friend list of a user.
+func (s *FriendListStore) Rename(ctx context.Context, ownerID, listID uuid.UUID, name string) error {
+	db := s.db.Primary()
+
+	if _, err := getFriendList(ctx, db, ownerID, listID); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, queries.RenameFriendList, name, ownerID, listID); err != nil {
+		return checkFriendListConstraint(err)
+	}
+	return nil
+}
+
+// Delete deletes a friend list of a user and its members.
+func (s *FriendListStore) Delete(ctx context.Context, ownerID, listID uuid.UUID) error {
+	return s.db.InTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, queries.DeleteFriendListMembers, ownerID, listID); err != nil {
+			return err
+		}
+
+		a, err := tx.ExecContext(ctx, queries.DeleteFriendList, ownerID, listID)
+		if err != nil {
+			return err
+		}
+		if n, err := a.RowsAffected(); err != nil || n == 0 {
+			return interfaces.ErrFriendListNotFound
+		}
+		return nil
+	})
+}
+
+// GetMembers returns the members of a friend list of a user.
+func (s *FriendListStore) GetMembers(ctx context.Context, ownerID, listID uuid.UUID) ([]*types.FriendListMember, error) {
+	db := s.db.Reader()
+
+	if _, err := getFriendList(ctx, db, ownerID, listID); err != nil {
+		return nil, err
+	}
+
+	return queryFriendListMembers(ctx, db, queries.GetFriendListMembers, listID)
+}
+
+// AddMember adds a user to a friend list, adding a member twice is a no-op.
+func (s *FriendListStore) AddMember(ctx context.Context, ownerID, listID, memberID uuid.UUID) error {
+	db := s.db.Primary()
+
+	if _, err := getFriendList(ctx, db, ownerID, listID); err != nil {
+		return err
+	}
+
+	_, err := db.ExecContext(ctx, queries.AddFriendListMember, listID, memberID)
+	return err
+}
+
+// RemoveMember removes a user from a friend list.
+func (s *FriendListStore) RemoveMember(ctx context.Context, ownerID, listID, memberID uuid.UUID) error {
+	db := s.db.Primary()
+
+	a, err := db.ExecContext(ctx, queries.RemoveFriendListMember, ownerID, listID, memberID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := a.RowsAffected(); err != nil || n == 0 {
+		return interfaces.ErrFriendListMemberNotFound
+	}
+	return nil
+}
+
+// GetHiddenFrom returns the users a user hides their statuses from.
+func (s *FriendListStore) GetHiddenFrom(ctx context.Context, ownerID uuid.UUID) ([]*types.FriendListMember, error) {
+	db := s.db.Reader()
+
+	return queryFriendListMembers(ctx, db, queries.GetStatusHiddenFrom, ownerID)
+}
+
+// HideFrom hides the statuses of a user from another, hiding twice is a no-op.
+func (s *FriendListStore) HideFrom(ctx context.Context, ownerID, userID uuid.UUID) error {
+	db := s.db.Primary()
+
+	_, err := db.ExecContext(ctx, queries.HideStatusFrom, ownerID, userID)
+	return err
+}
+
+// UnhideFrom shows the statuses of a user to another again.
+func (s *FriendListStore) UnhideFrom(ctx context.Context, ownerID, userID uuid.UUID) error {
+	db := s.db.Primary()
+
+	a, err := db.ExecContext(ctx, queries.UnhideStatusFrom, ownerID, userID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := a.RowsAffected(); err != nil || n == 0 {
+		return interfaces.ErrFriendListMemberNotFound
+	}
+	return nil
+}
+
+// getFriendList returns a friend list of a user.
+func getFriendList(ctx context.Context, db *sql.DB, ownerID, listID uuid.UUID) (*types.FriendList, error) {
+	list := &types.FriendList{}
+	err := scanFriendList(db.QueryRowContext(ctx, queries.GetFriendList, ownerID, listID), list)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, interfaces.ErrFriendListNotFound
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+// queryFriendListMembers runs a query selecting friend list members.
+func queryFriendListMembers(ctx context.Context, db *sql.DB, query string, args ...any) ([]*types.FriendListMember, error) {
+	members := []*types.FriendListMember{}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return members, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		member := &types.FriendListMember{}
+		err = rows.Scan(&member.UID, &member.Username, &member.FirstName, &member.LastName, &member.Avatar, &member.AddedAt)
+		if err != nil {
+			return members, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// scanFriendList scans a row selected with the friend list columns into a list.
+func scanFriendList(row scanner, list *types.FriendList) error {
+	return row.Scan(&list.ID, &list.UID, &list.OwnerUID, &list.Name, &list.MemberCount, &list.CreatedAt)
+}
+
+// checkFriendListConstraint maps a duplicate list name to ErrFriendListExists.
+func checkFriendListConstraint(err error) error {
+	var sqlErr *driver.MySQLError
+	if errors.As(err, &sqlErr) && sqlErr.Number == mysqlErrDuplicateEntry {
+		return interfaces.ErrFriendListExists
+	}
+	return err
+}
@@ -0,0 +1,196 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/coderero/erochat-server/db/mysql/queries"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+)
+
+// HighlightStore is a MySQL data store for highlights.
+type HighlightStore struct {
+	// db is the database of the store.
+	db *DB
+}
+
+// NewHighlightStore creates a new HighlightStore.
+func NewHighlightStore(db *DB) *HighlightStore {
+	return &HighlightStore{
+		db: db,
+	}
+}
+
+// Create creates a new highlight.
+func (s *HighlightStore) Create(ctx context.Context, highlight *types.Highlight) (*types.Highlight, error) {
+	db := s.db.Primary()
+
+	result, err := db.ExecContext(ctx, queries.CreateHighlight, highlight.OwnerUID, highlight.Name)
+	if err != nil {
+		return nil, checkHighlightConstraint(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	created := &types.Highlight{Statuses: []*types.UserStatus{}}
+	if err = scanHighlight(db.QueryRowContext(ctx, queries.GetHighlightByID, id), created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetByOwner returns the highlights of a user with their statuses as seen by
+// the viewer.
+func (s *HighlightStore) GetByOwner(ctx context.Context, ownerID, viewerID uuid.UUID) ([]*types.Highlight, error) {
+	var highlights []*types.Highlight
+	highlights = []*types.Highlight{}
+	db := s.db.Reader()
+
+	rows, err := db.QueryContext(ctx, queries.GetHighlightsByOwner, ownerID)
+	if err != nil {
+		return highlights, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		highlight := &types.Highlight{}
+		if err = scanHighlight(rows, highlight); err != nil {
+			return highlights, err
+		}
+		highlights = append(highlights, highlight)
+	}
+	if err = rows.Err(); err != nil {
+		return highlights, err
+	}
+
+	for _, highlight := range highlights {
+		if ownerID == viewerID {
+			highlight.Statuses, err = queryStatuses(ctx, db, queries.GetHighlightStatuses, highlight.UID)
+		} else {
+			highlight.Statuses, err = queryStatuses(ctx, db, queries.GetVisibleHighlightStatuses, highlight.UID, viewerID)
+		}
+		if err != nil {
+			return highlights, err
+		}
+	}
+	return highlights, nil
+}
+
+// Get returns a highlight of a user with its statuses.
+func (s *HighlightStore) Get(ctx context.Context, ownerID, highlightID uuid.UUID) (*types.Highlight, error) {
+	db := s.db.Reader()
+
+	highlight, err := getHighlight(ctx, db, ownerID, highlightID)
+	if err != nil {
+		return nil, err
+	}
+
+	highlight.Statuses, err = queryStatuses(ctx, db, queries.GetHighlightStatuses, highlight.UID)
+	if err != nil {
+		return nil, err
+	}
+	return highlight, nil
+}
+
+// Rename renames a highlight of a user.
+func (s *HighlightStore) Rename(ctx context.Context, ownerID, highlightID uuid.UUID, name string) error {
+	db := s.db.Primary()
+
+	if _, err := getHighlight(ctx, db, ownerID, highlightID); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, queries.RenameHighlight, name, ownerID, highlightID); err != nil {
+		return checkHighlightConstraint(err)
+	}
+	return nil
+}
+
+// Delete deletes a highlight of a user, the statuses are kept.
+func (s *HighlightStore) Delete(ctx context.Context, ownerID, highlightID uuid.UUID) error {
+	return s.db.InTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, queries.DeleteHighlightItems, ownerID, highlightID); err != nil {
+			return err
+		}
+
+		a, err := tx.ExecContext(ctx, queries.DeleteHighlight, ownerID, highlightID)
+		if err != nil {
+			return err
+		}
+		if n, err := a.RowsAffected(); err != nil || n == 0 {
+			return interfaces.ErrHighlightNotFound
+		}
+		return nil
+	})
+}
+
+// AddStatus adds a status of the user to a highlight, adding a status twice
+// is a no-op.
+func (s *HighlightStore) AddStatus(ctx context.Context, ownerID, highlightID, statusID uuid.UUID) error {
+	db := s.db.Primary()
+
+	if _, err := getHighlight(ctx, db, ownerID, highlightID); err != nil {
+		return err
+	}
+
+	// Only the owner's own statuses can be highlighted, expired ones included.
+	status := &types.UserStatus{}
+	if err := scanStatus(db.QueryRowContext(ctx, queries.GetStatusByID, ownerID, statusID), status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return interfaces.ErrStatusNotFound
+		}
+		return err
+	}
+
+	_, err := db.ExecContext(ctx, queries.AddHighlightStatus, highlightID, statusID)
+	return err
+}
+
+// RemoveStatus removes a status from a highlight of a user.
+func (s *HighlightStore) RemoveStatus(ctx context.Context, ownerID, highlightID, statusID uuid.UUID) error {
+	db := s.db.Primary()
+
+	a, err := db.ExecContext(ctx, queries.RemoveHighlightStatus, ownerID, highlightID, statusID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := a.RowsAffected(); err != nil || n == 0 {
+		return interfaces.ErrHighlightStatusNotFound
+	}
+	return nil
+}
+
+// getHighlight returns a highlight of a user without its statuses.
+func getHighlight(ctx context.Context, db *sql.DB, ownerID, highlightID uuid.UUID) (*types.Highlight, error) {
+	highlight := &types.Highlight{}
+	err := scanHighlight(db.QueryRowContext(ctx, queries.GetHighlight, ownerID, highlightID), highlight)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, interfaces.ErrHighlightNotFound
+		}
+		return nil, err
+	}
+	return highlight, nil
+}
+
+// scanHighlight scans a row selected with the highlight columns into a highlight.
+func scanHighlight(row scanner, highlight *types.Highlight) error {
+	return row.Scan(&highlight.ID, &highlight.UID, &highlight.OwnerUID, &highlight.Name, &highlight.CreatedAt)
+}
+
+// checkHighlightConstraint maps a duplicate highlight name to ErrHighlightExists.
+func checkHighlightConstraint(err error) error {
+	var sqlErr *driver.MySQLError
+	if errors.As(err, &sqlErr) && sqlErr.Number == mysqlErrDuplicateEntry {
+		return interfaces.ErrHighlightExists
+	}
+	return err
+}
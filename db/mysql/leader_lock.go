@@ -0,0 +1,90 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/coderero/erochat-server/db/mysql/queries"
+)
+
+// LeaderLock is a leader election on a MySQL named lock.
+//
+// Named locks belong to a connection, the leader keeps a dedicated connection
+// open for as long as it leads. The lock is released by the server when the
+// connection drops, so a crashed leader is replaced on the next Acquire of
+// another instance.
+type LeaderLock struct {
+	// db is the database holding the lock.
+	db *DB
+
+	// name is the name of the lock.
+	name string
+
+	// mu protects conn.
+	mu sync.Mutex
+
+	// conn is the connection holding the lock, nil when not leading.
+	conn *sql.Conn
+}
+
+// NewLeaderLock creates a new LeaderLock.
+func NewLeaderLock(db *DB, name string) *LeaderLock {
+	return &LeaderLock{
+		db:   db,
+		name: name,
+	}
+}
+
+// Acquire tries to take the lock, or checks it's still held. It reports
+// whether this instance leads.
+func (l *LeaderLock) Acquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		var held sql.NullBool
+		err := l.conn.QueryRowContext(ctx, queries.IsLockHeld, l.name).Scan(&held)
+		if err == nil && held.Valid && held.Bool {
+			return true, nil
+		}
+
+		// The connection dropped or the lock was lost, start over.
+		l.close()
+	}
+
+	// The lock lives on the primary, replicas would each grant it.
+	conn, err := l.db.Primary().Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var taken sql.NullInt64
+	if err = conn.QueryRowContext(ctx, queries.GetLock, l.name).Scan(&taken); err != nil || taken.Int64 != 1 {
+		conn.Close()
+		return false, err
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+// Release gives up the leadership.
+func (l *LeaderLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	_, err := l.conn.ExecContext(ctx, queries.ReleaseLock, l.name)
+	l.close()
+	return err
+}
+
+// close closes the connection holding the lock.
+func (l *LeaderLock) close() {
+	l.conn.Close()
+	l.conn = nil
+}
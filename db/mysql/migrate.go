@@ -0,0 +1,261 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/coderero/erochat-server/db/migrate"
+	"github.com/coderero/erochat-server/db/mysql/queries"
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+)
+
+const (
+	// migrationLock is the named lock serializing the migrations.
+	migrationLock = "erochat_schema_migrations"
+
+	// migrationLockTimeout is how long a migration waits for another one.
+	migrationLockTimeout = time.Minute
+)
+
+// Migrator applies the versioned MySQL schema migrations.
+//
+// Migrations run on a single connection holding a named lock, so instances
+// starting together apply them once. MySQL commits DDL implicitly, a
+// migration failing halfway isn't recorded and must be fixed by hand.
+type Migrator struct {
+	// db is the database migrated, its primary.
+	db *DB
+
+	// migrations holds the migration files.
+	migrations fs.FS
+}
+
+// appliedMigration is a row of the applied migrations table.
+type appliedMigration struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// NewMigrator creates a new Migrator.
+func NewMigrator(db *DB, migrations fs.FS) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+// Up applies the pending migrations in order, it returns the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]types.MigrationStatus, error) {
+	migrations, err := migrate.Load(m.migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []types.MigrationStatus{}
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		checksums := make(map[int64]string, len(done))
+		for version, a := range done {
+			checksums[version] = a.checksum
+		}
+		pending, err := migrate.Pending(migrations, checksums)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range pending {
+			if err = execMigration(ctx, conn, migration, migration.Up); err != nil {
+				return err
+			}
+			if _, err = conn.ExecContext(ctx, queries.CreateAppliedMigration, migration.Version, migration.Name, migration.Checksum); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			applied = append(applied, types.MigrationStatus{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				State:     types.MigrationApplied,
+				AppliedAt: &now,
+			})
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last applied migrations, it returns the rolled back ones.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]types.MigrationStatus, error) {
+	migrations, err := migrate.Load(m.migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*migrate.Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	rolledBack := []types.MigrationStatus{}
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			migration := migrations[i]
+			a, ok := done[migration.Version]
+			if !ok {
+				continue
+			}
+			if a.checksum != migration.Checksum {
+				return fmt.Errorf("migrate: %d_%s: %w", migration.Version, migration.Name, migrate.ErrChecksumMismatch)
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migrate: %d_%s: %w", migration.Version, migration.Name, migrate.ErrNoDownMigration)
+			}
+
+			if err = execMigration(ctx, conn, migration, migration.Down); err != nil {
+				return err
+			}
+			if _, err = conn.ExecContext(ctx, queries.DeleteAppliedMigration, migration.Version); err != nil {
+				return err
+			}
+
+			rolledBack = append(rolledBack, types.MigrationStatus{
+				Version: migration.Version,
+				Name:    migration.Name,
+				State:   types.MigrationPending,
+			})
+		}
+
+		// Migrations applied by a newer build can't be rolled back from here.
+		for version := range done {
+			if _, ok := byVersion[version]; !ok && len(rolledBack) < steps {
+				return fmt.Errorf("migrate: version %d is unknown to this build", version)
+			}
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status returns the state of every migration.
+func (m *Migrator) Status(ctx context.Context) ([]types.MigrationStatus, error) {
+	migrations, err := migrate.Load(m.migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := m.db.Primary().Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, queries.CreateMigrationsTable); err != nil {
+		return nil, err
+	}
+	done, err := getAppliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]types.MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := types.MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+			State:   types.MigrationPending,
+		}
+		if a, ok := done[migration.Version]; ok {
+			status.State = types.MigrationApplied
+			if a.checksum != migration.Checksum {
+				status.State = types.MigrationModified
+			}
+			status.AppliedAt = &a.appliedAt
+			delete(done, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, a := range done {
+		statuses = append(statuses, types.MigrationStatus{
+			Version:   a.version,
+			Name:      a.name,
+			State:     types.MigrationUnknown,
+			AppliedAt: &a.appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// withLock runs a function on a connection holding the migration lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Primary().Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var taken sql.NullInt64
+	if err = conn.QueryRowContext(ctx, queries.WaitLock, migrationLock, int(migrationLockTimeout.Seconds())).Scan(&taken); err != nil {
+		return err
+	}
+	if taken.Int64 != 1 {
+		return interfaces.ErrMigrationLocked
+	}
+	// The lock belongs to the pooled connection, it's released even when the
+	// context is done.
+	defer conn.ExecContext(context.WithoutCancel(ctx), queries.ReleaseLock, migrationLock)
+
+	if _, err = conn.ExecContext(ctx, queries.CreateMigrationsTable); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// getAppliedMigrations returns the applied migrations by version.
+func getAppliedMigrations(ctx context.Context, conn *sql.Conn) (map[int64]*appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, queries.GetAppliedMigrations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]*appliedMigration{}
+	for rows.Next() {
+		a := &appliedMigration{}
+		if err = rows.Scan(&a.version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[a.version] = a
+	}
+	return applied, rows.Err()
+}
+
+// execMigration runs the statements of a migration file.
+func execMigration(ctx context.Context, conn *sql.Conn, migration *migrate.Migration, content string) error {
+	statements, err := migrate.Statements(content)
+	if err != nil {
+		return fmt.Errorf("migrate: %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	for i, statement := range statements {
+		if _, err = conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migrate: %d_%s: statement %d: %w", migration.Version, migration.Name, i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+DROP TABLE IF EXISTS blobs;
+
+DROP TABLE IF EXISTS status_mutes;
+
+DROP TABLE IF EXISTS status_item_mentions;
+
+DROP TABLE IF EXISTS status_items;
+
+DROP TABLE IF EXISTS highlight_items;
+
+DROP TABLE IF EXISTS highlights;
+
+DROP TABLE IF EXISTS status_views;
+
+DROP TABLE IF EXISTS status_hidden_from;
+
+DROP TABLE IF EXISTS friend_list_members;
+
+DROP TABLE IF EXISTS friend_lists;
+
+DROP TABLE IF EXISTS privacy_settings;
+
+DROP TABLE IF EXISTS friend_suggestions;
+
+DROP TABLE IF EXISTS friend_request_declines;
+
+DROP TABLE IF EXISTS blocks;
+
+DROP TABLE IF EXISTS audit_events;
+
+DROP TABLE IF EXISTS api_tokens;
+
+DROP TABLE IF EXISTS status;
+
+DROP TABLE IF EXISTS friendships;
+
+DROP TABLE IF EXISTS profiles;
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,260 @@
+CREATE TABLE
+    users (
+        id INT AUTO_INCREMENT PRIMARY KEY,
+        uid VARCHAR(36) NOT NULL UNIQUE,
+        username VARCHAR(255) NOT NULL UNIQUE,
+        email VARCHAR(255) NOT NULL UNIQUE,
+        password VARCHAR(255) NOT NULL,
+        account_type VARCHAR(16) DEFAULT 'user' NOT NULL,
+        owner_uid VARCHAR(36) NULL,
+        role VARCHAR(16) DEFAULT 'user' NOT NULL,
+        last_seen_at TIMESTAMP NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        deleted_at TIMESTAMP NULL
+    );
+
+CREATE TABLE
+    profiles (
+        id INT AUTO_INCREMENT PRIMARY KEY,
+        uid VARCHAR(36) NOT NULL UNIQUE,
+        user_id INT NOT NULL UNIQUE,
+        first_name VARCHAR(255) NOT NULL,
+        last_name VARCHAR(255) NOT NULL,
+        bio TEXT NOT NULL,
+        avatar VARCHAR(255) NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        deleted_at TIMESTAMP NULL,
+        FOREIGN KEY (user_id) REFERENCES users (id),
+        FOREIGN KEY (uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    friendships (
+        id INT AUTO_INCREMENT PRIMARY KEY,
+        uid VARCHAR(36) NOT NULL UNIQUE,
+        user1 VARCHAR(36) NOT NULL,
+        user2 VARCHAR(36) NOT NULL,
+        accepted BOOLEAN DEFAULT false NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        accepted_at TIMESTAMP NULL,
+        FOREIGN KEY (user1) REFERENCES users (uid),
+        FOREIGN KEY (user2) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    status (
+        id INT AUTO_INCREMENT PRIMARY KEY,
+        uid VARCHAR(36) NOT NULL UNIQUE,
+        user_uid VARCHAR(36) NOT NULL,
+        resource_uri VARCHAR(255) DEFAULT '' NOT NULL,
+        resource_thumbnail VARCHAR(255) DEFAULT '' NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        title VARCHAR(255) DEFAULT '' NOT NULL,
+        audience VARCHAR(16) DEFAULT 'everyone' NOT NULL,
+        list_uid VARCHAR(36) NULL,
+        deleted_at TIMESTAMP NULL,
+        FOREIGN KEY (user_uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    api_tokens (
+        id INT AUTO_INCREMENT PRIMARY KEY,
+        uid VARCHAR(36) NOT NULL UNIQUE,
+        user_uid VARCHAR(36) NOT NULL,
+        name VARCHAR(64) NOT NULL,
+        prefix VARCHAR(16) NOT NULL,
+        token_hash CHAR(64) NOT NULL UNIQUE,
+        scopes VARCHAR(255) NOT NULL,
+        last_used_at TIMESTAMP NULL,
+        expires_at TIMESTAMP NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        revoked_at TIMESTAMP NULL,
+        INDEX (user_uid),
+        FOREIGN KEY (user_uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    audit_events (
+        id BIGINT AUTO_INCREMENT PRIMARY KEY,
+        uid VARCHAR(36) NOT NULL UNIQUE,
+        user_uid VARCHAR(36) NULL,
+        actor_uid VARCHAR(36) NULL,
+        event_type VARCHAR(64) NOT NULL,
+        ip VARCHAR(45) NOT NULL,
+        user_agent VARCHAR(512) NOT NULL,
+        request_id VARCHAR(64) NOT NULL,
+        metadata JSON NULL,
+        created_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6) NOT NULL,
+        INDEX (user_uid, created_at),
+        INDEX (event_type, created_at),
+        INDEX (created_at)
+    );
+
+CREATE TABLE
+    blocks (
+        id INT AUTO_INCREMENT PRIMARY KEY,
+        blocker_uid VARCHAR(36) NOT NULL,
+        blocked_uid VARCHAR(36) NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        UNIQUE (blocker_uid, blocked_uid),
+        INDEX (blocked_uid),
+        FOREIGN KEY (blocker_uid) REFERENCES users (uid),
+        FOREIGN KEY (blocked_uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    friend_request_declines (
+        sender_uid VARCHAR(36) NOT NULL,
+        recipient_uid VARCHAR(36) NOT NULL,
+        expires_at TIMESTAMP NOT NULL,
+        PRIMARY KEY (sender_uid, recipient_uid),
+        FOREIGN KEY (sender_uid) REFERENCES users (uid),
+        FOREIGN KEY (recipient_uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    friend_suggestions (
+        user_uid VARCHAR(36) NOT NULL,
+        suggested_uid VARCHAR(36) NOT NULL,
+        mutual_count INT NOT NULL,
+        computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        PRIMARY KEY (user_uid, suggested_uid),
+        INDEX (user_uid, mutual_count),
+        FOREIGN KEY (user_uid) REFERENCES users (uid),
+        FOREIGN KEY (suggested_uid) REFERENCES users (uid)
+    );
+
+ALTER TABLE users ADD FULLTEXT INDEX ft_users_username (username);
+
+ALTER TABLE profiles ADD FULLTEXT INDEX ft_profiles_name (first_name, last_name);
+
+CREATE TABLE
+    privacy_settings (
+        user_uid VARCHAR(36) PRIMARY KEY,
+        email_visibility VARCHAR(16) DEFAULT 'friends' NOT NULL,
+        bio_visibility VARCHAR(16) DEFAULT 'everyone' NOT NULL,
+        last_seen_visibility VARCHAR(16) DEFAULT 'friends' NOT NULL,
+        status_visibility VARCHAR(16) DEFAULT 'friends' NOT NULL,
+        friend_requests VARCHAR(24) DEFAULT 'everyone' NOT NULL,
+        searchable BOOLEAN DEFAULT TRUE NOT NULL,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        FOREIGN KEY (user_uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    friend_lists (
+        id INT AUTO_INCREMENT PRIMARY KEY,
+        uid VARCHAR(36) NOT NULL UNIQUE,
+        owner_uid VARCHAR(36) NOT NULL,
+        name VARCHAR(64) NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        UNIQUE (owner_uid, name),
+        FOREIGN KEY (owner_uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    friend_list_members (
+        list_uid VARCHAR(36) NOT NULL,
+        member_uid VARCHAR(36) NOT NULL,
+        added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        PRIMARY KEY (list_uid, member_uid),
+        INDEX (member_uid),
+        FOREIGN KEY (list_uid) REFERENCES friend_lists (uid),
+        FOREIGN KEY (member_uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    status_hidden_from (
+        owner_uid VARCHAR(36) NOT NULL,
+        hidden_uid VARCHAR(36) NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        PRIMARY KEY (owner_uid, hidden_uid),
+        FOREIGN KEY (owner_uid) REFERENCES users (uid),
+        FOREIGN KEY (hidden_uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    status_views (
+        status_uid VARCHAR(36) NOT NULL,
+        viewer_uid VARCHAR(36) NOT NULL,
+        viewed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        PRIMARY KEY (status_uid, viewer_uid),
+        INDEX (viewer_uid),
+        FOREIGN KEY (status_uid) REFERENCES status (uid),
+        FOREIGN KEY (viewer_uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    highlights (
+        id INT AUTO_INCREMENT PRIMARY KEY,
+        uid VARCHAR(36) NOT NULL UNIQUE,
+        owner_uid VARCHAR(36) NOT NULL,
+        name VARCHAR(64) NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        UNIQUE (owner_uid, name),
+        FOREIGN KEY (owner_uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    highlight_items (
+        highlight_uid VARCHAR(36) NOT NULL,
+        status_uid VARCHAR(36) NOT NULL,
+        added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        PRIMARY KEY (highlight_uid, status_uid),
+        INDEX (status_uid),
+        FOREIGN KEY (highlight_uid) REFERENCES highlights (uid),
+        FOREIGN KEY (status_uid) REFERENCES status (uid)
+    );
+
+CREATE TABLE
+    status_items (
+        status_uid VARCHAR(36) NOT NULL,
+        position TINYINT UNSIGNED NOT NULL,
+        kind VARCHAR(8) NOT NULL,
+        text VARCHAR(700) DEFAULT '' NOT NULL,
+        background_color VARCHAR(7) DEFAULT '' NOT NULL,
+        font VARCHAR(16) DEFAULT '' NOT NULL,
+        resource_uri VARCHAR(255) DEFAULT '' NOT NULL,
+        resource_thumbnail VARCHAR(255) DEFAULT '' NOT NULL,
+        duration_ms INT UNSIGNED DEFAULT 0 NOT NULL,
+        caption VARCHAR(500) DEFAULT '' NOT NULL,
+        link_url VARCHAR(2048) DEFAULT '' NOT NULL,
+        link_title VARCHAR(255) DEFAULT '' NOT NULL,
+        link_description VARCHAR(500) DEFAULT '' NOT NULL,
+        link_image VARCHAR(2048) DEFAULT '' NOT NULL,
+        link_site_name VARCHAR(100) DEFAULT '' NOT NULL,
+        PRIMARY KEY (status_uid, position),
+        FOREIGN KEY (status_uid) REFERENCES status (uid)
+    );
+
+CREATE TABLE
+    status_item_mentions (
+        status_uid VARCHAR(36) NOT NULL,
+        position TINYINT UNSIGNED NOT NULL,
+        user_uid VARCHAR(36) NOT NULL,
+        PRIMARY KEY (status_uid, position, user_uid),
+        INDEX (user_uid),
+        FOREIGN KEY (status_uid, position) REFERENCES status_items (status_uid, position),
+        FOREIGN KEY (user_uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    status_mutes (
+        owner_uid VARCHAR(36) NOT NULL,
+        muted_uid VARCHAR(36) NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        PRIMARY KEY (owner_uid, muted_uid),
+        FOREIGN KEY (owner_uid) REFERENCES users (uid),
+        FOREIGN KEY (muted_uid) REFERENCES users (uid)
+    );
+
+CREATE TABLE
+    blobs (
+        uri VARCHAR(255) PRIMARY KEY,
+        owner_uid VARCHAR(36) NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+        INDEX (owner_uid),
+        FOREIGN KEY (owner_uid) REFERENCES users (uid)
+    );
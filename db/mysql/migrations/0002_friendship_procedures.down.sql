@@ -0,0 +1,13 @@
+DROP PROCEDURE IF EXISTS get_friend;
+
+DROP PROCEDURE IF EXISTS get_friends_or_requests;
+
+DROP PROCEDURE IF EXISTS get_friend_request;
+
+DROP PROCEDURE IF EXISTS get_friend_requests_by_direction;
+
+DROP PROCEDURE IF EXISTS get_friends_status;
+
+DROP PROCEDURE IF EXISTS get_friend_status;
+
+DROP PROCEDURE IF EXISTS refresh_friend_suggestions;
@@ -0,0 +1,551 @@
+-- +migrate StatementBegin
+CREATE PROCEDURE get_friend (
+    IN user_uid VARCHAR(36),
+    IN friend_uid VARCHAR(36)
+) BEGIN
+SELECT
+    f.uid AS rid,
+    IF (u1.uid = user_uid, u2.uid, u1.uid) AS uid,
+    IF (u1.uid = user_uid, u2.username, u1.username) AS username,
+    IF (u1.uid = user_uid, p2.first_name, p1.first_name) AS first_name,
+    IF (u1.uid = user_uid, p2.last_name, p1.last_name) AS last_name,
+    IF (u1.uid = user_uid, p2.bio, p1.bio) AS bio,
+    IF (u1.uid = user_uid, p2.avatar, p1.avatar) AS avatar,
+    f.accepted_at,
+    IF (
+        f.accepted,
+        NULL,
+        IF (f.user1 = user_uid, 'outgoing', 'incoming')
+    ) AS direction
+FROM
+    friendships f
+    JOIN users u1 ON u1.uid = f.user1
+    JOIN users u2 ON u2.uid = f.user2
+    LEFT JOIN profiles p1 ON p1.uid = f.user1
+    LEFT JOIN profiles p2 ON p2.uid = f.user2
+WHERE
+    f.accepted = TRUE
+    AND (
+        u1.deleted_at IS NULL
+        OR u2.deleted_at IS NULL
+    )
+    AND (
+        user1 = user_uid
+        OR user2 = user_uid
+    )
+    AND (
+        user1 = friend_uid
+        OR user2 = friend_uid
+    );
+
+END;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE PROCEDURE get_friends_or_requests (IN user_uid VARCHAR(36), IN request BOOLEAN) BEGIN
+SELECT
+    f.uid AS rid,
+    IF (u1.uid = user_uid, u2.uid, u1.uid) AS uid,
+    IF (u1.uid = user_uid, u2.username, u1.username) AS username,
+    IF (u1.uid = user_uid, p2.first_name, p1.first_name) AS first_name,
+    IF (u1.uid = user_uid, p2.last_name, p1.last_name) AS last_name,
+    IF (u1.uid = user_uid, p2.bio, p1.bio) AS bio,
+    IF (u1.uid = user_uid, p2.avatar, p1.avatar) AS avatar,
+    f.accepted_at,
+    IF (
+        f.accepted,
+        NULL,
+        IF (f.user1 = user_uid, 'outgoing', 'incoming')
+    ) AS direction
+FROM
+    friendships f
+    JOIN users u1 ON u1.uid = f.user1
+    JOIN users u2 ON u2.uid = f.user2
+    LEFT JOIN profiles p1 ON p1.uid = f.user1
+    LEFT JOIN profiles p2 ON p2.uid = f.user2
+WHERE
+    f.accepted = (NOT request)
+    AND (
+        u1.deleted_at IS NULL
+        OR u2.deleted_at IS NULL
+    )
+    AND (
+        user1 = user_uid
+        OR user2 = user_uid
+    );
+
+END;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE PROCEDURE get_friend_request (
+    IN user_uid VARCHAR(36),
+    IN request_id VARCHAR(36)
+) BEGIN
+SELECT
+    f.uid AS rid,
+    IF (u1.uid = user_uid, u2.uid, u1.uid) AS uid,
+    IF (u1.uid = user_uid, u2.username, u1.username) AS username,
+    IF (u1.uid = user_uid, p2.first_name, p1.first_name) AS first_name,
+    IF (u1.uid = user_uid, p2.last_name, p1.last_name) AS last_name,
+    IF (u1.uid = user_uid, p2.bio, p1.bio) AS bio,
+    IF (u1.uid = user_uid, p2.avatar, p1.avatar) AS avatar,
+    f.accepted_at,
+    IF (
+        f.accepted,
+        NULL,
+        IF (f.user1 = user_uid, 'outgoing', 'incoming')
+    ) AS direction
+FROM
+    friendships f
+    JOIN users u1 ON u1.uid = f.user1
+    JOIN users u2 ON u2.uid = f.user2
+    LEFT JOIN profiles p1 ON p1.uid = f.user1
+    LEFT JOIN profiles p2 ON p2.uid = f.user2
+WHERE
+    f.uid = request_id
+    AND (
+        user1 = user_uid
+        OR user2 = user_uid
+    )
+    AND (
+        u1.deleted_at IS NULL
+        OR u2.deleted_at IS NULL
+    )
+    AND f.accepted_at IS NULL;
+
+END;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE PROCEDURE get_friend_requests_by_direction (IN user_uid VARCHAR(36), IN outgoing BOOLEAN) BEGIN
+SELECT
+    f.uid AS rid,
+    IF (u1.uid = user_uid, u2.uid, u1.uid) AS uid,
+    IF (u1.uid = user_uid, u2.username, u1.username) AS username,
+    IF (u1.uid = user_uid, p2.first_name, p1.first_name) AS first_name,
+    IF (u1.uid = user_uid, p2.last_name, p1.last_name) AS last_name,
+    IF (u1.uid = user_uid, p2.bio, p1.bio) AS bio,
+    IF (u1.uid = user_uid, p2.avatar, p1.avatar) AS avatar,
+    f.accepted_at,
+    IF (outgoing, 'outgoing', 'incoming') AS direction
+FROM
+    friendships f
+    JOIN users u1 ON u1.uid = f.user1
+    JOIN users u2 ON u2.uid = f.user2
+    LEFT JOIN profiles p1 ON p1.uid = f.user1
+    LEFT JOIN profiles p2 ON p2.uid = f.user2
+WHERE
+    f.accepted = FALSE
+    AND (
+        u1.deleted_at IS NULL
+        OR u2.deleted_at IS NULL
+    )
+    AND (
+        (
+            outgoing
+            AND user1 = user_uid
+        )
+        OR (
+            NOT outgoing
+            AND user2 = user_uid
+        )
+    )
+ORDER BY
+    f.created_at DESC;
+
+END;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE PROCEDURE get_friends_status (IN user_uid VARCHAR(36), IN ttl_seconds INT) BEGIN
+SELECT
+    f.uid AS rid,
+    IF (f.user1 = user_uid, u2.uid, u1.uid) AS uid,
+    s.uid AS status_id,
+    IF (f.user1 = user_uid, u2.username, u1.username) AS username,
+    IF (f.user1 = user_uid, p2.first_name, p1.first_name) AS first_name,
+    IF (f.user1 = user_uid, p2.last_name, p1.last_name) AS last_name,
+    IF (f.user1 = user_uid, p2.avatar, p1.avatar) AS avatar,
+    s.title,
+    s.resource_uri,
+    s.resource_thumbnail,
+    EXISTS (
+        SELECT
+            1
+        FROM
+            status_views v
+        WHERE
+            v.status_uid = s.uid
+            AND v.viewer_uid = user_uid
+    ) AS seen
+FROM
+    friendships f
+    JOIN users u1 ON f.user1 = u1.uid
+    JOIN users u2 ON f.user2 = u2.uid
+    JOIN profiles p1 ON u1.id = p1.user_id
+    JOIN profiles p2 ON u2.id = p2.user_id
+    LEFT JOIN status s ON (
+        (
+            s.user_uid = u1.uid
+            AND f.user1 <> user_uid
+        )
+        OR (
+            s.user_uid = u2.uid
+            AND f.user2 <> user_uid
+        )
+    )
+WHERE
+    f.accepted = TRUE
+    AND (
+        user_uid = f.user1
+        OR user_uid = f.user2
+    )
+    AND (
+        u1.deleted_at IS NULL
+        AND u2.deleted_at IS NULL
+    )
+    AND (
+        s.created_at > DATE_SUB(NOW(), INTERVAL ttl_seconds SECOND)
+        AND s.deleted_at IS NULL
+    )
+    AND (s.uid IS NOT NULL)
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            blocks b
+        WHERE
+            (
+                b.blocker_uid = f.user1
+                AND b.blocked_uid = f.user2
+            )
+            OR (
+                b.blocker_uid = f.user2
+                AND b.blocked_uid = f.user1
+            )
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            privacy_settings ps
+        WHERE
+            ps.user_uid = s.user_uid
+            AND ps.status_visibility = 'nobody'
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            status_hidden_from h
+        WHERE
+            h.owner_uid = s.user_uid
+            AND h.hidden_uid = user_uid
+    )
+    -- A status posted to a list that was deleted since is shown to nobody,
+    -- one posted to everyone except a deleted list is shown to every friend.
+    AND (
+        s.audience = 'everyone'
+        OR (
+            s.audience = 'list'
+            AND EXISTS (
+                SELECT
+                    1
+                FROM
+                    friend_list_members m
+                WHERE
+                    m.list_uid = s.list_uid
+                    AND m.member_uid = user_uid
+            )
+        )
+        OR (
+            s.audience = 'except_list'
+            AND NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    friend_list_members m
+                WHERE
+                    m.list_uid = s.list_uid
+                    AND m.member_uid = user_uid
+            )
+        )
+    )
+-- Unseen statuses first, newest first.
+ORDER BY
+    seen,
+    s.created_at DESC;
+
+END;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE PROCEDURE get_friend_status (
+    IN user_uid VARCHAR(36),
+    IN status_id VARCHAR(36),
+    IN ttl_seconds INT
+) BEGIN
+SELECT
+    f.uid AS rid,
+    IF (f.user1 = user_uid, u2.uid, u1.uid) AS uid,
+    s.uid AS status_id,
+    IF (f.user1 = user_uid, u2.username, u1.username) AS username,
+    IF (f.user1 = user_uid, p2.first_name, p1.first_name) AS first_name,
+    IF (f.user1 = user_uid, p2.last_name, p1.last_name) AS last_name,
+    IF (f.user1 = user_uid, p2.avatar, p1.avatar) AS avatar,
+    s.title,
+    s.resource_uri,
+    s.resource_thumbnail,
+    EXISTS (
+        SELECT
+            1
+        FROM
+            status_views v
+        WHERE
+            v.status_uid = s.uid
+            AND v.viewer_uid = user_uid
+    ) AS seen
+FROM
+    friendships f
+    JOIN users u1 ON f.user1 = u1.uid
+    JOIN users u2 ON f.user2 = u2.uid
+    JOIN profiles p1 ON u1.id = p1.user_id
+    JOIN profiles p2 ON u2.id = p2.user_id
+    LEFT JOIN status s ON (
+        (
+            s.user_uid = u1.uid
+            AND f.user1 <> user_uid
+        )
+        OR (
+            s.user_uid = u2.uid
+            AND f.user2 <> user_uid
+        )
+    )
+WHERE
+    f.accepted = TRUE
+    AND (
+        user_uid = f.user1
+        OR user_uid = f.user2
+    )
+    AND (
+        u1.deleted_at IS NULL
+        AND u2.deleted_at IS NULL
+    )
+    AND (
+        s.created_at > DATE_SUB(NOW(), INTERVAL ttl_seconds SECOND)
+        AND s.deleted_at IS NULL
+    )
+    AND (s.uid IS NOT NULL)
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            blocks b
+        WHERE
+            (
+                b.blocker_uid = f.user1
+                AND b.blocked_uid = f.user2
+            )
+            OR (
+                b.blocker_uid = f.user2
+                AND b.blocked_uid = f.user1
+            )
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            privacy_settings ps
+        WHERE
+            ps.user_uid = s.user_uid
+            AND ps.status_visibility = 'nobody'
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            status_hidden_from h
+        WHERE
+            h.owner_uid = s.user_uid
+            AND h.hidden_uid = user_uid
+    )
+    -- A status posted to a list that was deleted since is shown to nobody,
+    -- one posted to everyone except a deleted list is shown to every friend.
+    AND (
+        s.audience = 'everyone'
+        OR (
+            s.audience = 'list'
+            AND EXISTS (
+                SELECT
+                    1
+                FROM
+                    friend_list_members m
+                WHERE
+                    m.list_uid = s.list_uid
+                    AND m.member_uid = user_uid
+            )
+        )
+        OR (
+            s.audience = 'except_list'
+            AND NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    friend_list_members m
+                WHERE
+                    m.list_uid = s.list_uid
+                    AND m.member_uid = user_uid
+            )
+        )
+    )
+    AND (s.uid = status_id);
+
+END;
+-- +migrate StatementEnd
+
+-- The suggestions are recomputed by batches of users, each in its own
+-- transaction, so a large friend graph doesn't hold one long transaction.
+-- +migrate StatementBegin
+CREATE PROCEDURE refresh_friend_suggestions (IN per_user INT, IN batch_size INT) BEGIN
+DECLARE last_id INT DEFAULT 0;
+
+DECLARE max_id INT;
+
+-- An error must not leave the transaction open on the pooled connection.
+DECLARE EXIT HANDLER FOR SQLEXCEPTION BEGIN
+ROLLBACK;
+
+RESIGNAL;
+
+END;
+
+SELECT
+    COALESCE(MAX(id), 0) INTO max_id
+FROM
+    users;
+
+WHILE last_id < max_id DO
+START TRANSACTION;
+
+DELETE s
+FROM
+    friend_suggestions s
+    JOIN users u ON u.uid = s.user_uid
+WHERE
+    u.id > last_id
+    AND u.id <= last_id + batch_size;
+
+INSERT INTO
+    friend_suggestions (user_uid, suggested_uid, mutual_count)
+WITH
+    batch AS (
+        SELECT
+            u.uid
+        FROM
+            users u
+        WHERE
+            u.id > last_id
+            AND u.id <= last_id + batch_size
+            AND u.deleted_at IS NULL
+    ),
+    edges AS (
+        SELECT
+            f.user1 AS a,
+            f.user2 AS b
+        FROM
+            friendships f
+        WHERE
+            f.accepted = TRUE
+        UNION ALL
+        SELECT
+            f.user2 AS a,
+            f.user1 AS b
+        FROM
+            friendships f
+        WHERE
+            f.accepted = TRUE
+    ),
+    candidates AS (
+        SELECT
+            e1.a AS user_uid,
+            e2.b AS suggested_uid,
+            COUNT(*) AS mutual_count
+        FROM
+            batch
+            JOIN edges e1 ON e1.a = batch.uid
+            JOIN edges e2 ON e2.a = e1.b
+        WHERE
+            e2.b <> e1.a
+        GROUP BY
+            e1.a,
+            e2.b
+    ),
+    ranked AS (
+        SELECT
+            c.user_uid,
+            c.suggested_uid,
+            c.mutual_count,
+            ROW_NUMBER() OVER (
+                PARTITION BY
+                    c.user_uid
+                ORDER BY
+                    c.mutual_count DESC,
+                    c.suggested_uid
+            ) AS position
+        FROM
+            candidates c
+            JOIN users u2 ON u2.uid = c.suggested_uid
+        WHERE
+            u2.deleted_at IS NULL
+            AND u2.account_type = 'user'
+            -- Existing friends and pending requests in either direction.
+            AND NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    friendships f
+                WHERE
+                    (
+                        f.user1 = c.user_uid
+                        AND f.user2 = c.suggested_uid
+                    )
+                    OR (
+                        f.user1 = c.suggested_uid
+                        AND f.user2 = c.user_uid
+                    )
+            )
+            AND NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    blocks b
+                WHERE
+                    (
+                        b.blocker_uid = c.user_uid
+                        AND b.blocked_uid = c.suggested_uid
+                    )
+                    OR (
+                        b.blocker_uid = c.suggested_uid
+                        AND b.blocked_uid = c.user_uid
+                    )
+            )
+    )
+SELECT
+    user_uid,
+    suggested_uid,
+    mutual_count
+FROM
+    ranked
+WHERE
+    position <= per_user;
+
+COMMIT;
+
+SET
+    last_id = last_id + batch_size;
+
+END WHILE;
+
+END;
+-- +migrate StatementEnd
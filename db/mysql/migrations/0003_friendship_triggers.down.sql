@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS check_self_request;
+
+DROP TRIGGER IF EXISTS check_request_duplication;
+
+DROP TRIGGER IF EXISTS check_request_blocked;
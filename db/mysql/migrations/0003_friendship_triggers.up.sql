@@ -0,0 +1,59 @@
+-- +migrate StatementBegin
+CREATE TRIGGER check_self_request BEFORE INSERT ON friendships FOR EACH ROW BEGIN IF NEW.user1 = NEW.user2 THEN SIGNAL SQLSTATE '45000'
+SET
+    MESSAGE_TEXT = 'exception: self request';
+
+END IF;
+
+END;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE TRIGGER check_request_duplication BEFORE INSERT ON friendships FOR EACH ROW BEGIN DECLARE f_count INT;
+
+SELECT
+    COUNT(*) INTO f_count
+FROM
+    friendships
+WHERE
+    (
+        user1 = NEW.user1
+        and user2 = NEW.user2
+    )
+    OR (
+        user1 = NEW.user2
+        and user2 = NEW.user1
+    );
+
+IF f_count > 0 THEN SIGNAL SQLSTATE '45000'
+SET
+    MESSAGE_TEXT = 'exception: duplicate request';
+
+END IF;
+
+END;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE TRIGGER check_request_blocked BEFORE INSERT ON friendships FOR EACH ROW BEGIN IF EXISTS (
+    SELECT
+        1
+    FROM
+        blocks
+    WHERE
+        (
+            blocker_uid = NEW.user1
+            AND blocked_uid = NEW.user2
+        )
+        OR (
+            blocker_uid = NEW.user2
+            AND blocked_uid = NEW.user1
+        )
+) THEN SIGNAL SQLSTATE '45000'
+SET
+    MESSAGE_TEXT = 'exception: blocked request';
+
+END IF;
+
+END;
+-- +migrate StatementEnd
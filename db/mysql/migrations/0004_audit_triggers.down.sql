@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS audit_events_no_update;
+
+DROP TRIGGER IF EXISTS audit_events_no_delete;
@@ -0,0 +1,15 @@
+-- +migrate StatementBegin
+CREATE TRIGGER audit_events_no_update BEFORE UPDATE ON audit_events FOR EACH ROW BEGIN SIGNAL SQLSTATE '45000'
+SET
+    MESSAGE_TEXT = 'exception: audit log is append-only';
+
+END;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE TRIGGER audit_events_no_delete BEFORE DELETE ON audit_events FOR EACH ROW BEGIN SIGNAL SQLSTATE '45000'
+SET
+    MESSAGE_TEXT = 'exception: audit log is append-only';
+
+END;
+-- +migrate StatementEnd
@@ -0,0 +1,14 @@
+// Package migrations contains the versioned MySQL schema migrations.
+//
+// Each version has an up and a down file, named <version>_<name>.up.sql and
+// <version>_<name>.down.sql. Statements end with a semicolon at the end of a
+// line, stored programs are wrapped in StatementBegin and StatementEnd
+// comments since their bodies contain semicolons.
+package migrations
+
+import "embed"
+
+// FS holds the migration files.
+//
+//go:embed *.sql
+var FS embed.FS
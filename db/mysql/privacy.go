@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/coderero/erochat-server/db/mysql/queries"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+// PrivacyStore is a MySQL data store for privacy settings.
+type PrivacyStore struct {
+	// db is the database of the store.
+	db *DB
+}
+
+// NewPrivacyStore creates a new PrivacyStore.
+func NewPrivacyStore(db *DB) *PrivacyStore {
+	return &PrivacyStore{
+		db: db,
+	}
+}
+
+// Get returns the privacy settings of a user, or the defaults if they were
+// never changed. It reads the primary, the settings gate who can reach the
+// user and a change must apply at once.
+func (s *PrivacyStore) Get(ctx context.Context, userID uuid.UUID) (*types.PrivacySettings, error) {
+	db := s.db.Primary()
+
+	settings := &types.PrivacySettings{}
+	err := scanPrivacySettings(db.QueryRowContext(ctx, queries.GetPrivacySettings, userID), settings)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.DefaultPrivacySettings(userID), nil
+		}
+		return nil, err
+	}
+	return settings, nil
+}
+
+// GetMany returns the privacy settings of several users, by uid. Users who
+// never changed them get the defaults. It reads the primary like Get.
+func (s *PrivacyStore) GetMany(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]*types.PrivacySettings, error) {
+	settings := make(map[uuid.UUID]*types.PrivacySettings, len(userIDs))
+	if len(userIDs) == 0 {
+		return settings, nil
+	}
+
+	db := s.db.Primary()
+
+	args := make([]any, 0, len(userIDs))
+	for _, id := range userIDs {
+		args = append(args, id)
+		settings[id] = types.DefaultPrivacySettings(id)
+	}
+	query := fmt.Sprintf(queries.GetManyPrivacySettings, "?"+strings.Repeat(", ?", len(userIDs)-1))
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		userSettings := &types.PrivacySettings{}
+		if err = scanPrivacySettings(rows, userSettings); err != nil {
+			return nil, err
+		}
+		settings[userSettings.UserUID] = userSettings
+	}
+	return settings, rows.Err()
+}
+
+// Update saves the privacy settings of a user.
+func (s *PrivacyStore) Update(ctx context.Context, settings *types.PrivacySettings) (*types.PrivacySettings, error) {
+	db := s.db.Primary()
+
+	_, err := db.ExecContext(ctx, queries.UpsertPrivacySettings, settings.UserUID, settings.EmailVisibility, settings.BioVisibility, settings.LastSeenVisibility, settings.StatusVisibility, settings.FriendRequests, settings.Searchable)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := &types.PrivacySettings{}
+	if err := scanPrivacySettings(db.QueryRowContext(ctx, queries.GetPrivacySettings, settings.UserUID), updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// scanPrivacySettings scans a row selected with the privacy columns into settings.
+func scanPrivacySettings(row scanner, settings *types.PrivacySettings) error {
+	return row.Scan(&settings.UserUID, &settings.EmailVisibility, &settings.BioVisibility, &settings.LastSeenVisibility, &settings.StatusVisibility, &settings.FriendRequests, &settings.Searchable, &settings.UpdatedAt)
+}
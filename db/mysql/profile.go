@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -15,27 +16,23 @@ import (
 
 // ProfileStore is a MySQL data store for profile.
 type ProfileStore struct {
-	// ConnectionPool is a pool of connections to the database.
-	pool *ConnectionPool
+	// db is the database of the store.
+	db *DB
 }
 
 // NewProfileStore creates a new ProfileStore.
-func NewProfileStore(pool *ConnectionPool) *ProfileStore {
+func NewProfileStore(db *DB) *ProfileStore {
 	return &ProfileStore{
-		pool: pool,
+		db: db,
 	}
 }
 
 // GetByID returns a profile by its uuid.
-func (s *ProfileStore) GetByUID(id uuid.UUID) (*types.Profile, error) {
-	db, err := s.pool.Get()
-	if err != nil {
-		return nil, err
-	}
-	defer s.pool.Release()
+func (s *ProfileStore) GetByUID(ctx context.Context, id uuid.UUID) (*types.Profile, error) {
+	db := s.db.Reader()
 
 	profile := &types.Profile{}
-	err = db.QueryRow(queries.GetUserProfileByUID, id).Scan(&profile.ID, &profile.UID, &profile.UserID, &profile.FirstName, &profile.LastName, &profile.Bio, &profile.Avatar, &profile.Username, &profile.Email, &profile.CreatedAt, &profile.UpdatedAt, &profile.DeletedAt)
+	err := scanProfile(db.QueryRowContext(ctx, queries.GetUserProfileByUID, id), profile)
 	if err != nil {
 		// If the profile is not found, return an error.
 		if errors.Is(err, sql.ErrNoRows) {
@@ -47,15 +44,11 @@ func (s *ProfileStore) GetByUID(id uuid.UUID) (*types.Profile, error) {
 }
 
 // GetByUserID returns a profile by its user id.
-func (s *ProfileStore) GetByUserID(id int) (*types.Profile, error) {
-	db, err := s.pool.Get()
-	if err != nil {
-		return nil, err
-	}
-	defer s.pool.Release()
+func (s *ProfileStore) GetByUserID(ctx context.Context, id int) (*types.Profile, error) {
+	db := s.db.Reader()
 
 	profile := &types.Profile{}
-	err = db.QueryRow(queries.GetUserProfileByUserID, id).Scan(&profile.ID, &profile.UID, &profile.UserID, &profile.FirstName, &profile.LastName, &profile.Bio, &profile.Avatar, &profile.Username, &profile.Email, &profile.CreatedAt, &profile.UpdatedAt, &profile.DeletedAt)
+	err := scanProfile(db.QueryRowContext(ctx, queries.GetUserProfileByUserID, id), profile)
 	if err != nil {
 		// If the profile is not found, return an error.
 		if errors.Is(err, sql.ErrNoRows) {
@@ -67,15 +60,11 @@ func (s *ProfileStore) GetByUserID(id int) (*types.Profile, error) {
 }
 
 // GetByEmail returns a profile by its email.
-func (s *ProfileStore) GetByEmail(email string) (*types.Profile, error) {
-	db, err := s.pool.Get()
-	if err != nil {
-		return nil, err
-	}
-	defer s.pool.Release()
+func (s *ProfileStore) GetByEmail(ctx context.Context, email string) (*types.Profile, error) {
+	db := s.db.Reader()
 
 	profile := &types.Profile{}
-	err = db.QueryRow(queries.GetUserProfileByEmail, email).Scan(&profile.ID, &profile.UID, &profile.UserID, &profile.FirstName, &profile.LastName, &profile.Bio, &profile.Avatar, &profile.Username, &profile.Email, &profile.CreatedAt, &profile.UpdatedAt, &profile.DeletedAt)
+	err := scanProfile(db.QueryRowContext(ctx, queries.GetUserProfileByEmail, email), profile)
 	if err != nil {
 		// If the profile is not found, return an error.
 		if errors.Is(err, sql.ErrNoRows) {
@@ -87,14 +76,10 @@ func (s *ProfileStore) GetByEmail(email string) (*types.Profile, error) {
 }
 
 // Create creates a new profile.
-func (s *ProfileStore) Create(profile *types.Profile) (*types.Profile, error) {
-	db, err := s.pool.Get()
-	if err != nil {
-		return nil, err
-	}
-	defer s.pool.Release()
+func (s *ProfileStore) Create(ctx context.Context, profile *types.Profile) (*types.Profile, error) {
+	db := s.db.Primary()
 
-	result, err := db.Exec(queries.CreateUserProfile, profile.UID, profile.UserID, profile.FirstName, profile.LastName, profile.Bio, profile.Avatar)
+	result, err := db.ExecContext(ctx, queries.CreateUserProfile, profile.UID, profile.UserID, profile.FirstName, profile.LastName, profile.Bio, profile.Avatar)
 	if err != nil {
 		// Check if the profile already exists.
 		if strings.Contains(err.Error(), "Duplicate entry") {
@@ -108,31 +93,23 @@ func (s *ProfileStore) Create(profile *types.Profile) (*types.Profile, error) {
 		return nil, interfaces.ErrFailedToCreateProfile
 	}
 
-	var uid string
-	row := db.QueryRow(queries.GetUserProfileByID, id)
-	err = row.Scan(&profile.ID, &uid, &profile.UserID, &profile.FirstName, &profile.LastName, &profile.Bio, &profile.Avatar, &profile.Username, &profile.Email, &profile.CreatedAt, &profile.UpdatedAt, &profile.DeletedAt)
+	err = scanProfile(db.QueryRowContext(ctx, queries.GetUserProfileByID, id), profile)
 	if err != nil {
 		return nil, interfaces.ErrFailedToCreateProfile
 	}
 
-	profile.UID, err = uuid.Parse(uid)
-	if err != nil {
-		return nil, err
-	}
-
 	return profile, nil
 }
 
-func (s *ProfileStore) CreateFriendship(userID, friendID string) error {
-	db, err := s.pool.Get()
-	if err != nil {
-		return err
-	}
-	defer s.pool.Release()
+func (s *ProfileStore) CreateFriendship(ctx context.Context, userID, friendID string) error {
+	db := s.db.Primary()
 
-	a, err := db.Exec(queries.CreateFriendship, userID, friendID)
+	a, err := db.ExecContext(ctx, queries.CreateFriendship, userID, friendID)
 	if err != nil {
-		sqlErr, _ := err.(*driver.MySQLError)
+		sqlErr, ok := err.(*driver.MySQLError)
+		if !ok {
+			return interfaces.ErrFailedToCreateFriendship
+		}
 		if string(sqlErr.SQLState[:]) == "45000" && strings.Contains(sqlErr.Message, "duplicate request") {
 
 			return interfaces.ErrDuplicateFriendship
@@ -140,6 +117,9 @@ func (s *ProfileStore) CreateFriendship(userID, friendID string) error {
 		if string(sqlErr.SQLState[:]) == "45000" && strings.Contains(sqlErr.Message, "self request") {
 			return interfaces.ErrSelfFriendship
 		}
+		if string(sqlErr.SQLState[:]) == "45000" && strings.Contains(sqlErr.Message, "blocked request") {
+			return interfaces.ErrBlockedFriendship
+		}
 		return interfaces.ErrFailedToCreateFriendship
 	}
 
@@ -151,40 +131,29 @@ func (s *ProfileStore) CreateFriendship(userID, friendID string) error {
 }
 
 // Update updates a profile.
-func (s *ProfileStore) Update(profile *types.Profile) (*types.Profile, error) {
+func (s *ProfileStore) Update(ctx context.Context, profile *types.Profile) (*types.Profile, error) {
 	var p types.Profile
-	db, err := s.pool.Get()
-	if err != nil {
-		return nil, err
-	}
-	defer s.pool.Release()
+	db := s.db.Primary()
 
-	_, err = db.Exec(queries.UpdateUserProfile, profile.FirstName, profile.LastName, profile.Bio, profile.Avatar, profile.UserID)
+	_, err := db.ExecContext(ctx, queries.UpdateUserProfile, profile.FirstName, profile.LastName, profile.Bio, profile.Avatar, profile.UserID)
 	// SetStatus sets the status of a user.
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-		return nil, interfaces.ErrFailedToUpdateProfile
+		return nil, fmt.Errorf("%w: %w", interfaces.ErrFailedToUpdateProfile, err)
 	}
 
-	row := db.QueryRow(queries.GetUserProfileByUserID, profile.UserID)
-	err = row.Scan(&p.ID, &p.UID, &p.UserID, &p.FirstName, &p.LastName, &p.Bio, &p.Avatar, &p.Username, &p.Email, &p.CreatedAt, &p.UpdatedAt, &p.DeletedAt)
+	err = scanProfile(db.QueryRowContext(ctx, queries.GetUserProfileByUserID, profile.UserID), &p)
 	if err != nil {
-		fmt.Printf("error3: %v\n", err)
-		return nil, interfaces.ErrFailedToUpdateProfile
+		return nil, fmt.Errorf("%w: %w", interfaces.ErrFailedToUpdateProfile, err)
 	}
 
 	return &p, nil
 }
 
 // Delete deletes a profile by its uuid.
-func (s *ProfileStore) Delete(id int) error {
-	db, err := s.pool.Get()
-	if err != nil {
-		return err
-	}
-	defer s.pool.Release()
+func (s *ProfileStore) Delete(ctx context.Context, id int) error {
+	db := s.db.Primary()
 
-	_, err = db.Exec(queries.DeleteUserProfile, id)
+	_, err := db.ExecContext(ctx, queries.DeleteUserProfile, id)
 	if err != nil {
 		return interfaces.ErrFailedToDeleteProfile
 	}
@@ -193,17 +162,18 @@ func (s *ProfileStore) Delete(id int) error {
 }
 
 // Reactivate reactivates a profile by its uuid.
-func (s *ProfileStore) Reactivate(id int) error {
-	db, err := s.pool.Get()
-	if err != nil {
-		return err
-	}
-	defer s.pool.Release()
+func (s *ProfileStore) Reactivate(ctx context.Context, id int) error {
+	db := s.db.Primary()
 
-	_, err = db.Exec(queries.ReactivateUserProfile, id)
+	_, err := db.ExecContext(ctx, queries.ReactivateUserProfile, id)
 	if err != nil {
 		return interfaces.ErrFailedToUpdateProfile
 	}
 
 	return nil
 }
+
+// scanProfile scans a row selected with the profile columns into a profile.
+func scanProfile(row scanner, profile *types.Profile) error {
+	return row.Scan(&profile.ID, &profile.UID, &profile.UserID, &profile.FirstName, &profile.LastName, &profile.Bio, &profile.Avatar, &profile.Username, &profile.Email, &profile.CreatedAt, &profile.UpdatedAt, &profile.DeletedAt, &profile.LastSeenAt)
+}
@@ -0,0 +1,30 @@
+package queries
+
+// SQL queries template constants for personal api tokens.
+
+// apiTokenColumns is the list of columns selected for an api token.
+const apiTokenColumns = `id, uid, user_uid, name, prefix, token_hash, scopes, last_used_at, expires_at, created_at, revoked_at`
+
+const (
+	// CreateAPIToken creates a new api token.
+	CreateAPIToken = `INSERT INTO api_tokens (uid, user_uid, name, prefix, token_hash, scopes, expires_at) VALUES (UUID(), ?, ?, ?, ?, ?, ?)`
+
+	// GetAPITokenByID returns an api token by id.
+	GetAPITokenByID = `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE id = ?`
+
+	// GetAPITokenByHash returns an active api token by its hash.
+	GetAPITokenByHash = `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())`
+
+	// GetAPITokensByUser returns the active api tokens of a user.
+	GetAPITokensByUser = `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE user_uid = ? AND revoked_at IS NULL ORDER BY created_at DESC`
+
+	// RevokeAPIToken revokes an api token of a user.
+	RevokeAPIToken = `UPDATE api_tokens SET revoked_at = NOW() WHERE user_uid = ? AND uid = ? AND revoked_at IS NULL`
+
+	// RevokeAPITokensByUser revokes the active api tokens of a user and of
+	// the bots it owns.
+	RevokeAPITokensByUser = `UPDATE api_tokens SET revoked_at = NOW() WHERE revoked_at IS NULL AND (user_uid = ? OR user_uid IN (SELECT uid FROM users WHERE owner_uid = ?))`
+
+	// TouchAPIToken updates the last time an api token was used.
+	TouchAPIToken = `UPDATE api_tokens SET last_used_at = NOW() WHERE uid = ?`
+)
@@ -0,0 +1,18 @@
+package queries
+
+// SQL queries template constants for the audit log.
+
+// auditEventColumns is the list of columns selected for an audit event.
+const auditEventColumns = `uid, user_uid, actor_uid, event_type, ip, user_agent, request_id, metadata, created_at`
+
+const (
+	// CreateAuditEvent appends an event to the audit log.
+	CreateAuditEvent = `INSERT INTO audit_events (uid, user_uid, actor_uid, event_type, ip, user_agent, request_id, metadata, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// GetAuditEventsByUser returns the most recent events of a user.
+	GetAuditEventsByUser = `SELECT ` + auditEventColumns + ` FROM audit_events WHERE user_uid = ? AND created_at < ? ORDER BY created_at DESC LIMIT ?`
+
+	// QueryAuditEvents is the base of the filtered admin query, the conditions
+	// are appended by the store.
+	QueryAuditEvents = `SELECT ` + auditEventColumns + ` FROM audit_events WHERE 1 = 1`
+)
@@ -17,15 +17,80 @@ const (
 	// GetFriendRequest returns a friend request by its id.
 	GetFriendRequest = `CALL get_friend_request(?, ?)`
 
-	// AcceptFriendRequest accepts a friend request.
-	AcceptFriendRequest = `UPDATE friendships SET accepted = true, accepted_at = now() WHERE (user1 = ? OR user2 = ?) AND uid = ? AND accepted = false`
+	// GetFriendRequestsByDirection returns the incoming or outgoing friend requests of a user.
+	GetFriendRequestsByDirection = `CALL get_friend_requests_by_direction(?, ?)`
+
+	// AcceptFriendRequest accepts a friend request received by the user.
+	AcceptFriendRequest = `UPDATE friendships SET accepted = true, accepted_at = now() WHERE user2 = ? AND uid = ? AND accepted = false`
+
+	// CancelFriendRequest cancels a friend request sent by the user.
+	CancelFriendRequest = `DELETE FROM friendships WHERE user1 = ? AND uid = ? AND accepted = false`
+
+	// GetReceivedFriendRequestSender returns the sender of a friend request received by the user.
+	GetReceivedFriendRequestSender = `SELECT user1 FROM friendships WHERE user2 = ? AND uid = ? AND accepted = false FOR UPDATE`
+
+	// DeleteFriendRequestByID deletes a friend request by its id.
+	DeleteFriendRequestByID = `DELETE FROM friendships WHERE uid = ? AND accepted = false`
+
+	// UpsertFriendRequestDecline suppresses requests from the sender until the cooldown ends.
+	UpsertFriendRequestDecline = `INSERT INTO friend_request_declines (sender_uid, recipient_uid, expires_at) VALUES (?, ?, DATE_ADD(NOW(), INTERVAL ? SECOND)) ON DUPLICATE KEY UPDATE expires_at = VALUES(expires_at)`
+
+	// IsFriendRequestSuppressed returns whether the recipient declined a request from the sender during the cooldown.
+	IsFriendRequestSuppressed = `SELECT EXISTS (SELECT 1 FROM friend_request_declines WHERE sender_uid = ? AND recipient_uid = ? AND expires_at > NOW())`
 
 	// DeleteFriendRequest deletes a friend request by its id.
 	DeleteFriendRequest = `DELETE FROM friendships WHERE (user1 = ? OR user2 = ?) AND (uid = ?) AND (accepted = false)`
 
-	// GetFriendsStatus returns all friends status of a user.
-	GetFriendsStatus = `CALL get_friends_status(?)`
+	// GetFriendsStatus returns all friends status of a user, it takes the
+	// status expiry window in seconds.
+	GetFriendsStatus = `CALL get_friends_status(?, ?)`
+
+	// GetFriendStatus returns a friend status of a user, it takes the status
+	// expiry window in seconds.
+	GetFriendStatus = `CALL get_friend_status(?, ?, ?)`
+
+	// CreateBlock blocks a user.
+	CreateBlock = `INSERT INTO blocks (blocker_uid, blocked_uid) VALUES (?, ?)`
+
+	// DeleteFriendshipsBetween deletes any friendship or request between two users.
+	DeleteFriendshipsBetween = `DELETE FROM friendships WHERE (user1 = ? AND user2 = ?) OR (user1 = ? AND user2 = ?)`
+
+	// DeleteBlock unblocks a user.
+	DeleteBlock = `DELETE FROM blocks WHERE blocker_uid = ? AND blocked_uid = ?`
+
+	// GetBlockedUsers returns the users blocked by a user.
+	GetBlockedUsers = `SELECT u.uid, u.username, COALESCE(p.first_name, ''), COALESCE(p.last_name, ''), COALESCE(p.avatar, ''), b.created_at FROM blocks b JOIN users u ON u.uid = b.blocked_uid LEFT JOIN profiles p ON p.user_id = u.id WHERE b.blocker_uid = ? ORDER BY b.created_at DESC`
+
+	// AreFriends returns whether two users are friends.
+	AreFriends = `SELECT EXISTS (SELECT 1 FROM friendships WHERE accepted = TRUE AND ((user1 = ? AND user2 = ?) OR (user1 = ? AND user2 = ?)))`
+
+	// HaveMutualFriend returns whether two users share a friend.
+	HaveMutualFriend = `SELECT EXISTS (SELECT 1 FROM friendships f1 JOIN friendships f2 ON f2.accepted = TRUE AND IF(f2.user1 = ?, f2.user2, f2.user1) = IF(f1.user1 = ?, f1.user2, f1.user1) WHERE f1.accepted = TRUE AND (f1.user1 = ? OR f1.user2 = ?) AND (f2.user1 = ? OR f2.user2 = ?))`
+
+	// CreateStatusMute mutes the statuses of a friend.
+	CreateStatusMute = `INSERT IGNORE INTO status_mutes (owner_uid, muted_uid) VALUES (?, ?)`
+
+	// DeleteStatusMute unmutes the statuses of a friend.
+	DeleteStatusMute = `DELETE FROM status_mutes WHERE owner_uid = ? AND muted_uid = ?`
+
+	// GetMutedFriends returns the friends whose statuses are muted by a user.
+	GetMutedFriends = `SELECT u.uid, u.username, COALESCE(p.first_name, ''), COALESCE(p.last_name, ''), COALESCE(p.avatar, ''), m.created_at FROM status_mutes m JOIN users u ON u.uid = m.muted_uid LEFT JOIN profiles p ON p.user_id = u.id WHERE m.owner_uid = ? ORDER BY m.created_at DESC`
+
+	// GetMutedUIDs returns the uids of the friends muted by a user.
+	GetMutedUIDs = `SELECT muted_uid FROM status_mutes WHERE owner_uid = ?`
+
+	// IsBlocked returns whether either user has blocked the other.
+	IsBlocked = `SELECT EXISTS (SELECT 1 FROM blocks WHERE (blocker_uid = ? AND blocked_uid = ?) OR (blocker_uid = ? AND blocked_uid = ?))`
+
+	// GetFriendSuggestions returns a page of the friend suggestions of a user. The
+	// precomputed rows are rechecked against blocks and friendships made since.
+	GetFriendSuggestions = `SELECT u.uid, u.username, COALESCE(p.first_name, ''), COALESCE(p.last_name, ''), COALESCE(p.avatar, ''), fs.mutual_count FROM friend_suggestions fs JOIN users u ON u.uid = fs.suggested_uid LEFT JOIN profiles p ON p.user_id = u.id WHERE fs.user_uid = ? AND u.deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM friendships f WHERE (f.user1 = fs.user_uid AND f.user2 = fs.suggested_uid) OR (f.user1 = fs.suggested_uid AND f.user2 = fs.user_uid)) AND NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_uid = fs.user_uid AND b.blocked_uid = fs.suggested_uid) OR (b.blocker_uid = fs.suggested_uid AND b.blocked_uid = fs.user_uid)) ORDER BY fs.mutual_count DESC, fs.suggested_uid LIMIT ? OFFSET ?`
+
+	// GetMutualFriends returns the mutual friends of a user and a set of other
+	// users, the set is expanded by the store.
+	GetMutualFriends = `SELECT IF(f2.user1 = m.uid, f2.user2, f2.user1) AS other_uid, m.uid, m.username, COALESCE(p.first_name, ''), COALESCE(p.avatar, '') FROM friendships f1 JOIN users m ON m.uid = IF(f1.user1 = ?, f1.user2, f1.user1) LEFT JOIN profiles p ON p.user_id = m.id JOIN friendships f2 ON f2.accepted = TRUE AND (f2.user1 = m.uid OR f2.user2 = m.uid) WHERE f1.accepted = TRUE AND (f1.user1 = ? OR f1.user2 = ?) AND m.deleted_at IS NULL AND IF(f2.user1 = m.uid, f2.user2, f2.user1) IN (%s) ORDER BY m.username`
 
-	// GetFriendStatus returns a friend status of a user.
-	GetFriendStatus = `CALL get_friend_status(?, ?)`
+	// RefreshFriendSuggestions recomputes the friend suggestions of every user,
+	// by batches of users.
+	RefreshFriendSuggestions = `CALL refresh_friend_suggestions(?, ?)`
 )
@@ -0,0 +1,47 @@
+package queries
+
+// SQL queries template constants for friend lists.
+
+// friendListSelect selects the friend lists with their member count.
+const friendListSelect = `SELECT l.id, l.uid, l.owner_uid, l.name, (SELECT COUNT(*) FROM friend_list_members m WHERE m.list_uid = l.uid), l.created_at FROM friend_lists l`
+
+const (
+	// CreateFriendList creates a new friend list.
+	CreateFriendList = `INSERT INTO friend_lists (uid, owner_uid, name) VALUES (UUID(), ?, ?)`
+
+	// GetFriendListByID returns a friend list by id.
+	GetFriendListByID = friendListSelect + ` WHERE l.id = ?`
+
+	// GetFriendList returns a friend list of a user.
+	GetFriendList = friendListSelect + ` WHERE l.owner_uid = ? AND l.uid = ?`
+
+	// GetFriendListsByOwner returns the friend lists of a user.
+	GetFriendListsByOwner = friendListSelect + ` WHERE l.owner_uid = ? ORDER BY l.name`
+
+	// RenameFriendList renames a friend list of a user.
+	RenameFriendList = `UPDATE friend_lists SET name = ? WHERE owner_uid = ? AND uid = ?`
+
+	// DeleteFriendListMembers deletes the members of a friend list of a user.
+	DeleteFriendListMembers = `DELETE m FROM friend_list_members m JOIN friend_lists l ON l.uid = m.list_uid WHERE l.owner_uid = ? AND l.uid = ?`
+
+	// DeleteFriendList deletes a friend list of a user.
+	DeleteFriendList = `DELETE FROM friend_lists WHERE owner_uid = ? AND uid = ?`
+
+	// GetFriendListMembers returns the members of a friend list.
+	GetFriendListMembers = `SELECT u.uid, u.username, COALESCE(p.first_name, ''), COALESCE(p.last_name, ''), COALESCE(p.avatar, ''), m.added_at FROM friend_list_members m JOIN users u ON u.uid = m.member_uid LEFT JOIN profiles p ON p.user_id = u.id WHERE m.list_uid = ? ORDER BY u.username`
+
+	// AddFriendListMember adds a user to a friend list.
+	AddFriendListMember = `INSERT IGNORE INTO friend_list_members (list_uid, member_uid) VALUES (?, ?)`
+
+	// RemoveFriendListMember removes a user from a friend list of a user.
+	RemoveFriendListMember = `DELETE m FROM friend_list_members m JOIN friend_lists l ON l.uid = m.list_uid WHERE l.owner_uid = ? AND l.uid = ? AND m.member_uid = ?`
+
+	// GetStatusHiddenFrom returns the users a user hides their statuses from.
+	GetStatusHiddenFrom = `SELECT u.uid, u.username, COALESCE(p.first_name, ''), COALESCE(p.last_name, ''), COALESCE(p.avatar, ''), h.created_at FROM status_hidden_from h JOIN users u ON u.uid = h.hidden_uid LEFT JOIN profiles p ON p.user_id = u.id WHERE h.owner_uid = ? ORDER BY u.username`
+
+	// HideStatusFrom hides the statuses of a user from another.
+	HideStatusFrom = `INSERT IGNORE INTO status_hidden_from (owner_uid, hidden_uid) VALUES (?, ?)`
+
+	// UnhideStatusFrom shows the statuses of a user to another again.
+	UnhideStatusFrom = `DELETE FROM status_hidden_from WHERE owner_uid = ? AND hidden_uid = ?`
+)
@@ -0,0 +1,45 @@
+package queries
+
+// SQL queries template constants for highlights.
+
+// highlightSelect selects the highlights.
+const highlightSelect = `SELECT h.id, h.uid, h.owner_uid, h.name, h.created_at FROM highlights h`
+
+// highlightStatusSelect selects the statuses of a highlight that aren't deleted.
+const highlightStatusSelect = `SELECT ` + statusColumns + ` FROM status WHERE uid IN (SELECT status_uid FROM highlight_items WHERE highlight_uid = ?) AND deleted_at IS NULL`
+
+const (
+	// CreateHighlight creates a new highlight.
+	CreateHighlight = `INSERT INTO highlights (uid, owner_uid, name) VALUES (UUID(), ?, ?)`
+
+	// GetHighlightByID returns a highlight by id.
+	GetHighlightByID = highlightSelect + ` WHERE h.id = ?`
+
+	// GetHighlight returns a highlight of a user.
+	GetHighlight = highlightSelect + ` WHERE h.owner_uid = ? AND h.uid = ?`
+
+	// GetHighlightsByOwner returns the highlights of a user.
+	GetHighlightsByOwner = highlightSelect + ` WHERE h.owner_uid = ? ORDER BY h.created_at`
+
+	// RenameHighlight renames a highlight of a user.
+	RenameHighlight = `UPDATE highlights SET name = ? WHERE owner_uid = ? AND uid = ?`
+
+	// DeleteHighlightItems removes every status from a highlight of a user.
+	DeleteHighlightItems = `DELETE i FROM highlight_items i JOIN highlights h ON h.uid = i.highlight_uid WHERE h.owner_uid = ? AND h.uid = ?`
+
+	// DeleteHighlight deletes a highlight of a user.
+	DeleteHighlight = `DELETE FROM highlights WHERE owner_uid = ? AND uid = ?`
+
+	// GetHighlightStatuses returns every status of a highlight.
+	GetHighlightStatuses = highlightStatusSelect + ` ORDER BY created_at`
+
+	// GetVisibleHighlightStatuses returns the statuses of a highlight shown to
+	// everyone and not hidden from the viewer.
+	GetVisibleHighlightStatuses = highlightStatusSelect + ` AND audience = 'everyone' AND NOT EXISTS (SELECT 1 FROM status_hidden_from hf WHERE hf.owner_uid = status.user_uid AND hf.hidden_uid = ?) ORDER BY created_at`
+
+	// AddHighlightStatus adds a status to a highlight.
+	AddHighlightStatus = `INSERT IGNORE INTO highlight_items (highlight_uid, status_uid) VALUES (?, ?)`
+
+	// RemoveHighlightStatus removes a status from a highlight of a user.
+	RemoveHighlightStatus = `DELETE i FROM highlight_items i JOIN highlights h ON h.uid = i.highlight_uid WHERE h.owner_uid = ? AND h.uid = ? AND i.status_uid = ?`
+)
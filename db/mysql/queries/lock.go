@@ -0,0 +1,17 @@
+package queries
+
+// SQL queries template constants for the named locks.
+
+const (
+	// GetLock takes a named lock without waiting, it returns 1 when taken.
+	GetLock = `SELECT GET_LOCK(?, 0)`
+
+	// WaitLock takes a named lock, waiting up to a number of seconds for it.
+	WaitLock = `SELECT GET_LOCK(?, ?)`
+
+	// IsLockHeld returns whether the named lock is held by this connection.
+	IsLockHeld = `SELECT IS_USED_LOCK(?) = CONNECTION_ID()`
+
+	// ReleaseLock releases a named lock held by this connection.
+	ReleaseLock = `SELECT RELEASE_LOCK(?)`
+)
@@ -0,0 +1,17 @@
+package queries
+
+// SQL queries template constants for the schema migrations.
+
+const (
+	// CreateMigrationsTable creates the table of the applied migrations.
+	CreateMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, checksum CHAR(64) NOT NULL, applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL)`
+
+	// GetAppliedMigrations returns the applied migrations.
+	GetAppliedMigrations = `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`
+
+	// CreateAppliedMigration records an applied migration.
+	CreateAppliedMigration = `INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)`
+
+	// DeleteAppliedMigration forgets a rolled back migration.
+	DeleteAppliedMigration = `DELETE FROM schema_migrations WHERE version = ?`
+)
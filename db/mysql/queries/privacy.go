@@ -0,0 +1,18 @@
+package queries
+
+// SQL queries template constants for privacy settings.
+
+// privacyColumns is the list of columns selected for privacy settings.
+const privacyColumns = `user_uid, email_visibility, bio_visibility, last_seen_visibility, status_visibility, friend_requests, searchable, updated_at`
+
+const (
+	// GetPrivacySettings returns the privacy settings of a user.
+	GetPrivacySettings = `SELECT ` + privacyColumns + ` FROM privacy_settings WHERE user_uid = ?`
+
+	// GetManyPrivacySettings returns the privacy settings of several users,
+	// the list of uids is expanded by the store.
+	GetManyPrivacySettings = `SELECT ` + privacyColumns + ` FROM privacy_settings WHERE user_uid IN (%s)`
+
+	// UpsertPrivacySettings creates or replaces the privacy settings of a user.
+	UpsertPrivacySettings = `INSERT INTO privacy_settings (user_uid, email_visibility, bio_visibility, last_seen_visibility, status_visibility, friend_requests, searchable) VALUES (?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE email_visibility = VALUES(email_visibility), bio_visibility = VALUES(bio_visibility), last_seen_visibility = VALUES(last_seen_visibility), status_visibility = VALUES(status_visibility), friend_requests = VALUES(friend_requests), searchable = VALUES(searchable), updated_at = now()`
+)
@@ -1,18 +1,22 @@
 package queries
 
 // SQL queries template constants for user profile.
+
+// profileColumns is the list of columns selected for a profile.
+const profileColumns = `p.id, p.uid, p.user_id, p.first_name, p.last_name, p.bio, p.avatar, u.username, u.email, p.created_at, p.updated_at, p.deleted_at, u.last_seen_at`
+
 const (
 	// GetUserProfile returns a profile by uid.
-	GetUserProfileByID = `SELECT p.id, p.uid, p.user_id, p.first_name, p.last_name, p.bio, p.avatar, u.username, u.email, p.created_at, p.updated_at, p.deleted_at FROM profiles p JOIN users u ON p.user_id = u.id WHERE p.id = ?`
+	GetUserProfileByID = `SELECT ` + profileColumns + ` FROM profiles p JOIN users u ON p.user_id = u.id WHERE p.id = ?`
 
 	// GetUserProfile returns a profile by uid.
-	GetUserProfileByUID = `SELECT p.id, p.uid, p.user_id, p.first_name, p.last_name, p.bio, p.avatar, u.username, u.email, p.created_at, p.updated_at, p.deleted_at FROM profiles p JOIN users u ON p.user_id = u.id WHERE p.uid = ?`
+	GetUserProfileByUID = `SELECT ` + profileColumns + ` FROM profiles p JOIN users u ON p.user_id = u.id WHERE p.uid = ?`
 
 	// GetUserProfile returns a profile by user_id.
-	GetUserProfileByUserID = `SELECT p.id, p.uid, p.user_id, p.first_name, p.last_name, p.bio, p.avatar, u.username, u.email, p.created_at, p.updated_at, p.deleted_at FROM profiles p JOIN users u ON p.user_id = u.id WHERE p.user_id = ?`
+	GetUserProfileByUserID = `SELECT ` + profileColumns + ` FROM profiles p JOIN users u ON p.user_id = u.id WHERE p.user_id = ?`
 
 	// GetUserProfile returns a profile by email.
-	GetUserProfileByEmail = `SELECT p.id, p.uid, p.user_id, p.first_name, p.last_name, p.bio, p.avatar, u.username, u.email, p.created_at, p.updated_at, p.deleted_at FROM profiles p JOIN users u ON p.user_id = u.id WHERE u.email = ?`
+	GetUserProfileByEmail = `SELECT ` + profileColumns + ` FROM profiles p JOIN users u ON p.user_id = u.id WHERE u.email = ?`
 
 	// CreateUserProfile creates a new profile.
 	CreateUserProfile = `INSERT INTO profiles (uid, user_id, first_name, last_name, bio, avatar) VALUES (?, ?, ?, ?, ?, ?)`
@@ -0,0 +1,42 @@
+package queries
+
+// SQL queries template constants for user search.
+
+// searchDocumentSelect selects the searchable view of the active users.
+const searchDocumentSelect = `SELECT u.uid, u.username, COALESCE(p.first_name, ''), COALESCE(p.last_name, ''), COALESCE(p.avatar, '') FROM users u LEFT JOIN profiles p ON p.user_id = u.id WHERE u.deleted_at IS NULL AND u.account_type = 'user' AND p.deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM privacy_settings ps WHERE ps.user_uid = u.uid AND ps.searchable = FALSE)`
+
+// friendsOfFriendsSelect selects the uids of the friends of the friends of a
+// user, it takes the user uid three times.
+const friendsOfFriendsSelect = `SELECT DISTINCT IF(f2.user1 = m.mid, f2.user2, f2.user1) AS uid FROM (SELECT IF(f1.user1 = ?, f1.user2, f1.user1) AS mid FROM friendships f1 WHERE f1.accepted = TRUE AND (f1.user1 = ? OR f1.user2 = ?)) m JOIN friendships f2 ON f2.accepted = TRUE AND (f2.user1 = m.mid OR f2.user2 = m.mid)`
+
+const (
+	// GetSearchDocuments returns the searchable view of every active user.
+	GetSearchDocuments = searchDocumentSelect
+
+	// GetSearchDocument returns the searchable view of an active user.
+	GetSearchDocument = searchDocumentSelect + ` AND u.uid = ?`
+
+	// GetFriendsOfFriends returns the uids of the friends of the friends of a user.
+	GetFriendsOfFriends = friendsOfFriendsSelect
+
+	// GetBlockRelations returns the uids of the users blocked by or blocking a user.
+	GetBlockRelations = `SELECT blocked_uid FROM blocks WHERE blocker_uid = ? UNION SELECT blocker_uid FROM blocks WHERE blocked_uid = ?`
+
+	// SearchUsers returns a page of the users matching a search, scored by
+	// full-text relevance, username prefix, name sound-alikes and whether
+	// they're friends of friends. The arguments are, in order: the boolean
+	// mode query twice, the username prefix, the first term twice, the
+	// searcher uid three times, the searcher uid, the boolean mode query
+	// twice, the username prefix, the first term twice, the searcher uid
+	// twice, whether there is no cursor, the cursor score twice, the cursor
+	// uid and the limit.
+	SearchUsers = `SELECT s.uid, s.username, s.first_name, s.last_name, s.avatar, s.score, s.friend_of_friend FROM (` +
+		`SELECT u.uid, u.username, COALESCE(p.first_name, '') AS first_name, COALESCE(p.last_name, '') AS last_name, COALESCE(p.avatar, '') AS avatar, ` +
+		`ROUND(MATCH (u.username) AGAINST (? IN BOOLEAN MODE) + COALESCE(MATCH (p.first_name, p.last_name) AGAINST (? IN BOOLEAN MODE), 0) + IF(u.username LIKE ?, 2, 0) + IF(SOUNDEX(p.first_name) = SOUNDEX(?) OR SOUNDEX(p.last_name) = SOUNDEX(?), 1, 0) + IF(fof.uid IS NULL, 0, ?), 4) AS score, ` +
+		`fof.uid IS NOT NULL AS friend_of_friend ` +
+		`FROM users u LEFT JOIN profiles p ON p.user_id = u.id LEFT JOIN (` + friendsOfFriendsSelect + `) fof ON fof.uid = u.uid ` +
+		`WHERE u.uid <> ? AND u.deleted_at IS NULL AND u.account_type = 'user' AND p.deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM privacy_settings ps WHERE ps.user_uid = u.uid AND ps.searchable = FALSE) ` +
+		`AND (MATCH (u.username) AGAINST (? IN BOOLEAN MODE) OR MATCH (p.first_name, p.last_name) AGAINST (? IN BOOLEAN MODE) OR u.username LIKE ? OR SOUNDEX(p.first_name) = SOUNDEX(?) OR SOUNDEX(p.last_name) = SOUNDEX(?)) ` +
+		`AND NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_uid = ? AND b.blocked_uid = u.uid) OR (b.blocker_uid = u.uid AND b.blocked_uid = ?))` +
+		`) s WHERE (? OR s.score < ? OR (s.score = ? AND s.uid > ?)) ORDER BY s.score DESC, s.uid LIMIT ?`
+)
@@ -1,19 +1,98 @@
 package queries
 
 // SQL queries template constants for user status.
+
+// statusColumns is the list of columns selected for a status.
+const statusColumns = `id, uid, user_uid, title, resource_uri, resource_thumbnail, audience, list_uid, (SELECT COUNT(*) FROM status_views v WHERE v.status_uid = status.uid), created_at`
+
+// purgeableBlobs joins the media of a set of statuses to the blobs their
+// author owns that no status outside the set shows. A status shows its media
+// until it's deleted, or as long as it's in a highlight. The set is expanded
+// twice by the store.
+const purgeableBlobs = ` FROM status_items i JOIN status s ON s.uid = i.status_uid JOIN blobs b ON b.owner_uid = s.user_uid AND b.uri IN (i.resource_uri, i.resource_thumbnail) WHERE i.status_uid IN (%[1]s) AND i.kind IN ('image', 'video') AND NOT EXISTS (SELECT 1 FROM status_items o JOIN status os ON os.uid = o.status_uid WHERE o.status_uid NOT IN (%[1]s) AND o.kind IN ('image', 'video') AND b.uri IN (o.resource_uri, o.resource_thumbnail) AND (os.deleted_at IS NULL OR EXISTS (SELECT 1 FROM highlight_items h WHERE h.status_uid = os.uid)))`
+
 const (
-	// GetStatus returns a status by uid which is created under 24 hours.
-	GetStatus = `SELECT id, uid, user_uid, title, resource_uri, resource_thumbnail, created_at FROM status WHERE uid = ? AND created_at > DATE_SUB(NOW(), INTERVAL 24 HOUR) AND deleted_at IS NULL`
+	// GetStatus returns a status by uid which hasn't expired, it takes the
+	// expiry window in seconds.
+	GetStatus = `SELECT ` + statusColumns + ` FROM status WHERE uid = ? AND created_at > DATE_SUB(NOW(), INTERVAL ? SECOND) AND deleted_at IS NULL`
 
-	// GetStatusByID returns a status by id.
-	GetStatusByID = `SELECT id, uid, user_uid, title, resource_uri, resource_thumbnail, created_at FROM status WHERE (user_uid = ?, uid = ?) AND deleted_at IS NULL`
+	// GetStatusByID returns a status of a user by uid.
+	GetStatusByID = `SELECT ` + statusColumns + ` FROM status WHERE user_uid = ? AND uid = ? AND deleted_at IS NULL`
 
-	// GetUsersStatus returns all status of a user.
-	GetUsersStatus = `SELECT id, uid, user_uid, title, resource_uri, resource_thumbnail, created_at FROM status WHERE user_uid = ? AND created_at > DATE_SUB(NOW(), INTERVAL 24 HOUR) AND deleted_at IS NULL`
+	// GetStatusByRowID returns a status by id.
+	GetStatusByRowID = `SELECT ` + statusColumns + ` FROM status WHERE id = ?`
+
+	// GetUsersStatus returns all status of a user which haven't expired, it
+	// takes the expiry window in seconds.
+	GetUsersStatus = `SELECT ` + statusColumns + ` FROM status WHERE user_uid = ? AND created_at > DATE_SUB(NOW(), INTERVAL ? SECOND) AND deleted_at IS NULL`
 
 	// CreateStatus creates a new status.
-	CreateStatus = `INSERT INTO status (uid, user_uid, title, resource_uri, resource_thumbnail) VALUES (UUID(),?,?,?,?)`
+	CreateStatus = `INSERT INTO status (uid, user_uid, title, resource_uri, resource_thumbnail, audience, list_uid) VALUES (UUID(),?,?,?,?,?,?)`
+
+	// GetStatusUIDByRowID returns the uid of a status by id.
+	GetStatusUIDByRowID = `SELECT uid FROM status WHERE id = ?`
+
+	// CreateStatusItem creates an item of a status.
+	CreateStatusItem = `INSERT INTO status_items (status_uid, position, kind, text, background_color, font, resource_uri, resource_thumbnail, duration_ms, caption, link_url, link_title, link_description, link_image, link_site_name) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// ClaimBlob records the author of the first status showing a media as
+	// its owner.
+	ClaimBlob = `INSERT IGNORE INTO blobs (uri, owner_uid) VALUES (?, ?)`
+
+	// CreateStatusItemMention records a user mentioned in an item of a status.
+	CreateStatusItemMention = `INSERT IGNORE INTO status_item_mentions (status_uid, position, user_uid) VALUES (?, ?, ?)`
+
+	// GetStatusItems returns the items of a set of statuses, the set is
+	// expanded by the store.
+	GetStatusItems = `SELECT status_uid, position, kind, text, background_color, font, resource_uri, resource_thumbnail, duration_ms, caption, link_url, link_title, link_description, link_image, link_site_name FROM status_items WHERE status_uid IN (%s) ORDER BY status_uid, position`
+
+	// GetStatusItemMentions returns the users mentioned in the items of a set
+	// of statuses, the set is expanded by the store.
+	GetStatusItemMentions = `SELECT m.status_uid, m.position, u.uid, u.username FROM status_item_mentions m JOIN users u ON u.uid = m.user_uid WHERE m.status_uid IN (%s) AND u.deleted_at IS NULL ORDER BY u.username`
+
+	// GetActiveStatusIDs returns which of a set of statuses are neither
+	// expired nor deleted, the set is expanded by the store and is followed
+	// by the expiry window in seconds.
+	GetActiveStatusIDs = `SELECT uid FROM status WHERE uid IN (%s) AND created_at > DATE_SUB(NOW(), INTERVAL ? SECOND) AND deleted_at IS NULL`
+
+	// GetArchivedStatuses returns the most recent expired statuses of a user
+	// created before a time, it takes the expiry window in seconds.
+	GetArchivedStatuses = `SELECT ` + statusColumns + ` FROM status WHERE user_uid = ? AND created_at <= DATE_SUB(NOW(), INTERVAL ? SECOND) AND created_at < ? AND deleted_at IS NULL ORDER BY created_at DESC LIMIT ?`
+
+	// GetPurgeableStatuses returns the statuses deleted before a time and,
+	// when the flag is set, the expired statuses outside of any highlight. It
+	// takes the expiry window in seconds.
+	GetPurgeableStatuses = `SELECT ` + statusColumns + ` FROM status WHERE (deleted_at IS NOT NULL AND deleted_at < ?) OR (? AND deleted_at IS NULL AND created_at <= DATE_SUB(NOW(), INTERVAL ? SECOND) AND NOT EXISTS (SELECT 1 FROM highlight_items h WHERE h.status_uid = status.uid)) ORDER BY id LIMIT ?`
+
+	// GetPurgeableBlobs returns the blobs of a set of statuses that can be
+	// deleted with them, by status.
+	GetPurgeableBlobs = `SELECT DISTINCT i.status_uid, b.uri` + purgeableBlobs
+
+	// PurgeBlobs deletes the ownership of the blobs purged with a set of
+	// statuses.
+	PurgeBlobs = `DELETE b` + purgeableBlobs
+
+	// PurgeStatusItemMentions deletes the mentions of a set of statuses.
+	PurgeStatusItemMentions = `DELETE FROM status_item_mentions WHERE status_uid IN (%s)`
+
+	// PurgeStatusItems deletes the items of a set of statuses.
+	PurgeStatusItems = `DELETE FROM status_items WHERE status_uid IN (%s)`
+
+	// PurgeStatusViews deletes the views of a set of statuses.
+	PurgeStatusViews = `DELETE FROM status_views WHERE status_uid IN (%s)`
+
+	// PurgeHighlightStatuses removes a set of statuses from the highlights.
+	PurgeHighlightStatuses = `DELETE FROM highlight_items WHERE status_uid IN (%s)`
+
+	// PurgeStatuses deletes a set of statuses.
+	PurgeStatuses = `DELETE FROM status WHERE uid IN (%s)`
+
+	// RecordStatusView records the first view of a status by a user.
+	RecordStatusView = `INSERT IGNORE INTO status_views (status_uid, viewer_uid) VALUES (?, ?)`
+
+	// GetStatusViewers returns the users who viewed a status.
+	GetStatusViewers = `SELECT u.uid, u.username, COALESCE(p.first_name, ''), COALESCE(p.last_name, ''), COALESCE(p.avatar, ''), v.viewed_at FROM status_views v JOIN users u ON u.uid = v.viewer_uid LEFT JOIN profiles p ON p.user_id = u.id WHERE v.status_uid = ? ORDER BY v.viewed_at DESC`
 
-	// DeleteStatus deletes a status by uid.
-	DeleteStatus = `UPDATE status SET deleted_at = now() WHERE (user_uid = ? AND uid = ?) AND deleted_at IS NULL AND created_at > DATE_SUB(NOW(), INTERVAL 24 HOUR)`
+	// DeleteStatus deletes a status by uid, archived statuses included.
+	DeleteStatus = `UPDATE status SET deleted_at = now() WHERE (user_uid = ? AND uid = ?) AND deleted_at IS NULL`
 )
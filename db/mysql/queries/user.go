@@ -2,25 +2,37 @@ package queries
 
 // SQL queries template constants for user.
 
+// userColumns is the list of columns selected for a user.
+const userColumns = `id, uid, username, email, password, account_type, owner_uid, role, created_at, updated_at, deleted_at`
+
 const (
 	// GetUser returns a user by uid.
-	GetUserByID = `SELECT * FROM users WHERE id = ?`
+	GetUserByID = `SELECT ` + userColumns + ` FROM users WHERE id = ?`
 
 	// GetUser returns a user by uid.
-	GetUserByUID = `SELECT * FROM users WHERE uid = ?`
+	GetUserByUID = `SELECT ` + userColumns + ` FROM users WHERE uid = ?`
 
 	// GetUser returns a user by username.
-	GetUserByEmail = `SELECT * FROM users WHERE email = ?`
+	GetUserByEmail = `SELECT ` + userColumns + ` FROM users WHERE email = ?`
 
 	// GetUser returns a user by username.
-	GetUserByUsername = `SELECT * FROM users WHERE username = ?`
+	GetUserByUsername = `SELECT ` + userColumns + ` FROM users WHERE username = ?`
+
+	// GetBotsByOwner returns the bot accounts owned by a user.
+	GetBotsByOwner = `SELECT ` + userColumns + ` FROM users WHERE owner_uid = ? AND account_type = 'bot' AND deleted_at IS NULL ORDER BY created_at DESC`
 
 	// CreateUser creates a new user.
-	CreateUser = `INSERT INTO users (uid,username, email, password) VALUES (UUID(),?, ?, ?)`
+	CreateUser = `INSERT INTO users (uid, username, email, password, account_type, owner_uid) VALUES (UUID(), ?, ?, ?, ?, ?)`
 
 	// UpdateUser updates a user.
 	UpdateUser = `UPDATE users SET username = COALESCE(?, username), email = COALESCE(?, email), password = COALESCE(?, password), updated_at = now() WHERE uid = ?`
 
+	// UpdateUserPassword updates the password of a user.
+	UpdateUserPassword = `UPDATE users SET password = ?, updated_at = now() WHERE uid = ? AND deleted_at IS NULL`
+
+	// TouchUserLastSeen records that a user was seen, at most once a minute.
+	TouchUserLastSeen = `UPDATE users SET last_seen_at = now() WHERE uid = ? AND (last_seen_at IS NULL OR last_seen_at < now() - INTERVAL 1 MINUTE)`
+
 	// DeleteUser deletes a user.
 	DeleteUser = `UPDATE users SET deleted_at = now() WHERE uid = ?`
 )
@@ -0,0 +1,92 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/coderero/erochat-server/db/mysql/queries"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+// SearchIndex is a MySQL full-text search index of users.
+//
+// It reads the users and profiles tables directly, so there is nothing to
+// keep in sync.
+type SearchIndex struct {
+	// db is the database of the store.
+	db *DB
+}
+
+// NewSearchIndex creates a new SearchIndex.
+func NewSearchIndex(db *DB) *SearchIndex {
+	return &SearchIndex{
+		db: db,
+	}
+}
+
+// Search returns a page of the users matching the query.
+func (s *SearchIndex) Search(ctx context.Context, query types.SearchQuery) (*types.SearchPage, error) {
+	page := &types.SearchPage{Results: []*types.SearchResult{}}
+
+	terms := types.SearchTerms(query.Query)
+	if len(terms) == 0 {
+		return page, nil
+	}
+
+	db := s.db.Reader()
+
+	// Every term matches as a prefix, the full-text index doesn't hold
+	// words shorter than innodb_ft_min_token_size so the username prefix
+	// covers those.
+	boolean := strings.Join(terms, "* ") + "*"
+	prefix := escapeLike(strings.Join(terms, "")) + "%"
+	first := terms[0]
+
+	cursor := query.Cursor
+	if cursor == nil {
+		cursor = &types.SearchCursor{}
+	}
+
+	rows, err := db.QueryContext(ctx, queries.SearchUsers,
+		boolean, boolean, prefix, first, first, types.SearchFriendOfFriendBoost,
+		query.ViewerUID, query.ViewerUID, query.ViewerUID,
+		query.ViewerUID,
+		boolean, boolean, prefix, first, first,
+		query.ViewerUID, query.ViewerUID,
+		query.Cursor == nil, cursor.Score, cursor.Score, cursor.UID,
+		query.Limit,
+	)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		result := &types.SearchResult{}
+		err = rows.Scan(&result.UID, &result.Username, &result.FirstName, &result.LastName, &result.Avatar, &result.Score, &result.FriendOfFriend)
+		if err != nil {
+			return page, err
+		}
+		page.Results = append(page.Results, result)
+	}
+	if err = rows.Err(); err != nil {
+		return page, err
+	}
+
+	if len(page.Results) == query.Limit {
+		last := page.Results[len(page.Results)-1]
+		page.NextCursor = (&types.SearchCursor{Score: last.Score, UID: last.UID}).Encode()
+	}
+	return page, nil
+}
+
+// Reindex does nothing, the index reads the tables directly.
+func (s *SearchIndex) Reindex(ctx context.Context, userID uuid.UUID) error {
+	return nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,92 @@
+//go:build integration
+
+package mysql
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/coderero/erochat-server/db/mysql/migrations"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+// openTestDB opens the database of MYSQL_TEST_DSN and applies the
+// migrations, the test is skipped without it. The database must be
+// disposable.
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN is not set")
+	}
+
+	db, err := NewDB(DBConfig{DSN: dsn, MaxOpenConns: 4, MaxIdleConns: 4})
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err = NewMigrator(db, migrations.FS).Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// createTestUser inserts an active user with a profile.
+func createTestUser(t *testing.T, db *DB, username string) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+
+	uid := uuid.New()
+	result, err := db.Primary().ExecContext(ctx, `INSERT INTO users (uid, username, email, password) VALUES (?, ?, ?, '')`, uid, username, username+"@example.com")
+	if err != nil {
+		t.Fatalf("create user %s: %v", username, err)
+	}
+	id, _ := result.LastInsertId()
+
+	_, err = db.Primary().ExecContext(ctx, `INSERT INTO profiles (uid, user_id, first_name, last_name, bio, avatar) VALUES (?, ?, 'Test', 'User', '', '')`, uid, id)
+	if err != nil {
+		t.Fatalf("create profile %s: %v", username, err)
+	}
+	return uid
+}
+
+func TestSearchIndexExcludes(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	// A prefix of its own keeps the test apart from the existing users.
+	prefix := "srch" + strings.ReplaceAll(uuid.NewString(), "-", "")[:8]
+	var (
+		viewer        = createTestUser(t, db, prefix+"_viewer")
+		visible       = createTestUser(t, db, prefix+"_visible")
+		blockedBy     = createTestUser(t, db, prefix+"_blocked_by_viewer")
+		blocking      = createTestUser(t, db, prefix+"_blocking_viewer")
+		notSearchable = createTestUser(t, db, prefix+"_not_searchable")
+	)
+
+	for _, block := range [][2]uuid.UUID{{viewer, blockedBy}, {blocking, viewer}} {
+		if _, err := db.Primary().ExecContext(ctx, `INSERT INTO blocks (blocker_uid, blocked_uid) VALUES (?, ?)`, block[0], block[1]); err != nil {
+			t.Fatalf("block: %v", err)
+		}
+	}
+	if _, err := db.Primary().ExecContext(ctx, `INSERT INTO privacy_settings (user_uid, searchable) VALUES (?, FALSE)`, notSearchable); err != nil {
+		t.Fatalf("privacy: %v", err)
+	}
+
+	page, err := NewSearchIndex(db).Search(ctx, types.SearchQuery{Query: prefix, ViewerUID: viewer, Limit: 10})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(page.Results) != 1 || page.Results[0].UID != visible {
+		var got []string
+		for _, result := range page.Results {
+			got = append(got, result.Username)
+		}
+		t.Errorf("got %q, want only %s_visible", got, prefix)
+	}
+}
@@ -1,37 +1,43 @@
 package mysql
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/coderero/erochat-server/db/mysql/queries"
+	"github.com/coderero/erochat-server/interfaces"
 	"github.com/coderero/erochat-server/types"
 	"github.com/google/uuid"
 )
 
 // StatusStore is a MySQL data store for status.
 type StatusStore struct {
-	// ConnectionPool is a pool of connections to the database.
-	pool *ConnectionPool
+	// db is the database of the store.
+	db *DB
+
+	// ttl is how long a status is active before it moves to the archive.
+	ttl time.Duration
 }
 
 // NewStatusStore creates a new StatusStore.
-func NewStatusStore(pool *ConnectionPool) *StatusStore {
+func NewStatusStore(db *DB, ttl time.Duration) *StatusStore {
 	return &StatusStore{
-		pool: pool,
+		db:  db,
+		ttl: ttl,
 	}
 }
 
 // GetStatus gets the status of a user.
-func (s *StatusStore) GetStatus(id uuid.UUID) ([]*types.UserStatus, error) {
+func (s *StatusStore) GetStatus(ctx context.Context, id uuid.UUID) ([]*types.UserStatus, error) {
 	var statuses []*types.UserStatus
 	statuses = []*types.UserStatus{}
-	db, err := s.pool.Get()
-	if err != nil {
-		return statuses, err
-	}
-	defer s.pool.Release()
+	db := s.db.Reader()
 
-	rows, err := db.Query(queries.GetUsersStatus, id)
+	rows, err := db.QueryContext(ctx, queries.GetUsersStatus, id, int(s.ttl.Seconds()))
 	if err != nil {
 		return statuses, err
 	}
@@ -39,74 +45,419 @@ func (s *StatusStore) GetStatus(id uuid.UUID) ([]*types.UserStatus, error) {
 
 	for rows.Next() {
 		status := &types.UserStatus{}
-		err = rows.Scan(&status.ID, &status.UID, &status.UserID, &status.Title, &status.ResourceURI, &status.ResourceThumbnail, &status.CreatedAt)
+		err = scanStatus(rows, status)
 		if err != nil {
 			return statuses, err
 		}
 		statuses = append(statuses, status)
 	}
+	if err = rows.Err(); err != nil {
+		return statuses, err
+	}
 
-	return statuses, nil
+	return statuses, attachStatusItems(ctx, db, statuses...)
 }
 
-func (s *StatusStore) GetStatusByUID(userUID, uid uuid.UUID) (*types.UserStatus, error) {
-	db, err := s.pool.Get()
-	if err != nil {
-		return nil, err
-	}
-	defer s.pool.Release()
+func (s *StatusStore) GetStatusByUID(ctx context.Context, userUID, uid uuid.UUID) (*types.UserStatus, error) {
+	db := s.db.Reader()
 
 	status := &types.UserStatus{}
-	err = db.QueryRow(queries.GetStatusByID, userUID, uid).Scan(&status.ID, &status.UID, &status.UserID, &status.Title, &status.ResourceURI, &status.ResourceThumbnail, &status.CreatedAt)
+	err := scanStatus(db.QueryRowContext(ctx, queries.GetStatusByID, userUID, uid), status)
 	if err != nil {
 		return nil, err
 	}
 
-	return status, nil
+	return status, attachStatusItems(ctx, db, status)
 }
 
-// CreateStatus creates a new status.
-func (s *StatusStore) CreateStatus(status *types.UserStatus) (*types.UserStatus, error) {
-	db, err := s.pool.Get()
+// CreateStatus creates a new status with its items.
+func (s *StatusStore) CreateStatus(ctx context.Context, status *types.UserStatus) (*types.UserStatus, error) {
+	db := s.db.Primary()
+
+	if status.Audience == "" {
+		status.Audience = types.StatusAudienceEveryone
+	}
+
+	var id int64
+	err := s.db.InTx(ctx, func(tx *sql.Tx) error {
+		st, err := tx.ExecContext(ctx, queries.CreateStatus, status.UserID, status.Title, status.ResourceURI, status.ResourceThumbnail, status.Audience, status.ListUID)
+		if err != nil {
+			return err
+		}
+
+		if id, err = st.LastInsertId(); err != nil {
+			return err
+		}
+
+		var uid uuid.UUID
+		if err = tx.QueryRowContext(ctx, queries.GetStatusUIDByRowID, id).Scan(&uid); err != nil {
+			return err
+		}
+
+		for i, item := range status.Items {
+			link := item.Link
+			if link == nil {
+				link = &types.LinkPreview{}
+			}
+			_, err = tx.ExecContext(ctx, queries.CreateStatusItem, uid, i, item.Kind, item.Text, item.BackgroundColor, item.Font, item.ResourceURI, item.ResourceThumbnail, item.DurationMS, item.Caption, link.URL, link.Title, link.Description, link.Image, link.SiteName)
+			if err != nil {
+				return err
+			}
+			for _, mention := range item.Mentions {
+				if _, err = tx.ExecContext(ctx, queries.CreateStatusItemMention, uid, i, mention.UID); err != nil {
+					return err
+				}
+			}
+
+			// The author of the first status showing a media owns its blob,
+			// the previews of link items are on other sites.
+			if item.Kind != types.StatusItemImage && item.Kind != types.StatusItemVideo {
+				continue
+			}
+			for _, uri := range []string{item.ResourceURI, item.ResourceThumbnail} {
+				if uri == "" {
+					continue
+				}
+				if _, err = tx.ExecContext(ctx, queries.ClaimBlob, uri, status.UserID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer s.pool.Release()
 
-	st, err := db.Exec(queries.CreateStatus, status.UserID, status.Title, status.ResourceURI, status.ResourceThumbnail)
+	created := &types.UserStatus{}
+	err = scanStatus(db.QueryRowContext(ctx, queries.GetStatusByRowID, id), created)
 	if err != nil {
 		return nil, err
 	}
+	if err = attachStatusItems(ctx, db, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// DeleteStatus deletes a status by its id.
+func (s *StatusStore) DeleteStatus(ctx context.Context, userID uuid.UUID, uid uuid.UUID) error {
+	db := s.db.Primary()
+
+	a, err := db.ExecContext(ctx, queries.DeleteStatus, userID, uid)
+	if err != nil {
+		return err
+	}
+
+	if n, err := a.RowsAffected(); err != nil || n == 0 {
+		return interfaces.ErrStatusNotFound
+	}
+
+	return nil
+}
+
+// GetArchive returns the expired statuses of a user created before the given
+// time, most recent first.
+func (s *StatusStore) GetArchive(ctx context.Context, userID uuid.UUID, before time.Time, limit int) ([]*types.UserStatus, error) {
+	var statuses []*types.UserStatus
+	statuses = []*types.UserStatus{}
+	db := s.db.Reader()
 
-	id, err := st.LastInsertId()
+	rows, err := db.QueryContext(ctx, queries.GetArchivedStatuses, userID, int(s.ttl.Seconds()), before, limit)
+	if err != nil {
+		return statuses, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		status := &types.UserStatus{}
+		if err = scanStatus(rows, status); err != nil {
+			return statuses, err
+		}
+		statuses = append(statuses, status)
+	}
+	if err = rows.Err(); err != nil {
+		return statuses, err
+	}
+	return statuses, attachStatusItems(ctx, db, statuses...)
+}
+
+// RecordView records that a user viewed a status, only the first view of
+// each user is kept.
+func (s *StatusStore) RecordView(ctx context.Context, statusID, viewerID uuid.UUID) error {
+	db := s.db.Primary()
+
+	_, err := db.ExecContext(ctx, queries.RecordStatusView, statusID, viewerID)
+	return err
+}
+
+// GetActiveStatusIDs returns which of the statuses are neither expired nor deleted.
+func (s *StatusStore) GetActiveStatusIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	active := make(map[uuid.UUID]bool, len(ids))
+	if len(ids) == 0 {
+		return active, nil
+	}
+
+	db := s.db.Reader()
+
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	args = append(args, int(s.ttl.Seconds()))
+	query := fmt.Sprintf(queries.GetActiveStatusIDs, "?"+strings.Repeat(", ?", len(ids)-1))
+
+	uids, err := queryUIDs(ctx, db, query, args...)
 	if err != nil {
 		return nil, err
 	}
+	for _, uid := range uids {
+		active[uid] = true
+	}
+	return active, nil
+}
+
+// GetPurgeableStatuses returns up to limit statuses deleted before a time and,
+// when purgeExpired is set, the expired statuses outside of any highlight,
+// with their items.
+func (s *StatusStore) GetPurgeableStatuses(ctx context.Context, deletedBefore time.Time, purgeExpired bool, limit int) ([]*types.UserStatus, error) {
+	db := s.db.Reader()
 
-	err = db.QueryRow(queries.GetStatusByID, id).Scan(&status.ID, &status.UID, &status.UserID, &status.Title, &status.ResourceURI, &status.ResourceThumbnail, &status.CreatedAt)
+	return queryStatuses(ctx, db, queries.GetPurgeableStatuses, deletedBefore, purgeExpired, int(s.ttl.Seconds()), limit)
+}
+
+// GetPurgeableBlobs returns the blobs of a set of statuses that their author
+// owns and no other status shows, by status. It reads the primary so a
+// status that was just posted with a blob keeps it.
+func (s *StatusStore) GetPurgeableBlobs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+	blobs := make(map[uuid.UUID][]string, len(ids))
+	if len(ids) == 0 {
+		return blobs, nil
+	}
+	db := s.db.Primary()
+
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	in := "?" + strings.Repeat(", ?", len(ids)-1)
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(queries.GetPurgeableBlobs, in), append(args, args...)...)
 	if err != nil {
 		return nil, err
 	}
-	return status, nil
+	defer rows.Close()
 
+	for rows.Next() {
+		var (
+			statusID uuid.UUID
+			uri      string
+		)
+		if err = rows.Scan(&statusID, &uri); err != nil {
+			return nil, err
+		}
+		blobs[statusID] = append(blobs[statusID], uri)
+	}
+	return blobs, rows.Err()
 }
 
-// DeleteStatus deletes a status by its id.
-func (s *StatusStore) DeleteStatus(userID uuid.UUID, uid uuid.UUID) error {
-	db, err := s.pool.Get()
+// PurgeStatuses deletes statuses and everything referencing them, with the
+// ownership of their purgeable blobs.
+func (s *StatusStore) PurgeStatuses(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	in := "?" + strings.Repeat(", ?", len(ids)-1)
+
+	return s.db.InTx(ctx, func(tx *sql.Tx) error {
+		// The ownership of the blobs deleted with the statuses goes first,
+		// it's found through their items.
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(queries.PurgeBlobs, in), append(args, args...)...); err != nil {
+			return err
+		}
+
+		// Children first, for the foreign keys.
+		for _, query := range []string{
+			queries.PurgeStatusItemMentions,
+			queries.PurgeStatusItems,
+			queries.PurgeStatusViews,
+			queries.PurgeHighlightStatuses,
+			queries.PurgeStatuses,
+		} {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(query, in), args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetViewers returns the users who viewed a status of a user, most recent first.
+func (s *StatusStore) GetViewers(ctx context.Context, userID, statusID uuid.UUID) ([]*types.StatusViewer, error) {
+	var viewers []*types.StatusViewer
+	viewers = []*types.StatusViewer{}
+	db := s.db.Reader()
+
+	// Viewers stay visible after the status expires, as long as it's the user's.
+	status := &types.UserStatus{}
+	if err := scanStatus(db.QueryRowContext(ctx, queries.GetStatusByID, userID, statusID), status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return viewers, interfaces.ErrStatusNotFound
+		}
+		return viewers, err
+	}
+
+	rows, err := db.QueryContext(ctx, queries.GetStatusViewers, statusID)
 	if err != nil {
-		return err
+		return viewers, err
 	}
-	defer s.pool.Release()
+	defer rows.Close()
 
-	a, err := db.Exec(queries.DeleteStatus, userID, uid)
+	for rows.Next() {
+		viewer := &types.StatusViewer{}
+		err = rows.Scan(&viewer.UID, &viewer.Username, &viewer.FirstName, &viewer.LastName, &viewer.Avatar, &viewer.ViewedAt)
+		if err != nil {
+			return viewers, err
+		}
+		viewers = append(viewers, viewer)
+	}
+	return viewers, rows.Err()
+}
+
+// scanStatus scans a row selected with the status columns into a status.
+func scanStatus(row scanner, status *types.UserStatus) error {
+	return row.Scan(&status.ID, &status.UID, &status.UserID, &status.Title, &status.ResourceURI, &status.ResourceThumbnail, &status.Audience, &status.ListUID, &status.ViewCount, &status.CreatedAt)
+}
+
+// queryStatuses runs a query selecting statuses with the status columns.
+func queryStatuses(ctx context.Context, db *sql.DB, query string, args ...any) ([]*types.UserStatus, error) {
+	statuses := []*types.UserStatus{}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return statuses, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		status := &types.UserStatus{}
+		if err = scanStatus(rows, status); err != nil {
+			return statuses, err
+		}
+		statuses = append(statuses, status)
+	}
+	if err = rows.Err(); err != nil {
+		return statuses, err
+	}
+	return statuses, attachStatusItems(ctx, db, statuses...)
+}
+
+// attachStatusItems loads the items of the statuses.
+func attachStatusItems(ctx context.Context, db *sql.DB, statuses ...*types.UserStatus) error {
+	ids := make([]uuid.UUID, 0, len(statuses))
+	for _, status := range statuses {
+		ids = append(ids, status.UID)
+	}
+
+	items, err := getStatusItems(ctx, db, ids)
 	if err != nil {
 		return err
 	}
+	for _, status := range statuses {
+		status.Items = items[status.UID]
+		if status.Items == nil {
+			status.Items = []*types.StatusItem{}
+		}
+	}
+	return nil
+}
 
-	if n, err := a.RowsAffected(); err != nil || n == 0 {
-		return fmt.Errorf("status not found")
+// attachFriendStatusItems loads the items of the friend statuses.
+func attachFriendStatusItems(ctx context.Context, db *sql.DB, statuses ...*types.FriendStatus) error {
+	ids := make([]uuid.UUID, 0, len(statuses))
+	for _, status := range statuses {
+		ids = append(ids, status.StatusID)
 	}
 
+	items, err := getStatusItems(ctx, db, ids)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		status.Items = items[status.StatusID]
+		if status.Items == nil {
+			status.Items = []*types.StatusItem{}
+		}
+	}
 	return nil
 }
+
+// getStatusItems returns the items of a set of statuses with their mentions,
+// by status.
+func getStatusItems(ctx context.Context, db *sql.DB, ids []uuid.UUID) (map[uuid.UUID][]*types.StatusItem, error) {
+	items := make(map[uuid.UUID][]*types.StatusItem, len(ids))
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	in := "?" + strings.Repeat(", ?", len(ids)-1)
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(queries.GetStatusItems, in), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type itemKey struct {
+		statusID uuid.UUID
+		position int
+	}
+	byKey := map[itemKey]*types.StatusItem{}
+	for rows.Next() {
+		var (
+			statusID uuid.UUID
+			item     = &types.StatusItem{}
+			link     = &types.LinkPreview{}
+		)
+		err = rows.Scan(&statusID, &item.Position, &item.Kind, &item.Text, &item.BackgroundColor, &item.Font, &item.ResourceURI, &item.ResourceThumbnail, &item.DurationMS, &item.Caption, &link.URL, &link.Title, &link.Description, &link.Image, &link.SiteName)
+		if err != nil {
+			return nil, err
+		}
+		if item.Kind == types.StatusItemLink {
+			item.Link = link
+		}
+		items[statusID] = append(items[statusID], item)
+		byKey[itemKey{statusID, item.Position}] = item
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	mentions, err := db.QueryContext(ctx, fmt.Sprintf(queries.GetStatusItemMentions, in), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer mentions.Close()
+
+	for mentions.Next() {
+		var (
+			key     itemKey
+			mention = &types.StatusMention{}
+		)
+		if err = mentions.Scan(&key.statusID, &key.position, &mention.UID, &mention.Username); err != nil {
+			return nil, err
+		}
+		if item, ok := byKey[key]; ok {
+			item.Mentions = append(item.Mentions, mention)
+		}
+	}
+	return items, mentions.Err()
+}
@@ -0,0 +1,84 @@
+//go:build integration
+
+package mysql
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+func TestStatusPurgeableBlobs(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	store := NewStatusStore(db, time.Hour*24)
+
+	prefix := "blob" + strings.ReplaceAll(uuid.NewString(), "-", "")[:8]
+	var (
+		owner = createTestUser(t, db, prefix+"_owner")
+		other = createTestUser(t, db, prefix+"_other")
+		media = "https://cdn.erochat.example/media/" + prefix
+	)
+
+	post := func(userID uuid.UUID, items ...*types.StatusItem) uuid.UUID {
+		t.Helper()
+		status, err := store.CreateStatus(ctx, &types.UserStatus{UserID: userID, Items: items})
+		if err != nil {
+			t.Fatalf("create status: %v", err)
+		}
+		return status.UID
+	}
+	image := func(name string) *types.StatusItem {
+		return &types.StatusItem{Kind: types.StatusItemImage, ResourceURI: media + "/" + name + ".jpg", ResourceThumbnail: media + "/" + name + ".thumb.jpg"}
+	}
+
+	var (
+		// The link and its preview image aren't blobs.
+		own = post(owner, image("a"), &types.StatusItem{Kind: types.StatusItemLink, Link: &types.LinkPreview{URL: "https://example.com/post", Image: "https://example.com/og.jpg"}})
+		// The owner of a blob is the author of its first status.
+		live   = post(owner, image("b"))
+		copied = post(other, image("b"))
+		// A deleted status keeps its blobs while it's in a highlight.
+		shared      = post(owner, image("c"))
+		highlighted = post(owner, image("c"))
+	)
+	if err := store.DeleteStatus(ctx, owner, highlighted); err != nil {
+		t.Fatalf("delete status: %v", err)
+	}
+	highlight := uuid.New()
+	if _, err := db.Primary().ExecContext(ctx, `INSERT INTO highlights (uid, owner_uid, name) VALUES (?, ?, ?)`, highlight, owner, prefix); err != nil {
+		t.Fatalf("create highlight: %v", err)
+	}
+	if _, err := db.Primary().ExecContext(ctx, `INSERT INTO highlight_items (highlight_uid, status_uid) VALUES (?, ?)`, highlight, highlighted); err != nil {
+		t.Fatalf("add highlight item: %v", err)
+	}
+
+	purged := []uuid.UUID{own, copied, shared}
+	blobs, err := store.GetPurgeableBlobs(ctx, purged)
+	if err != nil {
+		t.Fatalf("GetPurgeableBlobs: %v", err)
+	}
+	slices.Sort(blobs[own])
+	if want := []string{media + "/a.jpg", media + "/a.thumb.jpg"}; len(blobs) != 1 || !slices.Equal(blobs[own], want) {
+		t.Errorf("got %v, want the blobs %v of the owner's status only", blobs, want)
+	}
+	if _, ok := blobs[live]; ok {
+		t.Error("got the blobs of a status outside the set")
+	}
+
+	if err = store.PurgeStatuses(ctx, purged); err != nil {
+		t.Fatalf("PurgeStatuses: %v", err)
+	}
+	var owned int
+	if err = db.Primary().QueryRowContext(ctx, `SELECT COUNT(*) FROM blobs WHERE uri LIKE ?`, media+"/%").Scan(&owned); err != nil {
+		t.Fatal(err)
+	}
+	if owned != 4 {
+		t.Errorf("got %d owned blobs after the purge, want the 4 of the live and highlighted statuses", owned)
+	}
+}
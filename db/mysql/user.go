@@ -1,9 +1,10 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"log"
+	"fmt"
 	"strings"
 
 	"github.com/coderero/erochat-server/db/mysql/queries"
@@ -14,8 +15,8 @@ import (
 
 // UserStore is a MySQL data store for user.
 type UserStore struct {
-	// ConnectionPool is a pool of connections to the database.
-	pool *ConnectionPool
+	// db is the database of the store.
+	db *DB
 }
 
 // CheckUserExistsResult represents the result of checking if a user exists.
@@ -26,150 +27,226 @@ type CheckUserExistsResult struct {
 }
 
 // NewUserStore creates a new UserStore.
-func NewUserStore(pool *ConnectionPool) *UserStore {
+func NewUserStore(db *DB) *UserStore {
 	return &UserStore{
-		pool: pool,
+		db: db,
 	}
 }
 
-// GetByID returns a user by its uuid.
-func (s *UserStore) GetByID(id uuid.UUID) (*types.User, error) {
-	db, err := s.pool.Get()
-	if err != nil {
-		return nil, err
-	}
-	defer s.pool.Release()
+// GetByID returns a user by its uuid. It reads the primary, the
+// authorization checks must see a deleted user or a changed role at once.
+func (s *UserStore) GetByID(ctx context.Context, id uuid.UUID) (*types.User, error) {
+	db := s.db.Primary()
 
 	user := &types.User{}
-	err = db.QueryRow(queries.GetUserByID, id).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
+	err := scanUser(db.QueryRowContext(ctx, queries.GetUserByUID, id), user)
 	if err != nil {
 		// If the user is not found, return an error.
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, interfaces.ErrUserNotFound
 		}
-		return nil, interfaces.ErrFailedToGetUser
+		return nil, fmt.Errorf("%w: %w", interfaces.ErrFailedToGetUser, err)
 	}
 	return user, nil
 }
 
-// GetByEmail returns a user by its email.
-func (s *UserStore) GetByEmail(email string) (*types.User, error) {
-	db, err := s.pool.Get()
+// GetByEmail returns a user by its email. It reads the primary so a login
+// checks the current password and a new account is found at once.
+func (s *UserStore) GetByEmail(ctx context.Context, email string) (*types.User, error) {
+	db := s.db.Primary()
+
+	user := &types.User{}
+	err := scanUser(db.QueryRowContext(ctx, queries.GetUserByEmail, email), user)
 	if err != nil {
-		return nil, err
+		// If the user is not found, return an error.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, interfaces.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", interfaces.ErrFailedToGetUser, err)
 	}
-	defer s.pool.Release()
+	return user, nil
+}
+
+// GetByUsername returns a user by its username, from the primary like
+// GetByEmail.
+func (s *UserStore) GetByUsername(ctx context.Context, username string) (*types.User, error) {
+	db := s.db.Primary()
 
 	user := &types.User{}
-	err = db.QueryRow(queries.GetUserByEmail, email).Scan(&user.ID, &user.UID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
+	err := scanUser(db.QueryRowContext(ctx, queries.GetUserByUsername, username), user)
 	if err != nil {
 		// If the user is not found, return an error.
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, interfaces.ErrUserNotFound
 		}
-		return nil, interfaces.ErrFailedToGetUser
+		return nil, fmt.Errorf("%w: %w", interfaces.ErrFailedToGetUser, err)
 	}
 	return user, nil
 }
 
-// GetByUsername returns a user by its username.
-func (s *UserStore) GetByUsername(username string) (*types.User, error) {
-	db, err := s.pool.Get()
+// GetBotsByOwner returns the bot accounts owned by a user.
+func (s *UserStore) GetBotsByOwner(ctx context.Context, ownerID uuid.UUID) ([]*types.User, error) {
+	var users []*types.User
+	users = []*types.User{}
+	db := s.db.Reader()
+
+	rows, err := db.QueryContext(ctx, queries.GetBotsByOwner, ownerID)
 	if err != nil {
-		return nil, err
+		return users, fmt.Errorf("%w: %w", interfaces.ErrFailedToGetUser, err)
 	}
-	defer s.pool.Release()
+	defer rows.Close()
 
-	user := &types.User{}
-	err = db.QueryRow(queries.GetUserByUsername, username).Scan(&user.ID, &user.UID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
+	for rows.Next() {
+		user := &types.User{}
+		if err = scanUser(rows, user); err != nil {
+			return users, fmt.Errorf("%w: %w", interfaces.ErrFailedToGetUser, err)
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// TouchLastSeen records that a user was just seen. The write is skipped if
+// the user was already seen in the last minute.
+func (s *UserStore) TouchLastSeen(ctx context.Context, id uuid.UUID) error {
+	db := s.db.Primary()
+
+	_, err := db.ExecContext(ctx, queries.TouchUserLastSeen, id)
+	return err
+}
+
+// GetSearchDocuments returns the searchable view of every active user.
+func (s *UserStore) GetSearchDocuments(ctx context.Context) ([]*types.SearchDocument, error) {
+	var docs []*types.SearchDocument
+	docs = []*types.SearchDocument{}
+	db := s.db.Reader()
+
+	rows, err := db.QueryContext(ctx, queries.GetSearchDocuments)
+	if err != nil {
+		return docs, fmt.Errorf("%w: %w", interfaces.ErrFailedToGetUser, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		doc := &types.SearchDocument{}
+		if err = rows.Scan(&doc.UID, &doc.Username, &doc.FirstName, &doc.LastName, &doc.Avatar); err != nil {
+			return docs, fmt.Errorf("%w: %w", interfaces.ErrFailedToGetUser, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// GetSearchDocument returns the searchable view of an active user. It reads
+// the primary, a user is reindexed right after the change.
+func (s *UserStore) GetSearchDocument(ctx context.Context, id uuid.UUID) (*types.SearchDocument, error) {
+	db := s.db.Primary()
+
+	doc := &types.SearchDocument{}
+	err := db.QueryRowContext(ctx, queries.GetSearchDocument, id).Scan(&doc.UID, &doc.Username, &doc.FirstName, &doc.LastName, &doc.Avatar)
 	if err != nil {
-		// If the user is not found, return an error.
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, interfaces.ErrUserNotFound
 		}
-		return nil, interfaces.ErrFailedToGetUser
+		return nil, fmt.Errorf("%w: %w", interfaces.ErrFailedToGetUser, err)
 	}
-	return user, nil
+	return doc, nil
 }
 
 // Create creates a new user.
-func (s *UserStore) Create(user *types.User) (*types.User, error) {
-	db, err := s.pool.Get()
-	if err != nil {
-		return nil, err
+func (s *UserStore) Create(ctx context.Context, user *types.User) (*types.User, error) {
+	db := s.db.Primary()
+
+	if user.AccountType == "" {
+		user.AccountType = types.AccountTypeUser
 	}
-	defer s.pool.Release()
 
-	result, err := db.Exec(queries.CreateUser, user.Username, user.Email, user.Password)
+	result, err := db.ExecContext(ctx, queries.CreateUser, user.Username, user.Email, user.Password, user.AccountType, user.OwnerUID)
 	if err != nil {
 		return nil, checkForErrorConstraint(err)
 	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
-		return nil, interfaces.ErrFailedToCreateUser
+		return nil, fmt.Errorf("%w: %w", interfaces.ErrFailedToCreateUser, err)
 	}
 
-	err = db.QueryRow(queries.GetUserByID, id).Scan(&user.ID, &user.UID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
+	err = scanUser(db.QueryRowContext(ctx, queries.GetUserByID, id), user)
 	if err != nil {
-		log.Printf("error: %v", err)
 		// If the user is not found, return an error.
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, interfaces.ErrUserNotFound
 		}
-		return nil, interfaces.ErrFailedToCreateUser
+		return nil, fmt.Errorf("%w: %w", interfaces.ErrFailedToCreateUser, err)
 	}
 	return user, nil
 }
 
 // Update updates a user.
-func (s *UserStore) Update(id uuid.UUID, user *types.User) (*types.User, error) {
-	db, err := s.pool.Get()
-	if err != nil {
-		return nil, err
-	}
-	defer s.pool.Release()
+func (s *UserStore) Update(ctx context.Context, id uuid.UUID, user *types.User) (*types.User, error) {
+	db := s.db.Primary()
 
 	// Update a user.
-	row := db.QueryRow(queries.UpdateUser, user.Username, user.Email, user.Password, user.UpdatedAt, id, id)
-	err = row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
+	row := db.QueryRowContext(ctx, queries.UpdateUser, user.Username, user.Email, user.Password, user.UpdatedAt, id, id)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
 	if err != nil {
 		// If the user is not found, return an error.
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, checkForErrorConstraint(err)
 		}
-		return nil, interfaces.ErrFailedToUpdateUser
+		return nil, fmt.Errorf("%w: %w", interfaces.ErrFailedToUpdateUser, err)
 	}
 	return user, nil
 }
 
-// Delete deletes a user by its uuid.
-func (s *UserStore) Delete(id uuid.UUID) (uuid.UUID, error) {
-	db, err := s.pool.Get()
+// UpdatePassword updates the password hash of a user.
+func (s *UserStore) UpdatePassword(ctx context.Context, id uuid.UUID, password string) error {
+	db := s.db.Primary()
+
+	a, err := db.ExecContext(ctx, queries.UpdateUserPassword, password, id)
 	if err != nil {
-		return uuid.Nil, err
+		return fmt.Errorf("%w: %w", interfaces.ErrFailedToUpdateUser, err)
+	}
+
+	if n, err := a.RowsAffected(); err != nil || n == 0 {
+		return interfaces.ErrUserNotFound
 	}
-	defer s.pool.Release()
+	return nil
+}
+
+// Delete deletes a user by its uuid.
+func (s *UserStore) Delete(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	db := s.db.Primary()
 
 	// Delete a user.
-	row := db.QueryRow(queries.DeleteUser, id, id)
+	row := db.QueryRowContext(ctx, queries.DeleteUser, id, id)
 	var deletedID uuid.UUID
-	err = row.Scan(&deletedID)
+	err := row.Scan(&deletedID)
 	if err != nil {
 		// If the user is not found, return an error.
 		if errors.Is(err, sql.ErrNoRows) {
 			return uuid.Nil, interfaces.ErrUserNotFound
 		}
-		return uuid.Nil, interfaces.ErrFailedToDeleteUser
+		return uuid.Nil, fmt.Errorf("%w: %w", interfaces.ErrFailedToDeleteUser, err)
 	}
 	return deletedID, nil
 }
 
+// scanner is implemented by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser scans a row selected with the user columns into a user.
+func scanUser(row scanner, user *types.User) error {
+	return row.Scan(&user.ID, &user.UID, &user.Username, &user.Email, &user.Password, &user.AccountType, &user.OwnerUID, &user.Role, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
+}
+
 func checkForErrorConstraint(err error) error {
 	if strings.Contains(err.Error(), "email") {
 		return interfaces.ErrEmailExists
 	} else if strings.Contains(err.Error(), "username") {
 		return interfaces.ErrUsernameExists
 	}
-	return interfaces.ErrFailedToGetUser
+	return fmt.Errorf("%w: %w", interfaces.ErrFailedToGetUser, err)
 }
@@ -0,0 +1,39 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAPITokenNotFound is returned when the api token is not found.
+	ErrAPITokenNotFound = errors.New("api token not found")
+
+	// ErrFailedToCreateAPIToken is returned when the api token can't be created.
+	ErrFailedToCreateAPIToken = errors.New("failed to create api token")
+)
+
+// APITokenStore is a data store for personal api tokens.
+type APITokenStore interface {
+	// Create creates a new api token.
+	Create(ctx context.Context, token *types.APIToken) (*types.APIToken, error)
+
+	// GetByHash returns an active api token by its hash.
+	GetByHash(ctx context.Context, hash string) (*types.APIToken, error)
+
+	// GetByUser returns the active api tokens of a user.
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]*types.APIToken, error)
+
+	// Revoke revokes an api token of a user.
+	Revoke(ctx context.Context, userID, uid uuid.UUID) error
+
+	// RevokeAll revokes the active api tokens of a user and of the bots it
+	// owns, and returns how many were revoked.
+	RevokeAll(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// Touch records that an api token was used.
+	Touch(ctx context.Context, uid uuid.UUID) error
+}
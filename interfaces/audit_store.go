@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrFailedToRecordAuditEvent is returned when an audit event can't be recorded.
+	ErrFailedToRecordAuditEvent = errors.New("failed to record audit event")
+)
+
+// AuditStore is an append-only data store for the security audit log.
+type AuditStore interface {
+	// Record appends an event to the audit log.
+	Record(ctx context.Context, event *types.AuditEvent) error
+
+	// GetByUser returns the most recent events of a user created before the given time.
+	GetByUser(ctx context.Context, userID uuid.UUID, before time.Time, limit int) ([]*types.AuditEvent, error)
+
+	// Query returns the most recent events matching the filter.
+	Query(ctx context.Context, filter types.AuditFilter) ([]*types.AuditEvent, error)
+}
@@ -0,0 +1,9 @@
+package interfaces
+
+import "context"
+
+type BlobStore interface {
+	// Delete deletes the blob behind a url, urls the store doesn't manage and
+	// missing blobs are ignored.
+	Delete(ctx context.Context, uri string) error
+}
@@ -0,0 +1,40 @@
+package interfaces
+
+import "errors"
+
+// expectedErrors are the store errors answering a request, like a not found
+// or a conflict, rather than failing it.
+var expectedErrors = []error{
+	ErrUserNotFound,
+	ErrEmailExists,
+	ErrUsernameExists,
+	ErrProfileNotFound,
+	ErrProfileExists,
+	ErrDuplicateFriendship,
+	ErrSelfFriendship,
+	ErrBlockedFriendship,
+	ErrFriendNotFound,
+	ErrFriendStatusNotFound,
+	ErrAlreadyBlocked,
+	ErrBlockNotFound,
+	ErrMuteNotFound,
+	ErrStatusNotFound,
+	ErrAPITokenNotFound,
+	ErrFriendListNotFound,
+	ErrFriendListExists,
+	ErrFriendListMemberNotFound,
+	ErrHighlightNotFound,
+	ErrHighlightExists,
+	ErrHighlightStatusNotFound,
+}
+
+// IsExpected reports whether a store error answers the request rather than
+// failing it, the metrics and traces don't count it as a failure.
+func IsExpected(err error) bool {
+	for _, expected := range expectedErrors {
+		if errors.Is(err, expected) {
+			return true
+		}
+	}
+	return false
+}
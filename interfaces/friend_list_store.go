@@ -0,0 +1,57 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrFriendListNotFound is returned when the friend list is not found.
+	ErrFriendListNotFound = errors.New("friend list not found")
+
+	// ErrFriendListExists is returned when the owner already has a list with the name.
+	ErrFriendListExists = errors.New("friend list exists")
+
+	// ErrFriendListMemberNotFound is returned when the user is not in the friend list.
+	ErrFriendListMemberNotFound = errors.New("friend list member not found")
+)
+
+// FriendListStore is a data store for friend lists and the users statuses
+// are hidden from.
+type FriendListStore interface {
+	// Create creates a new friend list.
+	Create(ctx context.Context, list *types.FriendList) (*types.FriendList, error)
+
+	// GetByOwner returns the friend lists of a user.
+	GetByOwner(ctx context.Context, ownerID uuid.UUID) ([]*types.FriendList, error)
+
+	// Get returns a friend list of a user.
+	Get(ctx context.Context, ownerID, listID uuid.UUID) (*types.FriendList, error)
+
+	// Rename renames a friend list of a user.
+	Rename(ctx context.Context, ownerID, listID uuid.UUID, name string) error
+
+	// Delete deletes a friend list of a user and its members.
+	Delete(ctx context.Context, ownerID, listID uuid.UUID) error
+
+	// GetMembers returns the members of a friend list of a user.
+	GetMembers(ctx context.Context, ownerID, listID uuid.UUID) ([]*types.FriendListMember, error)
+
+	// AddMember adds a user to a friend list, adding a member twice is a no-op.
+	AddMember(ctx context.Context, ownerID, listID, memberID uuid.UUID) error
+
+	// RemoveMember removes a user from a friend list.
+	RemoveMember(ctx context.Context, ownerID, listID, memberID uuid.UUID) error
+
+	// GetHiddenFrom returns the users a user hides their statuses from.
+	GetHiddenFrom(ctx context.Context, ownerID uuid.UUID) ([]*types.FriendListMember, error)
+
+	// HideFrom hides the statuses of a user from another, hiding twice is a no-op.
+	HideFrom(ctx context.Context, ownerID, userID uuid.UUID) error
+
+	// UnhideFrom shows the statuses of a user to another again.
+	UnhideFrom(ctx context.Context, ownerID, userID uuid.UUID) error
+}
@@ -1,7 +1,9 @@
 package interfaces
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"github.com/coderero/erochat-server/types"
 	"github.com/google/uuid"
@@ -13,32 +15,96 @@ var (
 
 	// ErrFriendStatusNotFound is an error that is returned when the friend status is not found.
 	ErrFriendStatusNotFound = errors.New("friend status not found")
+
+	// ErrAlreadyBlocked is an error that is returned when the user is already blocked.
+	ErrAlreadyBlocked = errors.New("user already blocked")
+
+	// ErrBlockNotFound is an error that is returned when the user is not blocked.
+	ErrBlockNotFound = errors.New("block not found")
+
+	// ErrMuteNotFound is an error that is returned when the friend's statuses aren't muted.
+	ErrMuteNotFound = errors.New("mute not found")
 )
 
 type FriendStore interface {
 	// GetFriends gets the friends of a user.
-	GetFriends(userID uuid.UUID) ([]*types.Friend, error)
+	GetFriends(ctx context.Context, userID uuid.UUID) ([]*types.Friend, error)
 
 	// CreateFriend creates a new friend.
-	GetFriend(userID uuid.UUID, friendID uuid.UUID) (*types.Friend, error)
+	GetFriend(ctx context.Context, userID uuid.UUID, friendID uuid.UUID) (*types.Friend, error)
 
 	// DeleteFriend deletes a friend by its id.
-	DeleteFriend(userID, fID uuid.UUID) error
+	DeleteFriend(ctx context.Context, userID, fID uuid.UUID) error
 	// GetFriendRequests gets the friend requests of a user.
-	GetFriendRequests(userID uuid.UUID) ([]*types.Friend, error)
+	GetFriendRequests(ctx context.Context, userID uuid.UUID) ([]*types.Friend, error)
+
+	// GetFriendRequestsByDirection gets the incoming or outgoing friend requests of a user.
+	GetFriendRequestsByDirection(ctx context.Context, userID uuid.UUID, direction types.FriendRequestDirection) ([]*types.Friend, error)
 
 	// GetFriendRequest gets a friend request by its id.
-	GetFriendRequest(userID, uid uuid.UUID) (*types.Friend, error)
+	GetFriendRequest(ctx context.Context, userID, uid uuid.UUID) (*types.Friend, error)
+
+	// AcceptFriendRequest accepts a friend request, only its recipient can accept it.
+	AcceptFriendRequest(ctx context.Context, userUID, uid uuid.UUID) error
 
-	// AcceptFriendRequest accepts a friend request.
-	AcceptFriendRequest(userUID, uid uuid.UUID) error
+	// CancelFriendRequest cancels a friend request, only its sender can cancel it.
+	CancelFriendRequest(ctx context.Context, userID, reqID uuid.UUID) error
+
+	// DeclineFriendRequest declines a friend request, only its recipient can decline it.
+	// New requests from the sender are suppressed until the cooldown ends.
+	DeclineFriendRequest(ctx context.Context, userID, reqID uuid.UUID, cooldown time.Duration) error
+
+	// IsFriendRequestSuppressed reports whether the recipient declined a request
+	// from the sender less than a cooldown ago.
+	IsFriendRequestSuppressed(ctx context.Context, senderID, recipientID uuid.UUID) (bool, error)
 
 	// DeleteFriendRequest deletes a friend request by its id.
-	DeleteFriendRequest(userID, reqID uuid.UUID) error
+	DeleteFriendRequest(ctx context.Context, userID, reqID uuid.UUID) error
 
-	// GetFriendsStatus gets the friends status of a user.
-	GetFriendsStatus(userID uuid.UUID) ([]*types.FriendStatus, error)
+	// GetFriendsStatus gets the friends status of a user, the statuses of
+	// muted friends are omitted unless the options include them.
+	GetFriendsStatus(ctx context.Context, userID uuid.UUID, opts types.FriendsStatusOptions) ([]*types.FriendStatus, error)
 
 	// GetFriendStatus gets the friend status of a user.
-	GetFriendStatus(userID uuid.UUID, friendID uuid.UUID) (*types.FriendStatus, error)
+	GetFriendStatus(ctx context.Context, userID uuid.UUID, friendID uuid.UUID) (*types.FriendStatus, error)
+
+	// BlockUser blocks a user and removes any friendship or pending request between them.
+	BlockUser(ctx context.Context, userID, blockedID uuid.UUID) error
+
+	// UnblockUser unblocks a user.
+	UnblockUser(ctx context.Context, userID, blockedID uuid.UUID) error
+
+	// GetBlockedUsers gets the users blocked by a user.
+	GetBlockedUsers(ctx context.Context, userID uuid.UUID) ([]*types.BlockedUser, error)
+
+	// MuteStatus mutes the statuses of a friend, muting twice is a no-op.
+	MuteStatus(ctx context.Context, userID, friendID uuid.UUID) error
+
+	// UnmuteStatus unmutes the statuses of a friend.
+	UnmuteStatus(ctx context.Context, userID, friendID uuid.UUID) error
+
+	// GetMutedFriends gets the friends whose statuses are muted by a user.
+	GetMutedFriends(ctx context.Context, userID uuid.UUID) ([]*types.MutedFriend, error)
+
+	// IsBlocked reports whether either user has blocked the other.
+	IsBlocked(ctx context.Context, userID, otherID uuid.UUID) (bool, error)
+
+	// GetFriendSuggestions gets a page of the precomputed friend suggestions of
+	// a user, ranked by mutual friend count.
+	GetFriendSuggestions(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*types.FriendSuggestion, error)
+
+	// RefreshFriendSuggestions recomputes the friend suggestions of every user.
+	RefreshFriendSuggestions(ctx context.Context) error
+
+	// GetFriendsOfFriends returns the uids of the friends of the friends of a user.
+	GetFriendsOfFriends(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+
+	// GetBlockRelations returns the uids of the users blocked by or blocking a user.
+	GetBlockRelations(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+
+	// AreFriends reports whether two users are friends.
+	AreFriends(ctx context.Context, userID, otherID uuid.UUID) (bool, error)
+
+	// HaveMutualFriend reports whether two users share a friend.
+	HaveMutualFriend(ctx context.Context, userID, otherID uuid.UUID) (bool, error)
 }
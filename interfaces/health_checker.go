@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/coderero/erochat-server/types"
+)
+
+// HealthChecker checks a dependency the server needs to serve requests.
+type HealthChecker interface {
+	// Check returns an error when the dependency is unavailable.
+	Check(ctx context.Context) error
+}
+
+// PoolStatsProvider reports the statistics of a connection pool.
+type PoolStatsProvider interface {
+	// Stats returns the statistics of the pool.
+	Stats() types.PoolStats
+}
@@ -0,0 +1,47 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrHighlightNotFound is returned when the highlight is not found.
+	ErrHighlightNotFound = errors.New("highlight not found")
+
+	// ErrHighlightExists is returned when the owner already has a highlight with the name.
+	ErrHighlightExists = errors.New("highlight exists")
+
+	// ErrHighlightStatusNotFound is returned when the status is not in the highlight.
+	ErrHighlightStatusNotFound = errors.New("highlight status not found")
+)
+
+// HighlightStore is a data store for the highlights pinned to profiles.
+type HighlightStore interface {
+	// Create creates a new highlight.
+	Create(ctx context.Context, highlight *types.Highlight) (*types.Highlight, error)
+
+	// GetByOwner returns the highlights of a user with their statuses as seen
+	// by the viewer, the owner sees every status and other users only the
+	// statuses shown to everyone and not hidden from them.
+	GetByOwner(ctx context.Context, ownerID, viewerID uuid.UUID) ([]*types.Highlight, error)
+
+	// Get returns a highlight of a user with its statuses.
+	Get(ctx context.Context, ownerID, highlightID uuid.UUID) (*types.Highlight, error)
+
+	// Rename renames a highlight of a user.
+	Rename(ctx context.Context, ownerID, highlightID uuid.UUID, name string) error
+
+	// Delete deletes a highlight of a user, the statuses are kept.
+	Delete(ctx context.Context, ownerID, highlightID uuid.UUID) error
+
+	// AddStatus adds a status of the user to a highlight, adding a status
+	// twice is a no-op.
+	AddStatus(ctx context.Context, ownerID, highlightID, statusID uuid.UUID) error
+
+	// RemoveStatus removes a status from a highlight of a user.
+	RemoveStatus(ctx context.Context, ownerID, highlightID, statusID uuid.UUID) error
+}
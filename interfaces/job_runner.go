@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/coderero/erochat-server/types"
+
+type JobRunner interface {
+	// Status returns the status of the background jobs.
+	Status() []types.JobStatus
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import "context"
+
+// LeaderLock elects a single leader among the server instances, for the work
+// that must not run on every instance.
+type LeaderLock interface {
+	// Acquire tries to become the leader, or checks the leadership is still
+	// held. It reports whether this instance leads.
+	Acquire(ctx context.Context) (bool, error)
+
+	// Release gives up the leadership.
+	Release(ctx context.Context) error
+}
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/coderero/erochat-server/types"
+)
+
+type LinkPreviewService interface {
+	// Fetch fetches the preview metadata of a link.
+	Fetch(ctx context.Context, rawURL string) (*types.LinkPreview, error)
+}
@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/coderero/erochat-server/types"
+)
+
+var (
+	// ErrFailedToSendMessage is returned when a message can't be stored.
+	ErrFailedToSendMessage = errors.New("failed to send message")
+)
+
+// MessageStore is a data store for private messages.
+type MessageStore interface {
+	// Send stores a new message, its uid and creation time are set by the store.
+	Send(ctx context.Context, message *types.Message) (*types.Message, error)
+
+	// GetConversation returns the most recent messages of a conversation
+	// sent before the given time.
+	GetConversation(ctx context.Context, conversationID string, before time.Time, limit int) ([]*types.Message, error)
+}
@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+
+	"github.com/coderero/erochat-server/types"
+)
+
+var (
+	// ErrMigrationLocked is returned when another instance holds the migration lock.
+	ErrMigrationLocked = errors.New("migrations are locked by another instance")
+)
+
+// Migrator applies and rolls back the versioned schema migrations of a data store.
+type Migrator interface {
+	// Up applies the pending migrations in order, it returns the applied ones.
+	Up(ctx context.Context) ([]types.MigrationStatus, error)
+
+	// Down rolls back the last applied migrations, it returns the rolled back ones.
+	Down(ctx context.Context, steps int) ([]types.MigrationStatus, error)
+
+	// Status returns the state of every migration.
+	Status(ctx context.Context) ([]types.MigrationStatus, error)
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+// PrivacyStore is a data store for the privacy settings of users.
+type PrivacyStore interface {
+	// Get returns the privacy settings of a user, or the defaults if they
+	// were never changed.
+	Get(ctx context.Context, userID uuid.UUID) (*types.PrivacySettings, error)
+
+	// GetMany returns the privacy settings of several users, by uid.
+	GetMany(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]*types.PrivacySettings, error)
+
+	// Update saves the privacy settings of a user.
+	Update(ctx context.Context, settings *types.PrivacySettings) (*types.PrivacySettings, error)
+}
@@ -1,6 +1,7 @@
 package interfaces
 
 import (
+	"context"
 	"errors"
 
 	"github.com/coderero/erochat-server/types"
@@ -34,31 +35,34 @@ var (
 
 	// ErrSelfFriendship is returned when the friendship is not found.
 	ErrSelfFriendship = errors.New("self friendship")
+
+	// ErrBlockedFriendship is returned when either user has blocked the other.
+	ErrBlockedFriendship = errors.New("blocked friendship")
 )
 
 // ProfileStore is a data store for profile.
 type ProfileStore interface {
 	// GetByUID returns a profile by its uuid.
-	GetByUID(id uuid.UUID) (*types.Profile, error)
+	GetByUID(ctx context.Context, id uuid.UUID) (*types.Profile, error)
 
 	// GetByUserID returns a profile by its user id.
-	GetByUserID(id int) (*types.Profile, error)
+	GetByUserID(ctx context.Context, id int) (*types.Profile, error)
 
 	// GetByEmail returns a profile by its email.
-	GetByEmail(email string) (*types.Profile, error)
+	GetByEmail(ctx context.Context, email string) (*types.Profile, error)
 
 	// Create creates a new profile.
-	Create(profile *types.Profile) (*types.Profile, error)
+	Create(ctx context.Context, profile *types.Profile) (*types.Profile, error)
 
 	// CreateFriendship creates a new friendship.
-	CreateFriendship(userID, friendID string) error
+	CreateFriendship(ctx context.Context, userID, friendID string) error
 
 	// Update updates a profile.
-	Update(profile *types.Profile) (*types.Profile, error)
+	Update(ctx context.Context, profile *types.Profile) (*types.Profile, error)
 
 	// Delete deletes a profile by its uuid.
-	Delete(id int) error
+	Delete(ctx context.Context, id int) error
 
 	// Reactivate reactivates a profile by its uuid.
-	Reactivate(id int) error
+	Reactivate(ctx context.Context, id int) error
 }
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+// SearchIndex is an index of users searchable by username and name.
+type SearchIndex interface {
+	// Search returns a page of the users matching the query, excluding the
+	// searcher and users blocked in either direction.
+	Search(ctx context.Context, query types.SearchQuery) (*types.SearchPage, error)
+
+	// Reindex refreshes the indexed document of a user after it changed.
+	Reindex(ctx context.Context, userID uuid.UUID) error
+}
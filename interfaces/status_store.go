@@ -1,20 +1,59 @@
 package interfaces
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/coderero/erochat-server/types"
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrStatusNotFound is returned when the status is not found.
+	ErrStatusNotFound = errors.New("status not found")
+)
+
 type StatusStore interface {
 	// GetStatus gets the status of a user.
-	GetStatus(uid uuid.UUID) ([]*types.UserStatus, error)
+	GetStatus(ctx context.Context, uid uuid.UUID) ([]*types.UserStatus, error)
 
 	// GetStatusByUID gets a status by its id.
-	GetStatusByUID(userUID, uid uuid.UUID) (*types.UserStatus, error)
+	GetStatusByUID(ctx context.Context, userUID, uid uuid.UUID) (*types.UserStatus, error)
 
 	// CreateStatus creates a new status.
-	CreateStatus(status *types.UserStatus) (*types.UserStatus, error)
+	CreateStatus(ctx context.Context, status *types.UserStatus) (*types.UserStatus, error)
 
 	// DeleteStatus deletes a status by its id.
-	DeleteStatus(userID uuid.UUID, uid uuid.UUID) error
+	DeleteStatus(ctx context.Context, userID uuid.UUID, uid uuid.UUID) error
+
+	// GetArchive returns the expired statuses of a user created before the
+	// given time, most recent first.
+	GetArchive(ctx context.Context, userID uuid.UUID, before time.Time, limit int) ([]*types.UserStatus, error)
+
+	// RecordView records that a user viewed a status, only the first view
+	// of each user is kept.
+	RecordView(ctx context.Context, statusID, viewerID uuid.UUID) error
+
+	// GetActiveStatusIDs returns which of the statuses are neither expired
+	// nor deleted.
+	GetActiveStatusIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error)
+
+	// GetPurgeableStatuses returns up to limit statuses deleted before a time
+	// and, when purgeExpired is set, the expired statuses outside of any
+	// highlight, with their items.
+	GetPurgeableStatuses(ctx context.Context, deletedBefore time.Time, purgeExpired bool, limit int) ([]*types.UserStatus, error)
+
+	// GetPurgeableBlobs returns the blobs of a set of statuses that their
+	// author owns and no other status shows, by status. A status outside the
+	// set shows its media until it's deleted, or as long as it's in a
+	// highlight.
+	GetPurgeableBlobs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error)
+
+	// PurgeStatuses deletes statuses and everything referencing them, with
+	// the ownership of their purgeable blobs.
+	PurgeStatuses(ctx context.Context, ids []uuid.UUID) error
+
+	// GetViewers returns the users who viewed a status of a user, most recent first.
+	GetViewers(ctx context.Context, userID, statusID uuid.UUID) ([]*types.StatusViewer, error)
 }
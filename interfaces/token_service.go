@@ -1,6 +1,8 @@
 package interfaces
 
 import (
+	"context"
+
 	"github.com/coderero/erochat-server/api/service"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -8,17 +10,17 @@ import (
 
 type TokenService interface {
 	// Generate generates a new token.
-	GenerateTokens(email string, userId uuid.UUID) (string, string, error)
+	GenerateTokens(ctx context.Context, email string, userId uuid.UUID) (string, string, error)
 
 	// ValidateToken validates a token.
-	ValidateToken(tokenString string) (bool, error)
+	ValidateToken(ctx context.Context, tokenString string) (bool, error)
 
 	// GetClaims gets the claims from a token.
-	GetClaims(tokenString string) (jwt.MapClaims, error)
+	GetClaims(ctx context.Context, tokenString string) (jwt.MapClaims, error)
 
 	// GenerateToken generates a token.
-	GenerateToken(email string, userId uuid.UUID, tokenType service.TokenType) (string, error)
+	GenerateToken(ctx context.Context, email string, userId uuid.UUID, tokenType service.TokenType) (string, error)
 
 	// RefreshToken refreshes a token.
-	RefreshToken(refreshToken string) (string, error)
+	RefreshToken(ctx context.Context, refreshToken string) (string, error)
 }
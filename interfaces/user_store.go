@@ -1,6 +1,7 @@
 package interfaces
 
 import (
+	"context"
 	"errors"
 
 	"github.com/coderero/erochat-server/types"
@@ -32,20 +33,35 @@ var (
 
 type UserStore interface {
 	// GetByID returns a user by its uuid.
-	GetByID(uuid uuid.UUID) (*types.User, error)
+	GetByID(ctx context.Context, uuid uuid.UUID) (*types.User, error)
 
 	// GetByEmail returns a user by its email.
-	GetByEmail(email string) (*types.User, error)
+	GetByEmail(ctx context.Context, email string) (*types.User, error)
 
 	// GetByUsername returns a user by its username.
-	GetByUsername(username string) (*types.User, error)
+	GetByUsername(ctx context.Context, username string) (*types.User, error)
+
+	// GetBotsByOwner returns the bot accounts owned by a user.
+	GetBotsByOwner(ctx context.Context, ownerID uuid.UUID) ([]*types.User, error)
+
+	// GetSearchDocuments returns the searchable view of every active user.
+	GetSearchDocuments(ctx context.Context) ([]*types.SearchDocument, error)
+
+	// GetSearchDocument returns the searchable view of an active user.
+	GetSearchDocument(ctx context.Context, id uuid.UUID) (*types.SearchDocument, error)
 
 	// Create creates a new user.
-	Create(user *types.User) (*types.User, error)
+	Create(ctx context.Context, user *types.User) (*types.User, error)
+
+	// UpdatePassword updates the password hash of a user.
+	UpdatePassword(ctx context.Context, id uuid.UUID, password string) error
+
+	// TouchLastSeen records that a user was just seen.
+	TouchLastSeen(ctx context.Context, id uuid.UUID) error
 
 	// Update updates a user.
-	Update(id uuid.UUID, user *types.User) (*types.User, error)
+	Update(ctx context.Context, id uuid.UUID, user *types.User) (*types.User, error)
 
 	// Delete deletes a user by its uuid.
-	Delete(id uuid.UUID) (uuid.UUID, error)
+	Delete(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
 }
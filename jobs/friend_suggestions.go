@@ -0,0 +1,18 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+)
+
+// FriendSuggestions returns the job precomputing the friend suggestions of
+// every user, it runs on the leader only.
+func FriendSuggestions(friendStore interfaces.FriendStore, interval time.Duration) Job {
+	return Job{
+		Name:       "refresh friend suggestions",
+		Interval:   interval,
+		LeaderOnly: true,
+		Run:        friendStore.RefreshFriendSuggestions,
+	}
+}
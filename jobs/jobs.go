@@ -0,0 +1,240 @@
+// Package jobs contains the background jobs of the server.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+)
+
+// leaderReleaseTimeout bounds giving up the leadership once the jobs stopped.
+const leaderReleaseTimeout = time.Second * 5
+
+// Job is a background job run on an interval.
+type Job struct {
+	// Name is the name of the job, used in the logs and the status.
+	Name string
+
+	// Interval is the time between two runs of the job.
+	Interval time.Duration
+
+	// LeaderOnly runs the job only on the leader instance, for the work
+	// shared by every instance.
+	LeaderOnly bool
+
+	// Run runs the job once, the context is canceled when the runner stops
+	// without waiting for it.
+	Run func(ctx context.Context) error
+}
+
+// Runner runs the background jobs of the server.
+//
+// Each job runs right after a random jitter on start, then on every interval
+// plus a random jitter so the instances don't run them in lockstep. Leader
+// only jobs are skipped on the instances that don't hold the leader lock.
+type Runner struct {
+	// leader elects the instance running the leader only jobs, every
+	// instance leads when nil.
+	leader interfaces.LeaderLock
+
+	// jitter is the maximum random delay added to every interval.
+	jitter time.Duration
+
+	// mu protects the job states.
+	mu sync.Mutex
+
+	// jobs are the registered jobs and their status.
+	jobs []*jobState
+
+	// stop is closed to stop the jobs.
+	stop chan struct{}
+
+	// ctx is the context of the job runs, cancel aborts them.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// stopOnce closes stop once.
+	stopOnce sync.Once
+
+	// wg waits for the job loops to return.
+	wg sync.WaitGroup
+}
+
+// jobState is a registered job and its status.
+type jobState struct {
+	job    Job
+	status types.JobStatus
+}
+
+// NewRunner creates a new Runner.
+func NewRunner(leader interfaces.LeaderLock, jitter time.Duration) *Runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		leader: leader,
+		jitter: jitter,
+		stop:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Register registers a job, jobs must be registered before Start.
+func (r *Runner) Register(job Job) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobs = append(r.jobs, &jobState{
+		job: job,
+		status: types.JobStatus{
+			Name:       job.Name,
+			Interval:   job.Interval.String(),
+			LeaderOnly: job.LeaderOnly,
+		},
+	})
+}
+
+// Start starts the registered jobs in the background.
+func (r *Runner) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, state := range r.jobs {
+		r.wg.Add(1)
+		go r.loop(state)
+	}
+}
+
+// Stop stops scheduling the jobs, waits for the running ones to finish and
+// gives up the leadership. When the context is done the running jobs are
+// canceled, and the leadership is given up anyway.
+func (r *Runner) Stop(ctx context.Context) error {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("jobs: still running: %w", ctx.Err())
+	}
+	r.cancel()
+
+	if r.leader != nil {
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), leaderReleaseTimeout)
+		defer cancel()
+		err = errors.Join(err, r.leader.Release(releaseCtx))
+	}
+	return err
+}
+
+// Status returns the status of the registered jobs.
+func (r *Runner) Status() []types.JobStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := make([]types.JobStatus, 0, len(r.jobs))
+	for _, state := range r.jobs {
+		statuses = append(statuses, state.status)
+	}
+	return statuses
+}
+
+// loop runs a job until the runner stops.
+func (r *Runner) loop(state *jobState) {
+	defer r.wg.Done()
+
+	delay := r.delay(0)
+	for {
+		next := time.Now().Add(delay)
+		r.mu.Lock()
+		state.status.NextRunAt = &next
+		r.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.stop:
+			timer.Stop()
+			r.mu.Lock()
+			state.status.NextRunAt = nil
+			r.mu.Unlock()
+			return
+		case <-timer.C:
+		}
+
+		r.run(state)
+		delay = r.delay(state.job.Interval)
+	}
+}
+
+// run runs a job once, if this instance may run it.
+func (r *Runner) run(state *jobState) {
+	if state.job.LeaderOnly && r.leader != nil {
+		leader, err := r.leader.Acquire(r.ctx)
+		if err != nil {
+			slog.Error("failed to acquire the leader lock", slog.String("job", state.job.Name), slog.Any("error", err))
+		}
+		if !leader {
+			r.mu.Lock()
+			state.status.Skipped++
+			r.mu.Unlock()
+			return
+		}
+	}
+
+	started := time.Now()
+	r.mu.Lock()
+	state.status.Running = true
+	state.status.LastStartedAt = &started
+	r.mu.Unlock()
+
+	err := runJob(r.ctx, state.job)
+
+	finished := time.Now()
+	r.mu.Lock()
+	state.status.Running = false
+	state.status.Runs++
+	state.status.LastFinishedAt = &finished
+	state.status.LastDuration = finished.Sub(started).String()
+	state.status.LastError = ""
+	if err != nil {
+		state.status.Failures++
+		state.status.LastError = err.Error()
+	}
+	r.mu.Unlock()
+
+	if err != nil {
+		slog.Error("job failed", slog.String("job", state.job.Name), slog.Duration("duration", finished.Sub(started)), slog.Any("error", err))
+	}
+}
+
+// delay returns the interval plus a random jitter.
+func (r *Runner) delay(interval time.Duration) time.Duration {
+	if r.jitter <= 0 {
+		return interval
+	}
+	return interval + rand.N(r.jitter)
+}
+
+// runJob runs a job once, a panic fails the run instead of the server.
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return job.Run(ctx)
+}
@@ -0,0 +1,146 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+)
+
+// fakeLeaderLock leads or not, and records its release.
+type fakeLeaderLock struct {
+	leads    bool
+	mu       sync.Mutex
+	released bool
+}
+
+func (l *fakeLeaderLock) Acquire(ctx context.Context) (bool, error) {
+	return l.leads, nil
+}
+
+func (l *fakeLeaderLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released = true
+	return nil
+}
+
+// waitFor waits until a condition on the job statuses holds.
+func waitFor(t *testing.T, r *Runner, cond func(statuses []types.JobStatus) bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second * 5)
+	for !cond(r.Status()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out with the statuses %+v", r.Status())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRunnerLeaderOnly(t *testing.T) {
+	tests := []struct {
+		name   string
+		leader *fakeLeaderLock
+		runs   bool
+	}{
+		{"leader", &fakeLeaderLock{leads: true}, true},
+		{"follower", &fakeLeaderLock{leads: false}, false},
+		{"no leader lock", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var leaderRuns, everyRuns atomic.Int32
+			var leader interfaces.LeaderLock
+			if tt.leader != nil {
+				leader = tt.leader
+			}
+			r := NewRunner(leader, 0)
+			r.Register(Job{Name: "leader", Interval: time.Millisecond, LeaderOnly: true, Run: func(ctx context.Context) error {
+				leaderRuns.Add(1)
+				return nil
+			}})
+			r.Register(Job{Name: "every", Interval: time.Millisecond, Run: func(ctx context.Context) error {
+				everyRuns.Add(1)
+				return nil
+			}})
+
+			r.Start()
+			waitFor(t, r, func(statuses []types.JobStatus) bool {
+				return (statuses[0].Runs+statuses[0].Skipped) >= 3 && statuses[1].Runs >= 3
+			})
+			if err := r.Stop(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			status := r.Status()[0]
+			if tt.runs && (status.Skipped != 0 || leaderRuns.Load() == 0) {
+				t.Errorf("got %d runs and %d skipped, want the job to run", leaderRuns.Load(), status.Skipped)
+			}
+			if !tt.runs && (status.Runs != 0 || leaderRuns.Load() != 0) {
+				t.Errorf("got %d runs on a follower, want the job skipped", leaderRuns.Load())
+			}
+			if everyRuns.Load() == 0 {
+				t.Error("the job of every instance didn't run")
+			}
+		})
+	}
+}
+
+func TestRunnerStop(t *testing.T) {
+	leader := &fakeLeaderLock{leads: true}
+	r := NewRunner(leader, 0)
+
+	var (
+		started  = make(chan struct{})
+		canceled = make(chan struct{})
+		runs     atomic.Int32
+	)
+	r.Register(Job{Name: "blocking", Interval: time.Hour, LeaderOnly: true, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		close(started)
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}})
+	r.Register(Job{Name: "idle", Interval: time.Hour, Run: func(ctx context.Context) error {
+		return nil
+	}})
+
+	r.Start()
+	<-started
+
+	// The running job holds Stop until its context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	if err := r.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the deadline of the running job", err)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second * 5):
+		t.Fatal("the running job wasn't canceled")
+	}
+	leader.mu.Lock()
+	if !leader.released {
+		t.Error("the leadership wasn't given up")
+	}
+	leader.mu.Unlock()
+
+	// Stopping twice is a no-op and no run is scheduled anymore.
+	if err := r.Stop(context.Background()); err != nil {
+		t.Errorf("second stop: %v", err)
+	}
+	if status := r.Status()[1]; status.NextRunAt != nil {
+		t.Errorf("the idle job is still scheduled at %v", status.NextRunAt)
+	}
+	if n := runs.Load(); n != 1 {
+		t.Errorf("got %d runs, want 1", n)
+	}
+}
@@ -0,0 +1,100 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/google/uuid"
+)
+
+// statusCleanupBatch is the number of statuses purged at once.
+const statusCleanupBatch = 500
+
+// StatusCleanupConfig is the configuration of the status cleanup job.
+type StatusCleanupConfig struct {
+	// Interval is the time between two cleanups.
+	Interval time.Duration
+
+	// DeletedRetention is how long deleted statuses are kept before purging.
+	DeletedRetention time.Duration
+
+	// PurgeExpired purges the expired statuses outside of any highlight
+	// instead of keeping them in the owner's archive.
+	PurgeExpired bool
+}
+
+// StatusCleanup returns the job purging the deleted statuses and, when
+// configured, the expired ones. The blobs a status author owns and no other
+// status shows are deleted before its rows, a status whose blobs can't be
+// deleted is retried on the next run. It runs on the leader only.
+func StatusCleanup(statusStore interfaces.StatusStore, blobs interfaces.BlobStore, config StatusCleanupConfig) Job {
+	return Job{
+		Name:       "clean up statuses",
+		Interval:   config.Interval,
+		LeaderOnly: true,
+		Run: func(ctx context.Context) error {
+			for {
+				statuses, err := statusStore.GetPurgeableStatuses(ctx, time.Now().Add(-config.DeletedRetention), config.PurgeExpired, statusCleanupBatch)
+				if err != nil {
+					return err
+				}
+				if len(statuses) == 0 {
+					return nil
+				}
+
+				ids := make([]uuid.UUID, 0, len(statuses))
+				for _, status := range statuses {
+					ids = append(ids, status.UID)
+				}
+				purgeable, err := statusStore.GetPurgeableBlobs(ctx, ids)
+				if err != nil {
+					return err
+				}
+
+				var (
+					purged  = make([]uuid.UUID, 0, len(statuses))
+					deleted = map[string]bool{}
+					failed  int
+				)
+				for _, status := range statuses {
+					if err := deleteStatusBlobs(ctx, blobs, purgeable[status.UID], deleted); err != nil {
+						slog.Error("failed to delete status blobs", slog.String("status_uid", status.UID.String()), slog.Any("error", err))
+						failed++
+						continue
+					}
+					purged = append(purged, status.UID)
+				}
+
+				if err = statusStore.PurgeStatuses(ctx, purged); err != nil {
+					return err
+				}
+
+				// Stop instead of selecting the same failed statuses again.
+				if failed > 0 {
+					return fmt.Errorf("failed to delete the blobs of %d statuses", failed)
+				}
+				if len(statuses) < statusCleanupBatch {
+					return nil
+				}
+			}
+		},
+	}
+}
+
+// deleteStatusBlobs deletes the purgeable blobs of a status, a blob shared by
+// several statuses of the batch is deleted once.
+func deleteStatusBlobs(ctx context.Context, blobs interfaces.BlobStore, uris []string, deleted map[string]bool) error {
+	for _, uri := range uris {
+		if uri == "" || deleted[uri] {
+			continue
+		}
+		if err := blobs.Delete(ctx, uri); err != nil {
+			return err
+		}
+		deleted[uri] = true
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+var errBlob = errors.New("blob store unavailable")
+
+// fakeBlobStore records the deleted blobs and fails on the broken ones.
+type fakeBlobStore struct {
+	deleted []string
+	broken  map[string]bool
+}
+
+func (s *fakeBlobStore) Delete(ctx context.Context, uri string) error {
+	if s.broken[uri] {
+		return errBlob
+	}
+	s.deleted = append(s.deleted, uri)
+	return nil
+}
+
+// purgeStatusStore serves one batch of purgeable statuses and records the
+// purged ones, the other methods panic.
+type purgeStatusStore struct {
+	interfaces.StatusStore
+	statuses []*types.UserStatus
+	blobs    map[uuid.UUID][]string
+	purged   []uuid.UUID
+}
+
+func (s *purgeStatusStore) GetPurgeableStatuses(ctx context.Context, deletedBefore time.Time, purgeExpired bool, limit int) ([]*types.UserStatus, error) {
+	statuses := s.statuses
+	s.statuses = nil
+	return statuses, nil
+}
+
+func (s *purgeStatusStore) GetPurgeableBlobs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+	return s.blobs, nil
+}
+
+func (s *purgeStatusStore) PurgeStatuses(ctx context.Context, ids []uuid.UUID) error {
+	s.purged = append(s.purged, ids...)
+	return nil
+}
+
+func TestDeleteStatusBlobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		uris    []string
+		deleted map[string]bool
+		broken  string
+		want    []string
+		err     error
+	}{
+		{name: "none"},
+		{name: "media and thumbnail", uris: []string{"/a.mp4", "/a.jpg"}, want: []string{"/a.mp4", "/a.jpg"}},
+		{name: "twice", uris: []string{"/a.jpg", "/a.jpg"}, want: []string{"/a.jpg"}},
+		{name: "empty", uris: []string{"", "/a.jpg"}, want: []string{"/a.jpg"}},
+		{name: "deleted with another status", uris: []string{"/a.jpg", "/b.jpg"}, deleted: map[string]bool{"/a.jpg": true}, want: []string{"/b.jpg"}},
+		{name: "failure", uris: []string{"/a.jpg", "/b.jpg", "/c.jpg"}, broken: "/b.jpg", want: []string{"/a.jpg"}, err: errBlob},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blobs := &fakeBlobStore{broken: map[string]bool{tt.broken: true}}
+			deleted := map[string]bool{}
+			for uri := range tt.deleted {
+				deleted[uri] = true
+			}
+
+			if err := deleteStatusBlobs(context.Background(), blobs, tt.uris, deleted); err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if !slices.Equal(blobs.deleted, tt.want) {
+				t.Errorf("deleted %v, want %v", blobs.deleted, tt.want)
+			}
+			for _, uri := range tt.want {
+				if !deleted[uri] {
+					t.Errorf("%s isn't marked deleted", uri)
+				}
+			}
+			if tt.broken != "" && deleted[tt.broken] {
+				t.Errorf("%s is marked deleted", tt.broken)
+			}
+		})
+	}
+}
+
+func TestStatusCleanup(t *testing.T) {
+	var (
+		image = &types.UserStatus{UID: uuid.New(), ResourceURI: "https://cdn.erochat.example/media/a.jpg"}
+		// The summary of a link status is the link and its preview image.
+		link   = &types.UserStatus{UID: uuid.New(), ResourceURI: "https://example.com/post", ResourceThumbnail: "https://example.com/og.jpg"}
+		shared = &types.UserStatus{UID: uuid.New()}
+		broken = &types.UserStatus{UID: uuid.New()}
+	)
+	statuses := &purgeStatusStore{
+		statuses: []*types.UserStatus{image, link, shared, broken},
+		blobs: map[uuid.UUID][]string{
+			image.UID:  {"https://cdn.erochat.example/media/a.jpg", "https://cdn.erochat.example/media/a.thumb.jpg"},
+			shared.UID: {"https://cdn.erochat.example/media/a.jpg"},
+			broken.UID: {"https://cdn.erochat.example/media/b.jpg"},
+		},
+	}
+	blobs := &fakeBlobStore{broken: map[string]bool{"https://cdn.erochat.example/media/b.jpg": true}}
+
+	err := StatusCleanup(statuses, blobs, StatusCleanupConfig{}).Run(context.Background())
+	if err == nil {
+		t.Error("got no error with a blob failing")
+	}
+
+	want := []string{"https://cdn.erochat.example/media/a.jpg", "https://cdn.erochat.example/media/a.thumb.jpg"}
+	if !slices.Equal(blobs.deleted, want) {
+		t.Errorf("deleted %v, want only the purgeable blobs %v", blobs.deleted, want)
+	}
+	if purged := []uuid.UUID{image.UID, link.UID, shared.UID}; !slices.Equal(statuses.purged, purged) {
+		t.Errorf("purged %v, want %v without the status whose blob failed", statuses.purged, purged)
+	}
+}
@@ -0,0 +1,161 @@
+// Package metrics contains the Prometheus metrics of the server.
+//
+// Collectors are registered on a dedicated registry served by Handler, along
+// with the Go runtime and process collectors. Stores and services are
+// instrumented through decorators so their interfaces stay unchanged.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the metric names.
+const namespace = "erochat"
+
+var (
+	// Registry is the registry of the server metrics.
+	Registry = prometheus.NewRegistry()
+
+	// httpRequests counts the HTTP requests.
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	// httpDuration observes the HTTP request latencies.
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latencies by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// loginAttempts counts the password logins.
+	loginAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "login_attempts_total",
+		Help:      "Password logins by result and failure reason.",
+	}, []string{"result", "reason"})
+
+	// passwordHashDuration observes the scrypt latencies.
+	passwordHashDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "password_hash_duration_seconds",
+		Help:      "Password hashing latencies by operation.",
+		Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	// storeDuration observes the store call latencies.
+	storeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "store",
+		Name:      "query_duration_seconds",
+		Help:      "Store call latencies by store and method.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"store", "method"})
+
+	// storeErrors counts the failed store calls.
+	storeErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "store",
+		Name:      "errors_total",
+		Help:      "Failed store calls by store and method, not found and conflict results excluded.",
+	}, []string{"store", "method"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		loginAttempts,
+		passwordHashDuration,
+		storeDuration,
+		storeErrors,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRequest records an HTTP request, route is the route template so
+// path parameters don't create new series.
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	labels := prometheus.Labels{"method": method, "route": route, "status": strconv.Itoa(status)}
+	httpRequests.With(labels).Inc()
+	httpDuration.With(labels).Observe(duration.Seconds())
+}
+
+// ObserveLogin records a successful password login.
+func ObserveLogin() {
+	loginAttempts.WithLabelValues("success", "").Inc()
+}
+
+// ObserveLoginFailure records a failed password login and its reason.
+func ObserveLoginFailure(reason string) {
+	loginAttempts.WithLabelValues("failure", reason).Inc()
+}
+
+// RegisterPool exports the statistics of a database connection pool as
+// gauges, labelled with the database name.
+func RegisterPool(name string, pool interfaces.PoolStatsProvider) {
+	labels := prometheus.Labels{"db": name}
+	gauge := func(metric, help string, value func() float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   "db_pool",
+			Name:        metric,
+			Help:        help,
+			ConstLabels: labels,
+		}, value)
+	}
+
+	Registry.MustRegister(
+		gauge("max_open_connections", "Maximum number of open connections.", func() float64 {
+			return float64(pool.Stats().MaxOpenConnections)
+		}),
+		gauge("open_connections", "Number of open connections.", func() float64 {
+			return float64(pool.Stats().OpenConnections)
+		}),
+		gauge("in_use_connections", "Number of connections in use.", func() float64 {
+			return float64(pool.Stats().InUse)
+		}),
+		gauge("idle_connections", "Number of idle connections.", func() float64 {
+			return float64(pool.Stats().Idle)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "db_pool",
+			Name:        "wait_count_total",
+			Help:        "Number of connections waited for.",
+			ConstLabels: labels,
+		}, func() float64 {
+			return float64(pool.Stats().WaitCount)
+		}),
+	)
+}
+
+// observeStore records a store call, it's deferred with a pointer to the
+// named error result of the call.
+func observeStore(store, method string, started time.Time, err *error) {
+	storeDuration.WithLabelValues(store, method).Observe(time.Since(started).Seconds())
+	if *err == nil || interfaces.IsExpected(*err) {
+		return
+	}
+	storeErrors.WithLabelValues(store, method).Inc()
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+)
+
+// passService instruments the password hashing.
+type passService struct {
+	next interfaces.PassService
+}
+
+// NewPassService instruments a PassService.
+func NewPassService(next interfaces.PassService) interfaces.PassService {
+	return &passService{next: next}
+}
+
+// Hash implements interfaces.PassService.
+func (s *passService) Hash(password string) (string, error) {
+	defer observePasswordHash("hash", time.Now())
+	return s.next.Hash(password)
+}
+
+// Compare implements interfaces.PassService.
+func (s *passService) Compare(password, hash string) bool {
+	defer observePasswordHash("compare", time.Now())
+	return s.next.Compare(password, hash)
+}
+
+// observePasswordHash records a password hashing.
+func observePasswordHash(operation string, started time.Time) {
+	passwordHashDuration.WithLabelValues(operation).Observe(time.Since(started).Seconds())
+}
@@ -0,0 +1,386 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+// userStore instruments the user store.
+type userStore struct {
+	next interfaces.UserStore
+}
+
+// NewUserStore instruments a UserStore.
+func NewUserStore(next interfaces.UserStore) interfaces.UserStore {
+	return &userStore{next: next}
+}
+
+// GetByID implements interfaces.UserStore.
+func (s *userStore) GetByID(ctx context.Context, id uuid.UUID) (result *types.User, err error) {
+	defer observeStore("user", "GetByID", time.Now(), &err)
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByEmail implements interfaces.UserStore.
+func (s *userStore) GetByEmail(ctx context.Context, email string) (result *types.User, err error) {
+	defer observeStore("user", "GetByEmail", time.Now(), &err)
+	return s.next.GetByEmail(ctx, email)
+}
+
+// GetByUsername implements interfaces.UserStore.
+func (s *userStore) GetByUsername(ctx context.Context, username string) (result *types.User, err error) {
+	defer observeStore("user", "GetByUsername", time.Now(), &err)
+	return s.next.GetByUsername(ctx, username)
+}
+
+// GetBotsByOwner implements interfaces.UserStore.
+func (s *userStore) GetBotsByOwner(ctx context.Context, ownerID uuid.UUID) (result []*types.User, err error) {
+	defer observeStore("user", "GetBotsByOwner", time.Now(), &err)
+	return s.next.GetBotsByOwner(ctx, ownerID)
+}
+
+// GetSearchDocuments implements interfaces.UserStore.
+func (s *userStore) GetSearchDocuments(ctx context.Context) (result []*types.SearchDocument, err error) {
+	defer observeStore("user", "GetSearchDocuments", time.Now(), &err)
+	return s.next.GetSearchDocuments(ctx)
+}
+
+// GetSearchDocument implements interfaces.UserStore.
+func (s *userStore) GetSearchDocument(ctx context.Context, id uuid.UUID) (result *types.SearchDocument, err error) {
+	defer observeStore("user", "GetSearchDocument", time.Now(), &err)
+	return s.next.GetSearchDocument(ctx, id)
+}
+
+// Create implements interfaces.UserStore.
+func (s *userStore) Create(ctx context.Context, user *types.User) (result *types.User, err error) {
+	defer observeStore("user", "Create", time.Now(), &err)
+	return s.next.Create(ctx, user)
+}
+
+// UpdatePassword implements interfaces.UserStore.
+func (s *userStore) UpdatePassword(ctx context.Context, id uuid.UUID, password string) (err error) {
+	defer observeStore("user", "UpdatePassword", time.Now(), &err)
+	return s.next.UpdatePassword(ctx, id, password)
+}
+
+// TouchLastSeen implements interfaces.UserStore.
+func (s *userStore) TouchLastSeen(ctx context.Context, id uuid.UUID) (err error) {
+	defer observeStore("user", "TouchLastSeen", time.Now(), &err)
+	return s.next.TouchLastSeen(ctx, id)
+}
+
+// Update implements interfaces.UserStore.
+func (s *userStore) Update(ctx context.Context, id uuid.UUID, user *types.User) (result *types.User, err error) {
+	defer observeStore("user", "Update", time.Now(), &err)
+	return s.next.Update(ctx, id, user)
+}
+
+// Delete implements interfaces.UserStore.
+func (s *userStore) Delete(ctx context.Context, id uuid.UUID) (result uuid.UUID, err error) {
+	defer observeStore("user", "Delete", time.Now(), &err)
+	return s.next.Delete(ctx, id)
+}
+
+// profileStore instruments the profile store.
+type profileStore struct {
+	next interfaces.ProfileStore
+}
+
+// NewProfileStore instruments a ProfileStore.
+func NewProfileStore(next interfaces.ProfileStore) interfaces.ProfileStore {
+	return &profileStore{next: next}
+}
+
+// GetByUID implements interfaces.ProfileStore.
+func (s *profileStore) GetByUID(ctx context.Context, id uuid.UUID) (result *types.Profile, err error) {
+	defer observeStore("profile", "GetByUID", time.Now(), &err)
+	return s.next.GetByUID(ctx, id)
+}
+
+// GetByUserID implements interfaces.ProfileStore.
+func (s *profileStore) GetByUserID(ctx context.Context, id int) (result *types.Profile, err error) {
+	defer observeStore("profile", "GetByUserID", time.Now(), &err)
+	return s.next.GetByUserID(ctx, id)
+}
+
+// GetByEmail implements interfaces.ProfileStore.
+func (s *profileStore) GetByEmail(ctx context.Context, email string) (result *types.Profile, err error) {
+	defer observeStore("profile", "GetByEmail", time.Now(), &err)
+	return s.next.GetByEmail(ctx, email)
+}
+
+// Create implements interfaces.ProfileStore.
+func (s *profileStore) Create(ctx context.Context, profile *types.Profile) (result *types.Profile, err error) {
+	defer observeStore("profile", "Create", time.Now(), &err)
+	return s.next.Create(ctx, profile)
+}
+
+// CreateFriendship implements interfaces.ProfileStore.
+func (s *profileStore) CreateFriendship(ctx context.Context, userID, friendID string) (err error) {
+	defer observeStore("profile", "CreateFriendship", time.Now(), &err)
+	return s.next.CreateFriendship(ctx, userID, friendID)
+}
+
+// Update implements interfaces.ProfileStore.
+func (s *profileStore) Update(ctx context.Context, profile *types.Profile) (result *types.Profile, err error) {
+	defer observeStore("profile", "Update", time.Now(), &err)
+	return s.next.Update(ctx, profile)
+}
+
+// Delete implements interfaces.ProfileStore.
+func (s *profileStore) Delete(ctx context.Context, id int) (err error) {
+	defer observeStore("profile", "Delete", time.Now(), &err)
+	return s.next.Delete(ctx, id)
+}
+
+// Reactivate implements interfaces.ProfileStore.
+func (s *profileStore) Reactivate(ctx context.Context, id int) (err error) {
+	defer observeStore("profile", "Reactivate", time.Now(), &err)
+	return s.next.Reactivate(ctx, id)
+}
+
+// friendStore instruments the friend store.
+type friendStore struct {
+	next interfaces.FriendStore
+}
+
+// NewFriendStore instruments a FriendStore.
+func NewFriendStore(next interfaces.FriendStore) interfaces.FriendStore {
+	return &friendStore{next: next}
+}
+
+// GetFriends implements interfaces.FriendStore.
+func (s *friendStore) GetFriends(ctx context.Context, userID uuid.UUID) (result []*types.Friend, err error) {
+	defer observeStore("friend", "GetFriends", time.Now(), &err)
+	return s.next.GetFriends(ctx, userID)
+}
+
+// GetFriend implements interfaces.FriendStore.
+func (s *friendStore) GetFriend(ctx context.Context, userID uuid.UUID, friendID uuid.UUID) (result *types.Friend, err error) {
+	defer observeStore("friend", "GetFriend", time.Now(), &err)
+	return s.next.GetFriend(ctx, userID, friendID)
+}
+
+// DeleteFriend implements interfaces.FriendStore.
+func (s *friendStore) DeleteFriend(ctx context.Context, userID, fID uuid.UUID) (err error) {
+	defer observeStore("friend", "DeleteFriend", time.Now(), &err)
+	return s.next.DeleteFriend(ctx, userID, fID)
+}
+
+// GetFriendRequests implements interfaces.FriendStore.
+func (s *friendStore) GetFriendRequests(ctx context.Context, userID uuid.UUID) (result []*types.Friend, err error) {
+	defer observeStore("friend", "GetFriendRequests", time.Now(), &err)
+	return s.next.GetFriendRequests(ctx, userID)
+}
+
+// GetFriendRequestsByDirection implements interfaces.FriendStore.
+func (s *friendStore) GetFriendRequestsByDirection(ctx context.Context, userID uuid.UUID, direction types.FriendRequestDirection) (result []*types.Friend, err error) {
+	defer observeStore("friend", "GetFriendRequestsByDirection", time.Now(), &err)
+	return s.next.GetFriendRequestsByDirection(ctx, userID, direction)
+}
+
+// GetFriendRequest implements interfaces.FriendStore.
+func (s *friendStore) GetFriendRequest(ctx context.Context, userID, uid uuid.UUID) (result *types.Friend, err error) {
+	defer observeStore("friend", "GetFriendRequest", time.Now(), &err)
+	return s.next.GetFriendRequest(ctx, userID, uid)
+}
+
+// AcceptFriendRequest implements interfaces.FriendStore.
+func (s *friendStore) AcceptFriendRequest(ctx context.Context, userUID, uid uuid.UUID) (err error) {
+	defer observeStore("friend", "AcceptFriendRequest", time.Now(), &err)
+	return s.next.AcceptFriendRequest(ctx, userUID, uid)
+}
+
+// CancelFriendRequest implements interfaces.FriendStore.
+func (s *friendStore) CancelFriendRequest(ctx context.Context, userID, reqID uuid.UUID) (err error) {
+	defer observeStore("friend", "CancelFriendRequest", time.Now(), &err)
+	return s.next.CancelFriendRequest(ctx, userID, reqID)
+}
+
+// DeclineFriendRequest implements interfaces.FriendStore.
+func (s *friendStore) DeclineFriendRequest(ctx context.Context, userID, reqID uuid.UUID, cooldown time.Duration) (err error) {
+	defer observeStore("friend", "DeclineFriendRequest", time.Now(), &err)
+	return s.next.DeclineFriendRequest(ctx, userID, reqID, cooldown)
+}
+
+// IsFriendRequestSuppressed implements interfaces.FriendStore.
+func (s *friendStore) IsFriendRequestSuppressed(ctx context.Context, senderID, recipientID uuid.UUID) (result bool, err error) {
+	defer observeStore("friend", "IsFriendRequestSuppressed", time.Now(), &err)
+	return s.next.IsFriendRequestSuppressed(ctx, senderID, recipientID)
+}
+
+// DeleteFriendRequest implements interfaces.FriendStore.
+func (s *friendStore) DeleteFriendRequest(ctx context.Context, userID, reqID uuid.UUID) (err error) {
+	defer observeStore("friend", "DeleteFriendRequest", time.Now(), &err)
+	return s.next.DeleteFriendRequest(ctx, userID, reqID)
+}
+
+// GetFriendsStatus implements interfaces.FriendStore.
+func (s *friendStore) GetFriendsStatus(ctx context.Context, userID uuid.UUID, opts types.FriendsStatusOptions) (result []*types.FriendStatus, err error) {
+	defer observeStore("friend", "GetFriendsStatus", time.Now(), &err)
+	return s.next.GetFriendsStatus(ctx, userID, opts)
+}
+
+// GetFriendStatus implements interfaces.FriendStore.
+func (s *friendStore) GetFriendStatus(ctx context.Context, userID uuid.UUID, friendID uuid.UUID) (result *types.FriendStatus, err error) {
+	defer observeStore("friend", "GetFriendStatus", time.Now(), &err)
+	return s.next.GetFriendStatus(ctx, userID, friendID)
+}
+
+// BlockUser implements interfaces.FriendStore.
+func (s *friendStore) BlockUser(ctx context.Context, userID, blockedID uuid.UUID) (err error) {
+	defer observeStore("friend", "BlockUser", time.Now(), &err)
+	return s.next.BlockUser(ctx, userID, blockedID)
+}
+
+// UnblockUser implements interfaces.FriendStore.
+func (s *friendStore) UnblockUser(ctx context.Context, userID, blockedID uuid.UUID) (err error) {
+	defer observeStore("friend", "UnblockUser", time.Now(), &err)
+	return s.next.UnblockUser(ctx, userID, blockedID)
+}
+
+// GetBlockedUsers implements interfaces.FriendStore.
+func (s *friendStore) GetBlockedUsers(ctx context.Context, userID uuid.UUID) (result []*types.BlockedUser, err error) {
+	defer observeStore("friend", "GetBlockedUsers", time.Now(), &err)
+	return s.next.GetBlockedUsers(ctx, userID)
+}
+
+// MuteStatus implements interfaces.FriendStore.
+func (s *friendStore) MuteStatus(ctx context.Context, userID, friendID uuid.UUID) (err error) {
+	defer observeStore("friend", "MuteStatus", time.Now(), &err)
+	return s.next.MuteStatus(ctx, userID, friendID)
+}
+
+// UnmuteStatus implements interfaces.FriendStore.
+func (s *friendStore) UnmuteStatus(ctx context.Context, userID, friendID uuid.UUID) (err error) {
+	defer observeStore("friend", "UnmuteStatus", time.Now(), &err)
+	return s.next.UnmuteStatus(ctx, userID, friendID)
+}
+
+// GetMutedFriends implements interfaces.FriendStore.
+func (s *friendStore) GetMutedFriends(ctx context.Context, userID uuid.UUID) (result []*types.MutedFriend, err error) {
+	defer observeStore("friend", "GetMutedFriends", time.Now(), &err)
+	return s.next.GetMutedFriends(ctx, userID)
+}
+
+// IsBlocked implements interfaces.FriendStore.
+func (s *friendStore) IsBlocked(ctx context.Context, userID, otherID uuid.UUID) (result bool, err error) {
+	defer observeStore("friend", "IsBlocked", time.Now(), &err)
+	return s.next.IsBlocked(ctx, userID, otherID)
+}
+
+// GetFriendSuggestions implements interfaces.FriendStore.
+func (s *friendStore) GetFriendSuggestions(ctx context.Context, userID uuid.UUID, limit, offset int) (result []*types.FriendSuggestion, err error) {
+	defer observeStore("friend", "GetFriendSuggestions", time.Now(), &err)
+	return s.next.GetFriendSuggestions(ctx, userID, limit, offset)
+}
+
+// RefreshFriendSuggestions implements interfaces.FriendStore.
+func (s *friendStore) RefreshFriendSuggestions(ctx context.Context) (err error) {
+	defer observeStore("friend", "RefreshFriendSuggestions", time.Now(), &err)
+	return s.next.RefreshFriendSuggestions(ctx)
+}
+
+// GetFriendsOfFriends implements interfaces.FriendStore.
+func (s *friendStore) GetFriendsOfFriends(ctx context.Context, userID uuid.UUID) (result []uuid.UUID, err error) {
+	defer observeStore("friend", "GetFriendsOfFriends", time.Now(), &err)
+	return s.next.GetFriendsOfFriends(ctx, userID)
+}
+
+// GetBlockRelations implements interfaces.FriendStore.
+func (s *friendStore) GetBlockRelations(ctx context.Context, userID uuid.UUID) (result []uuid.UUID, err error) {
+	defer observeStore("friend", "GetBlockRelations", time.Now(), &err)
+	return s.next.GetBlockRelations(ctx, userID)
+}
+
+// AreFriends implements interfaces.FriendStore.
+func (s *friendStore) AreFriends(ctx context.Context, userID, otherID uuid.UUID) (result bool, err error) {
+	defer observeStore("friend", "AreFriends", time.Now(), &err)
+	return s.next.AreFriends(ctx, userID, otherID)
+}
+
+// HaveMutualFriend implements interfaces.FriendStore.
+func (s *friendStore) HaveMutualFriend(ctx context.Context, userID, otherID uuid.UUID) (result bool, err error) {
+	defer observeStore("friend", "HaveMutualFriend", time.Now(), &err)
+	return s.next.HaveMutualFriend(ctx, userID, otherID)
+}
+
+// statusStore instruments the status store.
+type statusStore struct {
+	next interfaces.StatusStore
+}
+
+// NewStatusStore instruments a StatusStore.
+func NewStatusStore(next interfaces.StatusStore) interfaces.StatusStore {
+	return &statusStore{next: next}
+}
+
+// GetStatus implements interfaces.StatusStore.
+func (s *statusStore) GetStatus(ctx context.Context, uid uuid.UUID) (result []*types.UserStatus, err error) {
+	defer observeStore("status", "GetStatus", time.Now(), &err)
+	return s.next.GetStatus(ctx, uid)
+}
+
+// GetStatusByUID implements interfaces.StatusStore.
+func (s *statusStore) GetStatusByUID(ctx context.Context, userUID, uid uuid.UUID) (result *types.UserStatus, err error) {
+	defer observeStore("status", "GetStatusByUID", time.Now(), &err)
+	return s.next.GetStatusByUID(ctx, userUID, uid)
+}
+
+// CreateStatus implements interfaces.StatusStore.
+func (s *statusStore) CreateStatus(ctx context.Context, status *types.UserStatus) (result *types.UserStatus, err error) {
+	defer observeStore("status", "CreateStatus", time.Now(), &err)
+	return s.next.CreateStatus(ctx, status)
+}
+
+// DeleteStatus implements interfaces.StatusStore.
+func (s *statusStore) DeleteStatus(ctx context.Context, userID uuid.UUID, uid uuid.UUID) (err error) {
+	defer observeStore("status", "DeleteStatus", time.Now(), &err)
+	return s.next.DeleteStatus(ctx, userID, uid)
+}
+
+// GetArchive implements interfaces.StatusStore.
+func (s *statusStore) GetArchive(ctx context.Context, userID uuid.UUID, before time.Time, limit int) (result []*types.UserStatus, err error) {
+	defer observeStore("status", "GetArchive", time.Now(), &err)
+	return s.next.GetArchive(ctx, userID, before, limit)
+}
+
+// RecordView implements interfaces.StatusStore.
+func (s *statusStore) RecordView(ctx context.Context, statusID, viewerID uuid.UUID) (err error) {
+	defer observeStore("status", "RecordView", time.Now(), &err)
+	return s.next.RecordView(ctx, statusID, viewerID)
+}
+
+// GetActiveStatusIDs implements interfaces.StatusStore.
+func (s *statusStore) GetActiveStatusIDs(ctx context.Context, ids []uuid.UUID) (result map[uuid.UUID]bool, err error) {
+	defer observeStore("status", "GetActiveStatusIDs", time.Now(), &err)
+	return s.next.GetActiveStatusIDs(ctx, ids)
+}
+
+// GetPurgeableStatuses implements interfaces.StatusStore.
+func (s *statusStore) GetPurgeableStatuses(ctx context.Context, deletedBefore time.Time, purgeExpired bool, limit int) (result []*types.UserStatus, err error) {
+	defer observeStore("status", "GetPurgeableStatuses", time.Now(), &err)
+	return s.next.GetPurgeableStatuses(ctx, deletedBefore, purgeExpired, limit)
+}
+
+// GetPurgeableBlobs implements interfaces.StatusStore.
+func (s *statusStore) GetPurgeableBlobs(ctx context.Context, ids []uuid.UUID) (result map[uuid.UUID][]string, err error) {
+	defer observeStore("status", "GetPurgeableBlobs", time.Now(), &err)
+	return s.next.GetPurgeableBlobs(ctx, ids)
+}
+
+// PurgeStatuses implements interfaces.StatusStore.
+func (s *statusStore) PurgeStatuses(ctx context.Context, ids []uuid.UUID) (err error) {
+	defer observeStore("status", "PurgeStatuses", time.Now(), &err)
+	return s.next.PurgeStatuses(ctx, ids)
+}
+
+// GetViewers implements interfaces.StatusStore.
+func (s *statusStore) GetViewers(ctx context.Context, userID, statusID uuid.UUID) (result []*types.StatusViewer, err error) {
+	defer observeStore("status", "GetViewers", time.Now(), &err)
+	return s.next.GetViewers(ctx, userID, statusID)
+}
@@ -0,0 +1,750 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/coderero/erochat-server/interfaces"
+	"github.com/coderero/erochat-server/types"
+	"github.com/google/uuid"
+)
+
+// userStore traces the user store.
+type userStore struct {
+	next interfaces.UserStore
+}
+
+// NewUserStore traces a UserStore.
+func NewUserStore(next interfaces.UserStore) interfaces.UserStore {
+	return &userStore{next: next}
+}
+
+// GetByID implements interfaces.UserStore.
+func (s *userStore) GetByID(ctx context.Context, id uuid.UUID) (result *types.User, err error) {
+	ctx, span := startStore(ctx, "user", "GetByID")
+	defer endStore(span, &err)
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByEmail implements interfaces.UserStore.
+func (s *userStore) GetByEmail(ctx context.Context, email string) (result *types.User, err error) {
+	ctx, span := startStore(ctx, "user", "GetByEmail")
+	defer endStore(span, &err)
+	return s.next.GetByEmail(ctx, email)
+}
+
+// GetByUsername implements interfaces.UserStore.
+func (s *userStore) GetByUsername(ctx context.Context, username string) (result *types.User, err error) {
+	ctx, span := startStore(ctx, "user", "GetByUsername")
+	defer endStore(span, &err)
+	return s.next.GetByUsername(ctx, username)
+}
+
+// GetBotsByOwner implements interfaces.UserStore.
+func (s *userStore) GetBotsByOwner(ctx context.Context, ownerID uuid.UUID) (result []*types.User, err error) {
+	ctx, span := startStore(ctx, "user", "GetBotsByOwner")
+	defer endStore(span, &err)
+	return s.next.GetBotsByOwner(ctx, ownerID)
+}
+
+// GetSearchDocuments implements interfaces.UserStore.
+func (s *userStore) GetSearchDocuments(ctx context.Context) (result []*types.SearchDocument, err error) {
+	ctx, span := startStore(ctx, "user", "GetSearchDocuments")
+	defer endStore(span, &err)
+	return s.next.GetSearchDocuments(ctx)
+}
+
+// GetSearchDocument implements interfaces.UserStore.
+func (s *userStore) GetSearchDocument(ctx context.Context, id uuid.UUID) (result *types.SearchDocument, err error) {
+	ctx, span := startStore(ctx, "user", "GetSearchDocument")
+	defer endStore(span, &err)
+	return s.next.GetSearchDocument(ctx, id)
+}
+
+// Create implements interfaces.UserStore.
+func (s *userStore) Create(ctx context.Context, user *types.User) (result *types.User, err error) {
+	ctx, span := startStore(ctx, "user", "Create")
+	defer endStore(span, &err)
+	return s.next.Create(ctx, user)
+}
+
+// UpdatePassword implements interfaces.UserStore.
+func (s *userStore) UpdatePassword(ctx context.Context, id uuid.UUID, password string) (err error) {
+	ctx, span := startStore(ctx, "user", "UpdatePassword")
+	defer endStore(span, &err)
+	return s.next.UpdatePassword(ctx, id, password)
+}
+
+// TouchLastSeen implements interfaces.UserStore.
+func (s *userStore) TouchLastSeen(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "user", "TouchLastSeen")
+	defer endStore(span, &err)
+	return s.next.TouchLastSeen(ctx, id)
+}
+
+// Update implements interfaces.UserStore.
+func (s *userStore) Update(ctx context.Context, id uuid.UUID, user *types.User) (result *types.User, err error) {
+	ctx, span := startStore(ctx, "user", "Update")
+	defer endStore(span, &err)
+	return s.next.Update(ctx, id, user)
+}
+
+// Delete implements interfaces.UserStore.
+func (s *userStore) Delete(ctx context.Context, id uuid.UUID) (result uuid.UUID, err error) {
+	ctx, span := startStore(ctx, "user", "Delete")
+	defer endStore(span, &err)
+	return s.next.Delete(ctx, id)
+}
+
+// profileStore traces the profile store.
+type profileStore struct {
+	next interfaces.ProfileStore
+}
+
+// NewProfileStore traces a ProfileStore.
+func NewProfileStore(next interfaces.ProfileStore) interfaces.ProfileStore {
+	return &profileStore{next: next}
+}
+
+// GetByUID implements interfaces.ProfileStore.
+func (s *profileStore) GetByUID(ctx context.Context, id uuid.UUID) (result *types.Profile, err error) {
+	ctx, span := startStore(ctx, "profile", "GetByUID")
+	defer endStore(span, &err)
+	return s.next.GetByUID(ctx, id)
+}
+
+// GetByUserID implements interfaces.ProfileStore.
+func (s *profileStore) GetByUserID(ctx context.Context, id int) (result *types.Profile, err error) {
+	ctx, span := startStore(ctx, "profile", "GetByUserID")
+	defer endStore(span, &err)
+	return s.next.GetByUserID(ctx, id)
+}
+
+// GetByEmail implements interfaces.ProfileStore.
+func (s *profileStore) GetByEmail(ctx context.Context, email string) (result *types.Profile, err error) {
+	ctx, span := startStore(ctx, "profile", "GetByEmail")
+	defer endStore(span, &err)
+	return s.next.GetByEmail(ctx, email)
+}
+
+// Create implements interfaces.ProfileStore.
+func (s *profileStore) Create(ctx context.Context, profile *types.Profile) (result *types.Profile, err error) {
+	ctx, span := startStore(ctx, "profile", "Create")
+	defer endStore(span, &err)
+	return s.next.Create(ctx, profile)
+}
+
+// CreateFriendship implements interfaces.ProfileStore.
+func (s *profileStore) CreateFriendship(ctx context.Context, userID string, friendID string) (err error) {
+	ctx, span := startStore(ctx, "profile", "CreateFriendship")
+	defer endStore(span, &err)
+	return s.next.CreateFriendship(ctx, userID, friendID)
+}
+
+// Update implements interfaces.ProfileStore.
+func (s *profileStore) Update(ctx context.Context, profile *types.Profile) (result *types.Profile, err error) {
+	ctx, span := startStore(ctx, "profile", "Update")
+	defer endStore(span, &err)
+	return s.next.Update(ctx, profile)
+}
+
+// Delete implements interfaces.ProfileStore.
+func (s *profileStore) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := startStore(ctx, "profile", "Delete")
+	defer endStore(span, &err)
+	return s.next.Delete(ctx, id)
+}
+
+// Reactivate implements interfaces.ProfileStore.
+func (s *profileStore) Reactivate(ctx context.Context, id int) (err error) {
+	ctx, span := startStore(ctx, "profile", "Reactivate")
+	defer endStore(span, &err)
+	return s.next.Reactivate(ctx, id)
+}
+
+// friendStore traces the friend store.
+type friendStore struct {
+	next interfaces.FriendStore
+}
+
+// NewFriendStore traces a FriendStore.
+func NewFriendStore(next interfaces.FriendStore) interfaces.FriendStore {
+	return &friendStore{next: next}
+}
+
+// GetFriends implements interfaces.FriendStore.
+func (s *friendStore) GetFriends(ctx context.Context, userID uuid.UUID) (result []*types.Friend, err error) {
+	ctx, span := startStore(ctx, "friend", "GetFriends")
+	defer endStore(span, &err)
+	return s.next.GetFriends(ctx, userID)
+}
+
+// GetFriend implements interfaces.FriendStore.
+func (s *friendStore) GetFriend(ctx context.Context, userID uuid.UUID, friendID uuid.UUID) (result *types.Friend, err error) {
+	ctx, span := startStore(ctx, "friend", "GetFriend")
+	defer endStore(span, &err)
+	return s.next.GetFriend(ctx, userID, friendID)
+}
+
+// DeleteFriend implements interfaces.FriendStore.
+func (s *friendStore) DeleteFriend(ctx context.Context, userID uuid.UUID, fID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend", "DeleteFriend")
+	defer endStore(span, &err)
+	return s.next.DeleteFriend(ctx, userID, fID)
+}
+
+// GetFriendRequests implements interfaces.FriendStore.
+func (s *friendStore) GetFriendRequests(ctx context.Context, userID uuid.UUID) (result []*types.Friend, err error) {
+	ctx, span := startStore(ctx, "friend", "GetFriendRequests")
+	defer endStore(span, &err)
+	return s.next.GetFriendRequests(ctx, userID)
+}
+
+// GetFriendRequestsByDirection implements interfaces.FriendStore.
+func (s *friendStore) GetFriendRequestsByDirection(ctx context.Context, userID uuid.UUID, direction types.FriendRequestDirection) (result []*types.Friend, err error) {
+	ctx, span := startStore(ctx, "friend", "GetFriendRequestsByDirection")
+	defer endStore(span, &err)
+	return s.next.GetFriendRequestsByDirection(ctx, userID, direction)
+}
+
+// GetFriendRequest implements interfaces.FriendStore.
+func (s *friendStore) GetFriendRequest(ctx context.Context, userID uuid.UUID, uid uuid.UUID) (result *types.Friend, err error) {
+	ctx, span := startStore(ctx, "friend", "GetFriendRequest")
+	defer endStore(span, &err)
+	return s.next.GetFriendRequest(ctx, userID, uid)
+}
+
+// AcceptFriendRequest implements interfaces.FriendStore.
+func (s *friendStore) AcceptFriendRequest(ctx context.Context, userUID uuid.UUID, uid uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend", "AcceptFriendRequest")
+	defer endStore(span, &err)
+	return s.next.AcceptFriendRequest(ctx, userUID, uid)
+}
+
+// CancelFriendRequest implements interfaces.FriendStore.
+func (s *friendStore) CancelFriendRequest(ctx context.Context, userID uuid.UUID, reqID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend", "CancelFriendRequest")
+	defer endStore(span, &err)
+	return s.next.CancelFriendRequest(ctx, userID, reqID)
+}
+
+// DeclineFriendRequest implements interfaces.FriendStore.
+func (s *friendStore) DeclineFriendRequest(ctx context.Context, userID uuid.UUID, reqID uuid.UUID, cooldown time.Duration) (err error) {
+	ctx, span := startStore(ctx, "friend", "DeclineFriendRequest")
+	defer endStore(span, &err)
+	return s.next.DeclineFriendRequest(ctx, userID, reqID, cooldown)
+}
+
+// IsFriendRequestSuppressed implements interfaces.FriendStore.
+func (s *friendStore) IsFriendRequestSuppressed(ctx context.Context, senderID uuid.UUID, recipientID uuid.UUID) (result bool, err error) {
+	ctx, span := startStore(ctx, "friend", "IsFriendRequestSuppressed")
+	defer endStore(span, &err)
+	return s.next.IsFriendRequestSuppressed(ctx, senderID, recipientID)
+}
+
+// DeleteFriendRequest implements interfaces.FriendStore.
+func (s *friendStore) DeleteFriendRequest(ctx context.Context, userID uuid.UUID, reqID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend", "DeleteFriendRequest")
+	defer endStore(span, &err)
+	return s.next.DeleteFriendRequest(ctx, userID, reqID)
+}
+
+// GetFriendsStatus implements interfaces.FriendStore.
+func (s *friendStore) GetFriendsStatus(ctx context.Context, userID uuid.UUID, opts types.FriendsStatusOptions) (result []*types.FriendStatus, err error) {
+	ctx, span := startStore(ctx, "friend", "GetFriendsStatus")
+	defer endStore(span, &err)
+	return s.next.GetFriendsStatus(ctx, userID, opts)
+}
+
+// GetFriendStatus implements interfaces.FriendStore.
+func (s *friendStore) GetFriendStatus(ctx context.Context, userID uuid.UUID, friendID uuid.UUID) (result *types.FriendStatus, err error) {
+	ctx, span := startStore(ctx, "friend", "GetFriendStatus")
+	defer endStore(span, &err)
+	return s.next.GetFriendStatus(ctx, userID, friendID)
+}
+
+// BlockUser implements interfaces.FriendStore.
+func (s *friendStore) BlockUser(ctx context.Context, userID uuid.UUID, blockedID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend", "BlockUser")
+	defer endStore(span, &err)
+	return s.next.BlockUser(ctx, userID, blockedID)
+}
+
+// UnblockUser implements interfaces.FriendStore.
+func (s *friendStore) UnblockUser(ctx context.Context, userID uuid.UUID, blockedID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend", "UnblockUser")
+	defer endStore(span, &err)
+	return s.next.UnblockUser(ctx, userID, blockedID)
+}
+
+// GetBlockedUsers implements interfaces.FriendStore.
+func (s *friendStore) GetBlockedUsers(ctx context.Context, userID uuid.UUID) (result []*types.BlockedUser, err error) {
+	ctx, span := startStore(ctx, "friend", "GetBlockedUsers")
+	defer endStore(span, &err)
+	return s.next.GetBlockedUsers(ctx, userID)
+}
+
+// MuteStatus implements interfaces.FriendStore.
+func (s *friendStore) MuteStatus(ctx context.Context, userID uuid.UUID, friendID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend", "MuteStatus")
+	defer endStore(span, &err)
+	return s.next.MuteStatus(ctx, userID, friendID)
+}
+
+// UnmuteStatus implements interfaces.FriendStore.
+func (s *friendStore) UnmuteStatus(ctx context.Context, userID uuid.UUID, friendID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend", "UnmuteStatus")
+	defer endStore(span, &err)
+	return s.next.UnmuteStatus(ctx, userID, friendID)
+}
+
+// GetMutedFriends implements interfaces.FriendStore.
+func (s *friendStore) GetMutedFriends(ctx context.Context, userID uuid.UUID) (result []*types.MutedFriend, err error) {
+	ctx, span := startStore(ctx, "friend", "GetMutedFriends")
+	defer endStore(span, &err)
+	return s.next.GetMutedFriends(ctx, userID)
+}
+
+// IsBlocked implements interfaces.FriendStore.
+func (s *friendStore) IsBlocked(ctx context.Context, userID uuid.UUID, otherID uuid.UUID) (result bool, err error) {
+	ctx, span := startStore(ctx, "friend", "IsBlocked")
+	defer endStore(span, &err)
+	return s.next.IsBlocked(ctx, userID, otherID)
+}
+
+// GetFriendSuggestions implements interfaces.FriendStore.
+func (s *friendStore) GetFriendSuggestions(ctx context.Context, userID uuid.UUID, limit int, offset int) (result []*types.FriendSuggestion, err error) {
+	ctx, span := startStore(ctx, "friend", "GetFriendSuggestions")
+	defer endStore(span, &err)
+	return s.next.GetFriendSuggestions(ctx, userID, limit, offset)
+}
+
+// RefreshFriendSuggestions implements interfaces.FriendStore.
+func (s *friendStore) RefreshFriendSuggestions(ctx context.Context) (err error) {
+	ctx, span := startStore(ctx, "friend", "RefreshFriendSuggestions")
+	defer endStore(span, &err)
+	return s.next.RefreshFriendSuggestions(ctx)
+}
+
+// GetFriendsOfFriends implements interfaces.FriendStore.
+func (s *friendStore) GetFriendsOfFriends(ctx context.Context, userID uuid.UUID) (result []uuid.UUID, err error) {
+	ctx, span := startStore(ctx, "friend", "GetFriendsOfFriends")
+	defer endStore(span, &err)
+	return s.next.GetFriendsOfFriends(ctx, userID)
+}
+
+// GetBlockRelations implements interfaces.FriendStore.
+func (s *friendStore) GetBlockRelations(ctx context.Context, userID uuid.UUID) (result []uuid.UUID, err error) {
+	ctx, span := startStore(ctx, "friend", "GetBlockRelations")
+	defer endStore(span, &err)
+	return s.next.GetBlockRelations(ctx, userID)
+}
+
+// AreFriends implements interfaces.FriendStore.
+func (s *friendStore) AreFriends(ctx context.Context, userID uuid.UUID, otherID uuid.UUID) (result bool, err error) {
+	ctx, span := startStore(ctx, "friend", "AreFriends")
+	defer endStore(span, &err)
+	return s.next.AreFriends(ctx, userID, otherID)
+}
+
+// HaveMutualFriend implements interfaces.FriendStore.
+func (s *friendStore) HaveMutualFriend(ctx context.Context, userID uuid.UUID, otherID uuid.UUID) (result bool, err error) {
+	ctx, span := startStore(ctx, "friend", "HaveMutualFriend")
+	defer endStore(span, &err)
+	return s.next.HaveMutualFriend(ctx, userID, otherID)
+}
+
+// statusStore traces the status store.
+type statusStore struct {
+	next interfaces.StatusStore
+}
+
+// NewStatusStore traces a StatusStore.
+func NewStatusStore(next interfaces.StatusStore) interfaces.StatusStore {
+	return &statusStore{next: next}
+}
+
+// GetStatus implements interfaces.StatusStore.
+func (s *statusStore) GetStatus(ctx context.Context, uid uuid.UUID) (result []*types.UserStatus, err error) {
+	ctx, span := startStore(ctx, "status", "GetStatus")
+	defer endStore(span, &err)
+	return s.next.GetStatus(ctx, uid)
+}
+
+// GetStatusByUID implements interfaces.StatusStore.
+func (s *statusStore) GetStatusByUID(ctx context.Context, userUID uuid.UUID, uid uuid.UUID) (result *types.UserStatus, err error) {
+	ctx, span := startStore(ctx, "status", "GetStatusByUID")
+	defer endStore(span, &err)
+	return s.next.GetStatusByUID(ctx, userUID, uid)
+}
+
+// CreateStatus implements interfaces.StatusStore.
+func (s *statusStore) CreateStatus(ctx context.Context, status *types.UserStatus) (result *types.UserStatus, err error) {
+	ctx, span := startStore(ctx, "status", "CreateStatus")
+	defer endStore(span, &err)
+	return s.next.CreateStatus(ctx, status)
+}
+
+// DeleteStatus implements interfaces.StatusStore.
+func (s *statusStore) DeleteStatus(ctx context.Context, userID uuid.UUID, uid uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "status", "DeleteStatus")
+	defer endStore(span, &err)
+	return s.next.DeleteStatus(ctx, userID, uid)
+}
+
+// GetArchive implements interfaces.StatusStore.
+func (s *statusStore) GetArchive(ctx context.Context, userID uuid.UUID, before time.Time, limit int) (result []*types.UserStatus, err error) {
+	ctx, span := startStore(ctx, "status", "GetArchive")
+	defer endStore(span, &err)
+	return s.next.GetArchive(ctx, userID, before, limit)
+}
+
+// RecordView implements interfaces.StatusStore.
+func (s *statusStore) RecordView(ctx context.Context, statusID uuid.UUID, viewerID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "status", "RecordView")
+	defer endStore(span, &err)
+	return s.next.RecordView(ctx, statusID, viewerID)
+}
+
+// GetActiveStatusIDs implements interfaces.StatusStore.
+func (s *statusStore) GetActiveStatusIDs(ctx context.Context, ids []uuid.UUID) (result map[uuid.UUID]bool, err error) {
+	ctx, span := startStore(ctx, "status", "GetActiveStatusIDs")
+	defer endStore(span, &err)
+	return s.next.GetActiveStatusIDs(ctx, ids)
+}
+
+// GetPurgeableStatuses implements interfaces.StatusStore.
+func (s *statusStore) GetPurgeableStatuses(ctx context.Context, deletedBefore time.Time, purgeExpired bool, limit int) (result []*types.UserStatus, err error) {
+	ctx, span := startStore(ctx, "status", "GetPurgeableStatuses")
+	defer endStore(span, &err)
+	return s.next.GetPurgeableStatuses(ctx, deletedBefore, purgeExpired, limit)
+}
+
+// GetPurgeableBlobs implements interfaces.StatusStore.
+func (s *statusStore) GetPurgeableBlobs(ctx context.Context, ids []uuid.UUID) (result map[uuid.UUID][]string, err error) {
+	ctx, span := startStore(ctx, "status", "GetPurgeableBlobs")
+	defer endStore(span, &err)
+	return s.next.GetPurgeableBlobs(ctx, ids)
+}
+
+// PurgeStatuses implements interfaces.StatusStore.
+func (s *statusStore) PurgeStatuses(ctx context.Context, ids []uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "status", "PurgeStatuses")
+	defer endStore(span, &err)
+	return s.next.PurgeStatuses(ctx, ids)
+}
+
+// GetViewers implements interfaces.StatusStore.
+func (s *statusStore) GetViewers(ctx context.Context, userID uuid.UUID, statusID uuid.UUID) (result []*types.StatusViewer, err error) {
+	ctx, span := startStore(ctx, "status", "GetViewers")
+	defer endStore(span, &err)
+	return s.next.GetViewers(ctx, userID, statusID)
+}
+
+// apiTokenStore traces the api token store.
+type apiTokenStore struct {
+	next interfaces.APITokenStore
+}
+
+// NewAPITokenStore traces an APITokenStore.
+func NewAPITokenStore(next interfaces.APITokenStore) interfaces.APITokenStore {
+	return &apiTokenStore{next: next}
+}
+
+// Create implements interfaces.APITokenStore.
+func (s *apiTokenStore) Create(ctx context.Context, token *types.APIToken) (result *types.APIToken, err error) {
+	ctx, span := startStore(ctx, "api_token", "Create")
+	defer endStore(span, &err)
+	return s.next.Create(ctx, token)
+}
+
+// GetByHash implements interfaces.APITokenStore.
+func (s *apiTokenStore) GetByHash(ctx context.Context, hash string) (result *types.APIToken, err error) {
+	ctx, span := startStore(ctx, "api_token", "GetByHash")
+	defer endStore(span, &err)
+	return s.next.GetByHash(ctx, hash)
+}
+
+// GetByUser implements interfaces.APITokenStore.
+func (s *apiTokenStore) GetByUser(ctx context.Context, userID uuid.UUID) (result []*types.APIToken, err error) {
+	ctx, span := startStore(ctx, "api_token", "GetByUser")
+	defer endStore(span, &err)
+	return s.next.GetByUser(ctx, userID)
+}
+
+// Revoke implements interfaces.APITokenStore.
+func (s *apiTokenStore) Revoke(ctx context.Context, userID uuid.UUID, uid uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "api_token", "Revoke")
+	defer endStore(span, &err)
+	return s.next.Revoke(ctx, userID, uid)
+}
+
+// RevokeAll implements interfaces.APITokenStore.
+func (s *apiTokenStore) RevokeAll(ctx context.Context, userID uuid.UUID) (result int64, err error) {
+	ctx, span := startStore(ctx, "api_token", "RevokeAll")
+	defer endStore(span, &err)
+	return s.next.RevokeAll(ctx, userID)
+}
+
+// Touch implements interfaces.APITokenStore.
+func (s *apiTokenStore) Touch(ctx context.Context, uid uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "api_token", "Touch")
+	defer endStore(span, &err)
+	return s.next.Touch(ctx, uid)
+}
+
+// auditStore traces the audit store.
+type auditStore struct {
+	next interfaces.AuditStore
+}
+
+// NewAuditStore traces an AuditStore.
+func NewAuditStore(next interfaces.AuditStore) interfaces.AuditStore {
+	return &auditStore{next: next}
+}
+
+// Record implements interfaces.AuditStore.
+func (s *auditStore) Record(ctx context.Context, event *types.AuditEvent) (err error) {
+	ctx, span := startStore(ctx, "audit", "Record")
+	defer endStore(span, &err)
+	return s.next.Record(ctx, event)
+}
+
+// GetByUser implements interfaces.AuditStore.
+func (s *auditStore) GetByUser(ctx context.Context, userID uuid.UUID, before time.Time, limit int) (result []*types.AuditEvent, err error) {
+	ctx, span := startStore(ctx, "audit", "GetByUser")
+	defer endStore(span, &err)
+	return s.next.GetByUser(ctx, userID, before, limit)
+}
+
+// Query implements interfaces.AuditStore.
+func (s *auditStore) Query(ctx context.Context, filter types.AuditFilter) (result []*types.AuditEvent, err error) {
+	ctx, span := startStore(ctx, "audit", "Query")
+	defer endStore(span, &err)
+	return s.next.Query(ctx, filter)
+}
+
+// friendListStore traces the friend list store.
+type friendListStore struct {
+	next interfaces.FriendListStore
+}
+
+// NewFriendListStore traces a FriendListStore.
+func NewFriendListStore(next interfaces.FriendListStore) interfaces.FriendListStore {
+	return &friendListStore{next: next}
+}
+
+// Create implements interfaces.FriendListStore.
+func (s *friendListStore) Create(ctx context.Context, list *types.FriendList) (result *types.FriendList, err error) {
+	ctx, span := startStore(ctx, "friend_list", "Create")
+	defer endStore(span, &err)
+	return s.next.Create(ctx, list)
+}
+
+// GetByOwner implements interfaces.FriendListStore.
+func (s *friendListStore) GetByOwner(ctx context.Context, ownerID uuid.UUID) (result []*types.FriendList, err error) {
+	ctx, span := startStore(ctx, "friend_list", "GetByOwner")
+	defer endStore(span, &err)
+	return s.next.GetByOwner(ctx, ownerID)
+}
+
+// Get implements interfaces.FriendListStore.
+func (s *friendListStore) Get(ctx context.Context, ownerID uuid.UUID, listID uuid.UUID) (result *types.FriendList, err error) {
+	ctx, span := startStore(ctx, "friend_list", "Get")
+	defer endStore(span, &err)
+	return s.next.Get(ctx, ownerID, listID)
+}
+
+// Rename implements interfaces.FriendListStore.
+func (s *friendListStore) Rename(ctx context.Context, ownerID uuid.UUID, listID uuid.UUID, name string) (err error) {
+	ctx, span := startStore(ctx, "friend_list", "Rename")
+	defer endStore(span, &err)
+	return s.next.Rename(ctx, ownerID, listID, name)
+}
+
+// Delete implements interfaces.FriendListStore.
+func (s *friendListStore) Delete(ctx context.Context, ownerID uuid.UUID, listID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend_list", "Delete")
+	defer endStore(span, &err)
+	return s.next.Delete(ctx, ownerID, listID)
+}
+
+// GetMembers implements interfaces.FriendListStore.
+func (s *friendListStore) GetMembers(ctx context.Context, ownerID uuid.UUID, listID uuid.UUID) (result []*types.FriendListMember, err error) {
+	ctx, span := startStore(ctx, "friend_list", "GetMembers")
+	defer endStore(span, &err)
+	return s.next.GetMembers(ctx, ownerID, listID)
+}
+
+// AddMember implements interfaces.FriendListStore.
+func (s *friendListStore) AddMember(ctx context.Context, ownerID uuid.UUID, listID uuid.UUID, memberID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend_list", "AddMember")
+	defer endStore(span, &err)
+	return s.next.AddMember(ctx, ownerID, listID, memberID)
+}
+
+// RemoveMember implements interfaces.FriendListStore.
+func (s *friendListStore) RemoveMember(ctx context.Context, ownerID uuid.UUID, listID uuid.UUID, memberID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend_list", "RemoveMember")
+	defer endStore(span, &err)
+	return s.next.RemoveMember(ctx, ownerID, listID, memberID)
+}
+
+// GetHiddenFrom implements interfaces.FriendListStore.
+func (s *friendListStore) GetHiddenFrom(ctx context.Context, ownerID uuid.UUID) (result []*types.FriendListMember, err error) {
+	ctx, span := startStore(ctx, "friend_list", "GetHiddenFrom")
+	defer endStore(span, &err)
+	return s.next.GetHiddenFrom(ctx, ownerID)
+}
+
+// HideFrom implements interfaces.FriendListStore.
+func (s *friendListStore) HideFrom(ctx context.Context, ownerID uuid.UUID, userID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend_list", "HideFrom")
+	defer endStore(span, &err)
+	return s.next.HideFrom(ctx, ownerID, userID)
+}
+
+// UnhideFrom implements interfaces.FriendListStore.
+func (s *friendListStore) UnhideFrom(ctx context.Context, ownerID uuid.UUID, userID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "friend_list", "UnhideFrom")
+	defer endStore(span, &err)
+	return s.next.UnhideFrom(ctx, ownerID, userID)
+}
+
+// highlightStore traces the highlight store.
+type highlightStore struct {
+	next interfaces.HighlightStore
+}
+
+// NewHighlightStore traces a HighlightStore.
+func NewHighlightStore(next interfaces.HighlightStore) interfaces.HighlightStore {
+	return &highlightStore{next: next}
+}
+
+// Create implements interfaces.HighlightStore.
+func (s *highlightStore) Create(ctx context.Context, highlight *types.Highlight) (result *types.Highlight, err error) {
+	ctx, span := startStore(ctx, "highlight", "Create")
+	defer endStore(span, &err)
+	return s.next.Create(ctx, highlight)
+}
+
+// GetByOwner implements interfaces.HighlightStore.
+func (s *highlightStore) GetByOwner(ctx context.Context, ownerID uuid.UUID, viewerID uuid.UUID) (result []*types.Highlight, err error) {
+	ctx, span := startStore(ctx, "highlight", "GetByOwner")
+	defer endStore(span, &err)
+	return s.next.GetByOwner(ctx, ownerID, viewerID)
+}
+
+// Get implements interfaces.HighlightStore.
+func (s *highlightStore) Get(ctx context.Context, ownerID uuid.UUID, highlightID uuid.UUID) (result *types.Highlight, err error) {
+	ctx, span := startStore(ctx, "highlight", "Get")
+	defer endStore(span, &err)
+	return s.next.Get(ctx, ownerID, highlightID)
+}
+
+// Rename implements interfaces.HighlightStore.
+func (s *highlightStore) Rename(ctx context.Context, ownerID uuid.UUID, highlightID uuid.UUID, name string) (err error) {
+	ctx, span := startStore(ctx, "highlight", "Rename")
+	defer endStore(span, &err)
+	return s.next.Rename(ctx, ownerID, highlightID, name)
+}
+
+// Delete implements interfaces.HighlightStore.
+func (s *highlightStore) Delete(ctx context.Context, ownerID uuid.UUID, highlightID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "highlight", "Delete")
+	defer endStore(span, &err)
+	return s.next.Delete(ctx, ownerID, highlightID)
+}
+
+// AddStatus implements interfaces.HighlightStore.
+func (s *highlightStore) AddStatus(ctx context.Context, ownerID uuid.UUID, highlightID uuid.UUID, statusID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "highlight", "AddStatus")
+	defer endStore(span, &err)
+	return s.next.AddStatus(ctx, ownerID, highlightID, statusID)
+}
+
+// RemoveStatus implements interfaces.HighlightStore.
+func (s *highlightStore) RemoveStatus(ctx context.Context, ownerID uuid.UUID, highlightID uuid.UUID, statusID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "highlight", "RemoveStatus")
+	defer endStore(span, &err)
+	return s.next.RemoveStatus(ctx, ownerID, highlightID, statusID)
+}
+
+// privacyStore traces the privacy store.
+type privacyStore struct {
+	next interfaces.PrivacyStore
+}
+
+// NewPrivacyStore traces a PrivacyStore.
+func NewPrivacyStore(next interfaces.PrivacyStore) interfaces.PrivacyStore {
+	return &privacyStore{next: next}
+}
+
+// Get implements interfaces.PrivacyStore.
+func (s *privacyStore) Get(ctx context.Context, userID uuid.UUID) (result *types.PrivacySettings, err error) {
+	ctx, span := startStore(ctx, "privacy", "Get")
+	defer endStore(span, &err)
+	return s.next.Get(ctx, userID)
+}
+
+// GetMany implements interfaces.PrivacyStore.
+func (s *privacyStore) GetMany(ctx context.Context, userIDs []uuid.UUID) (result map[uuid.UUID]*types.PrivacySettings, err error) {
+	ctx, span := startStore(ctx, "privacy", "GetMany")
+	defer endStore(span, &err)
+	return s.next.GetMany(ctx, userIDs)
+}
+
+// Update implements interfaces.PrivacyStore.
+func (s *privacyStore) Update(ctx context.Context, settings *types.PrivacySettings) (result *types.PrivacySettings, err error) {
+	ctx, span := startStore(ctx, "privacy", "Update")
+	defer endStore(span, &err)
+	return s.next.Update(ctx, settings)
+}
+
+// messageStore traces the message store.
+type messageStore struct {
+	next interfaces.MessageStore
+}
+
+// NewMessageStore traces a MessageStore.
+func NewMessageStore(next interfaces.MessageStore) interfaces.MessageStore {
+	return &messageStore{next: next}
+}
+
+// Send implements interfaces.MessageStore.
+func (s *messageStore) Send(ctx context.Context, message *types.Message) (result *types.Message, err error) {
+	ctx, span := startStore(ctx, "message", "Send")
+	defer endStore(span, &err)
+	return s.next.Send(ctx, message)
+}
+
+// GetConversation implements interfaces.MessageStore.
+func (s *messageStore) GetConversation(ctx context.Context, conversationID string, before time.Time, limit int) (result []*types.Message, err error) {
+	ctx, span := startStore(ctx, "message", "GetConversation")
+	defer endStore(span, &err)
+	return s.next.GetConversation(ctx, conversationID, before, limit)
+}
+
+// searchIndex traces the search index.
+type searchIndex struct {
+	next interfaces.SearchIndex
+}
+
+// NewSearchIndex traces a SearchIndex.
+func NewSearchIndex(next interfaces.SearchIndex) interfaces.SearchIndex {
+	return &searchIndex{next: next}
+}
+
+// Search implements interfaces.SearchIndex.
+func (s *searchIndex) Search(ctx context.Context, query types.SearchQuery) (result *types.SearchPage, err error) {
+	ctx, span := startStore(ctx, "search", "Search")
+	defer endStore(span, &err)
+	return s.next.Search(ctx, query)
+}
+
+// Reindex implements interfaces.SearchIndex.
+func (s *searchIndex) Reindex(ctx context.Context, userID uuid.UUID) (err error) {
+	ctx, span := startStore(ctx, "search", "Reindex")
+	defer endStore(span, &err)
+	return s.next.Reindex(ctx, userID)
+}
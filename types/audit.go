@@ -0,0 +1,87 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEventType is the type of a security audit event.
+type AuditEventType string
+
+const (
+	// AuditLoginSuccess is recorded when a user logs in.
+	AuditLoginSuccess AuditEventType = "login.success"
+
+	// AuditLoginFailure is recorded when a login attempt fails.
+	AuditLoginFailure AuditEventType = "login.failure"
+
+	// AuditRegister is recorded when a user registers.
+	AuditRegister AuditEventType = "register"
+
+	// AuditLogout is recorded when a user logs out.
+	AuditLogout AuditEventType = "logout"
+
+	// AuditTokenRefresh is recorded when an access token is refreshed.
+	AuditTokenRefresh AuditEventType = "token.refresh"
+
+	// AuditProfileDelete is recorded when a user deletes their profile.
+	AuditProfileDelete AuditEventType = "profile.delete"
+
+	// AuditProfileReactivate is recorded when a user reactivates their profile.
+	AuditProfileReactivate AuditEventType = "profile.reactivate"
+
+	// AuditPasswordChange is recorded when a user changes their password.
+	AuditPasswordChange AuditEventType = "password.change"
+
+	// AuditAdminAction is recorded when an admin uses an admin route.
+	AuditAdminAction AuditEventType = "admin.action"
+)
+
+// RoleUser is the role of regular users.
+const RoleUser = "user"
+
+// RoleAdmin is the role of administrators.
+const RoleAdmin = "admin"
+
+// AuditEvent is an entry of the security audit log.
+type AuditEvent struct {
+	// UID is the unique identifier of the event.
+	UID uuid.UUID `json:"uid"`
+
+	// UserUID is the user the event is about, nil when it's unknown
+	// such as a login attempt for a user that doesn't exist.
+	UserUID uuid.UUID `json:"user_uid"`
+
+	// ActorUID is the user who performed the action when it isn't the user
+	// the event is about, such as an admin.
+	ActorUID uuid.NullUUID `json:"actor_uid"`
+
+	Type      AuditEventType    `json:"type"`
+	IP        string            `json:"ip"`
+	UserAgent string            `json:"user_agent"`
+	RequestID string            `json:"request_id"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// AuditFilter filters the audit events returned by an admin query.
+type AuditFilter struct {
+	// UserUID restricts the events to a user.
+	UserUID uuid.UUID
+
+	// Types restricts the events to the given types.
+	Types []AuditEventType
+
+	// IP restricts the events to an ip address.
+	IP string
+
+	// Since restricts the events to the ones created at or after it.
+	Since time.Time
+
+	// Until restricts the events to the ones created before it.
+	Until time.Time
+
+	// Limit is the maximum number of events to return.
+	Limit int
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FriendList is a named list of friends, such as "close friends".
+type FriendList struct {
+	ID          int       `json:"-"`
+	UID         uuid.UUID `json:"uid"`
+	OwnerUID    uuid.UUID `json:"-"`
+	Name        string    `json:"name"`
+	MemberCount int       `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// FriendListMember is a friend in a friend list, or a friend statuses are
+// hidden from.
+type FriendListMember struct {
+	UID       uuid.UUID `json:"uid"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Avatar    string    `json:"avatar"`
+	AddedAt   time.Time `json:"added_at"`
+}
@@ -16,17 +16,81 @@ type Friend struct {
 	Bio        string     `json:"bio"`
 	Avatar     string     `json:"avatar"`
 	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+
+	// Direction is set on pending requests, it tells whether the user sent
+	// or received the request.
+	Direction FriendRequestDirection `json:"direction,omitempty"`
 }
 
+// FriendRequestDirection is the direction of a pending friend request.
+type FriendRequestDirection string
+
+const (
+	// FriendRequestIncoming is a request received by the user.
+	FriendRequestIncoming FriendRequestDirection = "incoming"
+
+	// FriendRequestOutgoing is a request sent by the user.
+	FriendRequestOutgoing FriendRequestDirection = "outgoing"
+)
+
 type FriendStatus struct {
-	RID               uuid.UUID `json:"rid"`
-	UID               uuid.UUID `json:"uid"`
-	StatusID          uuid.UUID `json:"status_id"`
-	Username          string    `json:"username"`
-	FirstName         string    `json:"first_name"`
-	LastName          string    `json:"last_name"`
-	Avatar            string    `json:"avatar"`
-	Title             string    `json:"title"`
-	ResourceURI       string    `json:"resource_uri"`
-	ResourceThumbnail string    `json:"resource_thumbnail"`
+	RID               uuid.UUID     `json:"rid"`
+	UID               uuid.UUID     `json:"uid"`
+	StatusID          uuid.UUID     `json:"status_id"`
+	Username          string        `json:"username"`
+	FirstName         string        `json:"first_name"`
+	LastName          string        `json:"last_name"`
+	Avatar            string        `json:"avatar"`
+	Title             string        `json:"title"`
+	ResourceURI       string        `json:"resource_uri"`
+	ResourceThumbnail string        `json:"resource_thumbnail"`
+	Items             []*StatusItem `json:"items"`
+	Seen              bool          `json:"seen"`
+	Muted             bool          `json:"muted"`
+}
+
+// FriendsStatusOptions are the options of the friends status feed.
+type FriendsStatusOptions struct {
+	// IncludeMuted includes the statuses of muted friends, flagged as muted,
+	// instead of omitting them.
+	IncludeMuted bool
+}
+
+// MutedFriend is a friend whose statuses are muted by the user.
+type MutedFriend struct {
+	UID       uuid.UUID `json:"uid"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Avatar    string    `json:"avatar"`
+	MutedAt   time.Time `json:"muted_at"`
+}
+
+// BlockedUser is a user blocked by the authenticated user.
+type BlockedUser struct {
+	UID       uuid.UUID `json:"uid"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Avatar    string    `json:"avatar"`
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+// FriendSuggestion is a user the authenticated user may know.
+type FriendSuggestion struct {
+	UID           uuid.UUID       `json:"uid"`
+	Username      string          `json:"username"`
+	FirstName     string          `json:"first_name"`
+	LastName      string          `json:"last_name"`
+	Avatar        string          `json:"avatar"`
+	MutualCount   int             `json:"mutual_count"`
+	MutualFriends []*MutualFriend `json:"mutual_friends"`
+}
+
+// MutualFriend is a preview of a friend shared with a suggested user.
+type MutualFriend struct {
+	UID       uuid.UUID `json:"uid"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"first_name"`
+	Avatar    string    `json:"avatar"`
 }
@@ -0,0 +1,40 @@
+package types
+
+import "time"
+
+// HealthCheck is the result of a dependency check.
+type HealthCheck struct {
+	Healthy  bool   `json:"healthy"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Readiness reports whether an instance can serve requests.
+type Readiness struct {
+	Ready        bool                   `json:"ready"`
+	ShuttingDown bool                   `json:"shutting_down"`
+	Checks       map[string]HealthCheck `json:"checks"`
+}
+
+// PoolStats is the statistics of a database connection pool.
+type PoolStats struct {
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDuration       string `json:"wait_duration"`
+	MaxIdleClosed      int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64  `json:"max_lifetime_closed"`
+}
+
+// Diagnostics describes a running instance, for admins.
+type Diagnostics struct {
+	Version    string    `json:"version"`
+	GoVersion  string    `json:"go_version"`
+	StartedAt  time.Time `json:"started_at"`
+	Uptime     string    `json:"uptime"`
+	Goroutines int       `json:"goroutines"`
+	MySQL      PoolStats `json:"mysql"`
+}
@@ -0,0 +1,18 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Highlight is a named collection of statuses pinned to the profile, the
+// statuses stay in it after they expire.
+type Highlight struct {
+	ID        int           `json:"-"`
+	UID       uuid.UUID     `json:"uid"`
+	OwnerUID  uuid.UUID     `json:"-"`
+	Name      string        `json:"name"`
+	Statuses  []*UserStatus `json:"statuses"`
+	CreatedAt time.Time     `json:"created_at"`
+}
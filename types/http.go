@@ -38,6 +38,15 @@ const (
 
 	// ErrorInvalidRequest is returned when the request is invalid.
 	ErrorInvalidRequest
+
+	// ErrorTypeForbidden is returned when the request is not allowed.
+	ErrorTypeForbidden
+
+	// ErrorTypeTimeout is returned when the request ran out of time.
+	ErrorTypeTimeout
+
+	// ErrorTypeCanceled is returned when the client went away before the response.
+	ErrorTypeCanceled
 )
 
 func (t ErrorType) String() string {
@@ -54,6 +63,9 @@ func (t ErrorType) String() string {
 		"bad_request",
 		"account_deleted",
 		"invalid_request",
+		"forbidden",
+		"timeout",
+		"canceled",
 	}[t]
 }
 
@@ -86,6 +98,7 @@ type ApiResponse struct {
 	Type    string      `json:"type,omitempty"`
 	Errors  []Error     `json:"errors,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
+	TraceID string      `json:"trace_id,omitempty"`
 }
 
 type Error struct {
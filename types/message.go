@@ -0,0 +1,38 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message is a private message between two users.
+type Message struct {
+	UID            uuid.UUID  `json:"uid"`
+	ConversationID string     `json:"conversation_id"`
+	SenderUID      uuid.UUID  `json:"sender_uid"`
+	RecipientUID   uuid.UUID  `json:"recipient_uid"`
+	Body           string     `json:"body"`
+	StatusRef      *StatusRef `json:"status_ref,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// StatusRef is the status a message replies to. The title and thumbnail are
+// a snapshot taken when the reply was sent, they're blanked and Expired is
+// set once the status expired or was deleted.
+type StatusRef struct {
+	StatusUID         uuid.UUID `json:"status_uid"`
+	OwnerUID          uuid.UUID `json:"owner_uid"`
+	Title             string    `json:"title,omitempty"`
+	ResourceThumbnail string    `json:"resource_thumbnail,omitempty"`
+	Expired           bool      `json:"expired"`
+}
+
+// ConversationID returns the id of the conversation between two users, it's
+// the same whichever of the two is given first.
+func ConversationID(a, b uuid.UUID) string {
+	if a.String() > b.String() {
+		a, b = b, a
+	}
+	return a.String() + ":" + b.String()
+}
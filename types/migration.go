@@ -0,0 +1,29 @@
+package types
+
+import "time"
+
+// MigrationState is the state of a schema migration.
+type MigrationState string
+
+const (
+	// MigrationPending is a migration that isn't applied yet.
+	MigrationPending MigrationState = "pending"
+
+	// MigrationApplied is an applied migration.
+	MigrationApplied MigrationState = "applied"
+
+	// MigrationModified is an applied migration whose file changed since.
+	MigrationModified MigrationState = "modified"
+
+	// MigrationUnknown is an applied migration missing from this build,
+	// applied by a newer build.
+	MigrationUnknown MigrationState = "unknown"
+)
+
+// MigrationStatus is the status of a schema migration.
+type MigrationStatus struct {
+	Version   int64          `json:"version"`
+	Name      string         `json:"name"`
+	State     MigrationState `json:"state"`
+	AppliedAt *time.Time     `json:"applied_at"`
+}
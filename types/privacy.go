@@ -0,0 +1,73 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Visibility is who can see a part of a profile.
+type Visibility string
+
+const (
+	// VisibilityEveryone shows the field to every user.
+	VisibilityEveryone Visibility = "everyone"
+
+	// VisibilityFriends shows the field to friends only.
+	VisibilityFriends Visibility = "friends"
+
+	// VisibilityNobody hides the field from every other user.
+	VisibilityNobody Visibility = "nobody"
+)
+
+// FriendRequestPolicy is who can send friend requests to a user.
+type FriendRequestPolicy string
+
+const (
+	// FriendRequestsEveryone accepts friend requests from every user.
+	FriendRequestsEveryone FriendRequestPolicy = "everyone"
+
+	// FriendRequestsFriendsOfFriends accepts friend requests from users who
+	// share a friend.
+	FriendRequestsFriendsOfFriends FriendRequestPolicy = "friends_of_friends"
+
+	// FriendRequestsNobody refuses every friend request.
+	FriendRequestsNobody FriendRequestPolicy = "nobody"
+)
+
+// PrivacySettings are the privacy settings of a user.
+type PrivacySettings struct {
+	UserUID            uuid.UUID           `json:"-"`
+	EmailVisibility    Visibility          `json:"email_visibility"`
+	BioVisibility      Visibility          `json:"bio_visibility"`
+	LastSeenVisibility Visibility          `json:"last_seen_visibility"`
+	StatusVisibility   Visibility          `json:"status_visibility"`
+	FriendRequests     FriendRequestPolicy `json:"friend_requests"`
+	Searchable         bool                `json:"searchable"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+}
+
+// DefaultPrivacySettings returns the settings of a user who never changed them.
+func DefaultPrivacySettings(userID uuid.UUID) *PrivacySettings {
+	return &PrivacySettings{
+		UserUID:            userID,
+		EmailVisibility:    VisibilityFriends,
+		BioVisibility:      VisibilityEveryone,
+		LastSeenVisibility: VisibilityFriends,
+		StatusVisibility:   VisibilityFriends,
+		FriendRequests:     FriendRequestsEveryone,
+		Searchable:         true,
+	}
+}
+
+// CanSee reports whether a field with the visibility is shown to another
+// user, depending on whether they're friends.
+func (v Visibility) CanSee(isFriend bool) bool {
+	switch v {
+	case VisibilityEveryone:
+		return true
+	case VisibilityFriends:
+		return isFriend
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package types
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// SearchFriendOfFriendBoost is added to the score of results who are friends
+// of the searcher's friends.
+const SearchFriendOfFriendBoost = 5
+
+// ErrInvalidSearchCursor is returned when a search cursor can't be decoded.
+var ErrInvalidSearchCursor = errors.New("invalid search cursor")
+
+// SearchDocument is the searchable view of a user.
+type SearchDocument struct {
+	UID       uuid.UUID `json:"uid"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Avatar    string    `json:"avatar"`
+}
+
+// SearchQuery is a user search made by a user.
+type SearchQuery struct {
+	// Query is the text searched for.
+	Query string
+
+	// ViewerUID is the user searching, used for ranking and blocks.
+	ViewerUID uuid.UUID
+
+	// Cursor is where the previous page ended, nil for the first page.
+	Cursor *SearchCursor
+
+	// Limit is the maximum number of results returned.
+	Limit int
+}
+
+// SearchResult is a user matching a search.
+type SearchResult struct {
+	SearchDocument
+	Score          float64 `json:"score"`
+	FriendOfFriend bool    `json:"friend_of_friend"`
+}
+
+// SearchPage is a page of search results.
+type SearchPage struct {
+	Results    []*SearchResult `json:"results"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// SearchCursor is the position of the last result of a page. Results are
+// ordered by score descending, then uid ascending.
+type SearchCursor struct {
+	Score float64
+	UID   uuid.UUID
+}
+
+// Encode encodes the cursor into an opaque string.
+func (c *SearchCursor) Encode() string {
+	raw := strconv.FormatFloat(c.Score, 'f', -1, 64) + ":" + c.UID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// After reports whether a result with the given score and uid comes after the cursor.
+func (c *SearchCursor) After(score float64, uid uuid.UUID) bool {
+	if score != c.Score {
+		return score < c.Score
+	}
+	return strings.Compare(uid.String(), c.UID.String()) > 0
+}
+
+// ParseSearchCursor decodes a cursor encoded with SearchCursor.Encode.
+func ParseSearchCursor(s string) (*SearchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidSearchCursor
+	}
+
+	score, uid, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidSearchCursor
+	}
+
+	cursor := &SearchCursor{}
+	if cursor.Score, err = strconv.ParseFloat(score, 64); err != nil {
+		return nil, ErrInvalidSearchCursor
+	}
+	if cursor.UID, err = uuid.Parse(uid); err != nil {
+		return nil, ErrInvalidSearchCursor
+	}
+	return cursor, nil
+}
+
+// SearchTerms splits a search into lowercase terms made of letters, digits
+// and underscores, dropping everything else.
+func SearchTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	uid := uuid.MustParse("6f1c2a4e-9d3b-4c1e-8a7f-2b5d9e0c1a3f")
+
+	tests := []struct {
+		name   string
+		cursor SearchCursor
+	}{
+		{"zero", SearchCursor{}},
+		{"integer score", SearchCursor{Score: 7, UID: uid}},
+		{"fractional score", SearchCursor{Score: 3.1416, UID: uid}},
+		{"negative score", SearchCursor{Score: -0.5, UID: uid}},
+		{"large score", SearchCursor{Score: 1e21, UID: uid}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseSearchCursor(tt.cursor.Encode())
+			if err != nil {
+				t.Fatalf("ParseSearchCursor: %v", err)
+			}
+			if *parsed != tt.cursor {
+				t.Errorf("got %+v, want %+v", *parsed, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestParseSearchCursorMalformed(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"empty", ""},
+		{"not base64", "%%%"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte("1:6f1c2a4e-9d3b-4c1e-8a7f-2b5d9e0c1a3f"))},
+		{"no separator", encode("6f1c2a4e-9d3b-4c1e-8a7f-2b5d9e0c1a3f")},
+		{"bad score", encode("high:6f1c2a4e-9d3b-4c1e-8a7f-2b5d9e0c1a3f")},
+		{"bad uid", encode("1.5:not-a-uid")},
+		{"missing uid", encode("1.5:")},
+		{"missing score", encode(":6f1c2a4e-9d3b-4c1e-8a7f-2b5d9e0c1a3f")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := ParseSearchCursor(tt.cursor)
+			if !errors.Is(err, ErrInvalidSearchCursor) {
+				t.Errorf("got %+v, %v, want ErrInvalidSearchCursor", cursor, err)
+			}
+		})
+	}
+}
+
+func TestSearchCursorAfter(t *testing.T) {
+	low := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	high := uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff")
+	cursor := &SearchCursor{Score: 5, UID: uuid.MustParse("80000000-0000-0000-0000-000000000000")}
+
+	tests := []struct {
+		name  string
+		score float64
+		uid   uuid.UUID
+		want  bool
+	}{
+		{"lower score", 4, low, true},
+		{"higher score", 6, high, false},
+		{"same score, greater uid", 5, high, true},
+		{"same score, smaller uid", 5, low, false},
+		{"same position", 5, cursor.UID, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cursor.After(tt.score, tt.uid); got != tt.want {
+				t.Errorf("After(%v, %v) = %v, want %v", tt.score, tt.uid, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{}},
+		{"   ", []string{}},
+		{"Alice", []string{"alice"}},
+		{"alice  BOB", []string{"alice", "bob"}},
+		{"john_doe", []string{"john_doe"}},
+		{"o'brien-smith", []string{"o", "brien", "smith"}},
+		{"user42", []string{"user42"}},
+		{"+alice* -bob", []string{"alice", "bob"}},
+		{"Zoë Ångström", []string{"zoë", "ångström"}},
+		{"%_%", []string{"_"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := SearchTerms(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchTerms(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,99 @@
 package types
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusAudience is who a status is shown to, among the author's friends.
+type StatusAudience string
+
+const (
+	// StatusAudienceEveryone shows the status to every friend.
+	StatusAudienceEveryone StatusAudience = "everyone"
+
+	// StatusAudienceList shows the status to the members of a friend list.
+	StatusAudienceList StatusAudience = "list"
+
+	// StatusAudienceExceptList shows the status to every friend outside a
+	// friend list.
+	StatusAudienceExceptList StatusAudience = "except_list"
+)
+
+// StatusItemKind is the kind of content of a status item.
+type StatusItemKind string
+
+const (
+	// StatusItemText is a text on a colored background.
+	StatusItemText StatusItemKind = "text"
+
+	// StatusItemImage is an image with an optional caption.
+	StatusItemImage StatusItemKind = "image"
+
+	// StatusItemVideo is a video with its duration and an optional caption.
+	StatusItemVideo StatusItemKind = "video"
+
+	// StatusItemLink is a link with its fetched preview.
+	StatusItemLink StatusItemKind = "link"
+)
+
+// StatusItem is one item of a status post, the fields set depend on the kind.
+type StatusItem struct {
+	Position          int              `json:"position"`
+	Kind              StatusItemKind   `json:"kind"`
+	Text              string           `json:"text,omitempty"`
+	BackgroundColor   string           `json:"background_color,omitempty"`
+	Font              string           `json:"font,omitempty"`
+	ResourceURI       string           `json:"resource_uri,omitempty"`
+	ResourceThumbnail string           `json:"resource_thumbnail,omitempty"`
+	DurationMS        int              `json:"duration_ms,omitempty"`
+	Caption           string           `json:"caption,omitempty"`
+	Mentions          []*StatusMention `json:"mentions,omitempty"`
+	Link              *LinkPreview     `json:"link,omitempty"`
+}
+
+// StatusMention is a friend mentioned in the caption or text of a status item.
+type StatusMention struct {
+	UID      uuid.UUID `json:"uid"`
+	Username string    `json:"username"`
+}
+
+// LinkPreview is the preview metadata of a link, fetched when the status is
+// posted.
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
 
 // UserStatus is the model for the user status.
+//
+// Title, ResourceURI and ResourceThumbnail summarize the first item for
+// clients that don't render the items.
 type UserStatus struct {
 	// ID is the unique identifier of the user status.
-	ID                int       `json:"-"`
-	UID               uuid.UUID `json:"uid"`
-	UserID            uuid.UUID `json:"-"`
-	Title             string    `json:"title"`
-	ResourceURI       string    `json:"resource_uri"`
-	ResourceThumbnail string    `json:"resource_thumbnail"`
-	CreatedAt         string    `json:"-"`
+	ID                int            `json:"-"`
+	UID               uuid.UUID      `json:"uid"`
+	UserID            uuid.UUID      `json:"-"`
+	Title             string         `json:"title"`
+	ResourceURI       string         `json:"resource_uri"`
+	ResourceThumbnail string         `json:"resource_thumbnail"`
+	Audience          StatusAudience `json:"audience"`
+	ListUID           uuid.NullUUID  `json:"list_uid"`
+	ViewCount         int            `json:"view_count"`
+	Items             []*StatusItem  `json:"items"`
+	CreatedAt         string         `json:"created_at"`
+}
+
+// StatusViewer is a friend who viewed a status.
+type StatusViewer struct {
+	UID       uuid.UUID `json:"uid"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Avatar    string    `json:"avatar"`
+	ViewedAt  time.Time `json:"viewed_at"`
 }
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Personal api token scopes.
+const (
+	// ScopeProfile grants access to the profile routes.
+	ScopeProfile = "profile"
+
+	// ScopeStatus grants access to the status routes.
+	ScopeStatus = "status"
+
+	// ScopeFriends grants access to the friend routes.
+	ScopeFriends = "friends"
+
+	// ScopeMessages grants access to the private message routes.
+	ScopeMessages = "messages"
+)
+
+// Scopes is the list of scopes a personal api token can be granted.
+var Scopes = []string{
+	ScopeProfile + ":read",
+	ScopeProfile + ":write",
+	ScopeStatus + ":read",
+	ScopeStatus + ":write",
+	ScopeFriends + ":read",
+	ScopeFriends + ":write",
+	ScopeMessages + ":read",
+	ScopeMessages + ":write",
+}
+
+// IsValidScope reports whether the scope is known.
+func IsValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIToken is a long-lived personal api token.
+type APIToken struct {
+	// ID is the unique identifier of the token.
+	ID         int        `json:"-"`
+	UID        uuid.UUID  `json:"uid"`
+	UserUID    uuid.UUID  `json:"user_uid"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"-"`
+}
+
+// HasScope reports whether the token was granted the scope.
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
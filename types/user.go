@@ -7,29 +7,54 @@ import (
 	"github.com/google/uuid"
 )
 
+// AccountType is the type of a user account.
+type AccountType string
+
+const (
+	// AccountTypeUser is a regular account that logs in with a password.
+	AccountTypeUser AccountType = "user"
+
+	// AccountTypeBot is a bot account that can only authenticate with personal api tokens.
+	AccountTypeBot AccountType = "bot"
+)
+
 // User represents a user.
 type User struct {
-	ID        int          `json:"id" db:"id"`
-	UID       uuid.UUID    `json:"uid" db:"uid"`
-	Username  string       `json:"username" db:"username"`
-	Email     string       `json:"email" db:"email"`
-	Password  string       `json:"password" db:"password"`
-	CreatedAt time.Time    `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
-	DeletedAt sql.NullTime `json:"deleted_at" db:"deleted_at"`
+	ID          int           `json:"id" db:"id"`
+	UID         uuid.UUID     `json:"uid" db:"uid"`
+	Username    string        `json:"username" db:"username"`
+	Email       string        `json:"email" db:"email"`
+	Password    string        `json:"password" db:"password"`
+	AccountType AccountType   `json:"account_type" db:"account_type"`
+	OwnerUID    uuid.NullUUID `json:"owner_uid" db:"owner_uid"`
+	Role        string        `json:"role" db:"role"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
+	DeletedAt   sql.NullTime  `json:"deleted_at" db:"deleted_at"`
+}
+
+// IsAdmin reports whether the user is an administrator.
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// IsBot reports whether the user is a bot account.
+func (u *User) IsBot() bool {
+	return u.AccountType == AccountTypeBot
 }
 
 type Profile struct {
-	ID        int          `json:"id" db:"id"`
-	UID       uuid.UUID    `json:"uid" db:"uid"`
-	UserID    int          `json:"user_id" db:"user_id"`
-	FirstName string       `json:"first_name" db:"first_name"`
-	LastName  string       `json:"last_name" db:"last_name"`
-	Bio       string       `json:"bio" db:"bio"`
-	Avatar    string       `json:"avatar" db:"avatar"`
-	Username  string       `json:"username" db:"username"`
-	Email     string       `json:"email" db:"email"`
-	CreatedAt time.Time    `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
-	DeletedAt sql.NullTime `json:"deleted_at" db:"deleted_at"`
+	ID         int          `json:"id" db:"id"`
+	UID        uuid.UUID    `json:"uid" db:"uid"`
+	UserID     int          `json:"user_id" db:"user_id"`
+	FirstName  string       `json:"first_name" db:"first_name"`
+	LastName   string       `json:"last_name" db:"last_name"`
+	Bio        string       `json:"bio" db:"bio"`
+	Avatar     string       `json:"avatar" db:"avatar"`
+	Username   string       `json:"username" db:"username"`
+	Email      string       `json:"email" db:"email"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at" db:"updated_at"`
+	DeletedAt  sql.NullTime `json:"deleted_at" db:"deleted_at"`
+	LastSeenAt sql.NullTime `json:"last_seen_at" db:"last_seen_at"`
 }